@@ -3,13 +3,21 @@
 import (
 	"EffectiveMobile/internal/api"
 	"EffectiveMobile/internal/config"
+	"EffectiveMobile/internal/health"
+	"EffectiveMobile/internal/outbox"
 	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/internal/service"
+	"EffectiveMobile/internal/webhook"
+	"EffectiveMobile/migrations"
 	"EffectiveMobile/pkg/postgres"
+	"EffectiveMobile/pkg/tracing"
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -20,6 +28,21 @@ const (
 	envProd  = "prod"
 )
 
+const (
+	tracingNone   = "none"
+	tracingStdout = "stdout"
+	tracingFile   = "file"
+)
+
+const (
+	// migrationsTable is the version table, shared with cmd/migrator.
+	migrationsTable = "schema_migrations"
+	// migrateTimeout bounds migrate_on_start, including the wait for other replicas.
+	migrateTimeout = 5 * time.Minute
+	// readinessTimeout bounds each readiness check.
+	readinessTimeout = time.Second
+)
+
 // @title           Subscription API
 // @version         1.0
 // @description     This is a server subscription API.
@@ -36,6 +59,12 @@ func main() {
 
 	log := setupLogger(cfg.Env)
 
+	closeTracing, err := setupTracing(cfg.Tracing, log)
+	if err != nil {
+		log.Error("failed to set up tracing", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
 	provider := postgres.New(
 		cfg.SQLDataBase.User,
 		cfg.SQLDataBase.Password,
@@ -48,11 +77,60 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.MigrateOnStart {
+		migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), migrateTimeout)
+		err := provider.Migrate(migrateCtx, migrations.FS, migrationsTable)
+		cancelMigrate()
+		if err != nil {
+			log.Error("failed to apply migrations", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	serviceRepo := repository.NewServiceRepository(provider, log)
 	subscriptionRepo := repository.NewSubscriptionRepository(provider, log)
 	statsRepo := repository.NewStatsRepository(provider, log)
+	budgetRepo := repository.NewBudgetRepository(provider, log)
+	webhookRepo := repository.NewWebhookRepository(provider, log)
+	outboxRepo := repository.NewOutboxRepository(provider, log)
 
-	router := api.NewRouter(log, serviceRepo, subscriptionRepo, statsRepo)
+	readiness, err := setupReadiness(provider)
+	if err != nil {
+		log.Error("failed to set up readiness checks", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	// The router and the webhook dispatcher share one service.
+	subscriptionService := service.NewSubscriptionService(serviceRepo, subscriptionRepo, provider, log)
+
+	router := api.NewRouter(log, provider, serviceRepo, subscriptionService, statsRepo, budgetRepo, webhookRepo, readiness)
+
+	publishers, closePublishers, err := setupPublishers(cfg.Outbox, webhookRepo, log)
+	if err != nil {
+		log.Error("failed to set up outbox publishers", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	outboxWorker := outbox.NewWorker(outboxRepo, publishers, outbox.Config{
+		PollInterval:   cfg.Outbox.PollInterval,
+		BatchSize:      cfg.Outbox.BatchSize,
+		MaxAttempts:    cfg.Outbox.MaxAttempts,
+		InitialBackoff: cfg.Outbox.InitialBackoff,
+		MaxBackoff:     cfg.Outbox.MaxBackoff,
+	}, log)
+	dispatcher := webhook.NewDispatcher(webhookRepo, subscriptionService, webhook.Config(cfg.Webhooks), log)
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		outboxWorker.Run(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(workersCtx)
+	}()
 
 	log.Info("starting server", slog.String("addr", cfg.HTTPServer.Address))
 
@@ -79,6 +157,14 @@ func main() {
 	sig := <-quit
 	log.Info("received shutdown signal", slog.String("signal", sig.String()))
 
+	readiness.Drain()
+	log.Info("draining traffic...", slog.Duration("delay", cfg.HTTPServer.DrainDelay))
+	select {
+	case <-time.After(cfg.HTTPServer.DrainDelay):
+	case sig := <-quit:
+		log.Info("received second shutdown signal", slog.String("signal", sig.String()))
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -88,12 +174,28 @@ func main() {
 		log.Error("server forced to shutdown", slog.String("err", err.Error()))
 	}
 
+	log.Info("stopping background workers...")
+	stopWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		log.Error("background workers did not stop in time")
+	}
+	closePublishers()
+
 	log.Info("closing database connections...")
 	err = provider.Close()
 	if err != nil {
 		log.Error("failed to close database connections", slog.String("err", err.Error()))
 	}
 
+	closeTracing()
+
 	log.Info("server stopped")
 }
 
@@ -111,3 +213,73 @@ func setupLogger(env string) *slog.Logger {
 
 	return log
 }
+
+// setupTracing sets the tracer exporting spans as cfg asks and returns a function
+// closing the exporter.
+func setupTracing(cfg config.Tracing, log *slog.Logger) (func(), error) {
+	switch cfg.Exporter {
+	case "", tracingNone:
+		return func() {}, nil
+	case tracingStdout:
+		tracing.SetTracer(tracing.NewTracer(tracing.NewWriterExporter(os.Stdout), log))
+		return func() {}, nil
+	case tracingFile:
+		exporter, err := tracing.NewFileExporter(cfg.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		tracing.SetTracer(tracing.NewTracer(exporter, log))
+		return func() {
+			if err := exporter.Close(); err != nil {
+				log.Error("failed to close spans file", slog.String("err", err.Error()))
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", cfg.Exporter)
+	}
+}
+
+// setupReadiness returns the readiness checks of the database the provider connects to.
+func setupReadiness(provider *postgres.Provider) (*health.Readiness, error) {
+	latest, err := postgres.LatestMigration(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness := health.NewReadiness(readinessTimeout)
+	readiness.Add("database", health.DatabaseCheck(provider))
+	readiness.Add("migrations", health.MigrationsCheck(provider, migrationsTable, latest))
+	readiness.Add("pool", health.PoolCheck(provider.GetConn().Stats))
+
+	return readiness, nil
+}
+
+// setupPublishers returns the outbox publishers enabled in cfg and a function closing
+// them.
+func setupPublishers(cfg config.Outbox, webhookRepo *repository.WebhookRepository, log *slog.Logger) ([]outbox.Publisher, func(), error) {
+	publishers := []outbox.Publisher{webhook.NewPublisher(webhookRepo)}
+	closePublishers := func() {}
+
+	if cfg.LogEvents {
+		publishers = append(publishers, outbox.NewLogPublisher(log))
+	}
+
+	if cfg.HTTPURL != "" {
+		publishers = append(publishers, outbox.NewHTTPPublisher(cfg.HTTPURL, cfg.HTTPTimeout))
+	}
+
+	if cfg.FilePath != "" {
+		filePublisher, err := outbox.NewFilePublisher(cfg.FilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		publishers = append(publishers, filePublisher)
+		closePublishers = func() {
+			if err := filePublisher.Close(); err != nil {
+				log.Error("failed to close events file", slog.String("err", err.Error()))
+			}
+		}
+	}
+
+	return publishers, closePublishers, nil
+}
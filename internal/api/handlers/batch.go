@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	batchModeTransaction = "transaction"
+	batchModePerItem     = "per_item"
+
+	maxBatchSize = 1000
+
+	// batchTimeout replaces the server read and write timeouts, a full batch in
+	// per_item mode takes a transaction per item.
+	batchTimeout = 2 * time.Minute
+)
+
+const (
+	ErrInvalidBatchMode = "invalid mode, expected transaction or per_item"
+	ErrInvalidBatchSize = "batch must contain from 1 to 1000 items"
+)
+
+const (
+	BatchStatusCreated    = "created"
+	BatchStatusDuplicate  = "duplicate"
+	BatchStatusInvalid    = "invalid"
+	BatchStatusNotCreated = "not_created"
+	BatchStatusFailed     = "failed"
+)
+
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status" example:"created"`
+	ID     int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchCreateResponse struct {
+	Mode    string            `json:"mode" example:"transaction"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// @Summary      Create subscriptions in bulk
+// @Description  Create up to 1000 subscriptions at once. In transaction mode (default) either all items are created or none; items that were fine but rolled back get status not_created. In per_item mode every valid item is created on its own. Each item gets a result with the same index: created, duplicate, invalid, not_created or failed.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        mode   query     string                       false  "transaction or per_item"  Enums(transaction, per_item)  default(transaction)
+// @Param        input  body      []CreateSubscriptionRequest  true   "Subscriptions to create"
+// @Success      200    {object}  BatchCreateResponse
+// @Failure      400    {object}  ErrorResponse  "Invalid request body, mode or batch size"
+// @Failure      500    {object}  ErrorResponse  "Internal server error"
+// @Router       /subscriptions/batch [post]
+func SaveSubscriptionsBatch(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.batch.SaveSubscriptionsBatch"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		mode, ok := parseBatchMode(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidBatchMode)
+			return
+		}
+		atomic := mode == batchModeTransaction
+
+		extendDeadlines(w, batchTimeout, reqLog)
+		ctx, cancel := context.WithTimeout(r.Context(), batchTimeout)
+		defer cancel()
+
+		// Items are decoded one by one, so a malformed item only fails itself.
+		var rawItems []json.RawMessage
+		if err := render.DecodeJSON(r.Body, &rawItems); err != nil {
+			reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		if len(rawItems) == 0 || len(rawItems) > maxBatchSize {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidBatchSize)
+			return
+		}
+
+		results := make([]BatchItemResult, len(rawItems))
+		inputs := make([]serv.CreateSubscriptionInput, 0, len(rawItems))
+		indexes := make([]int, 0, len(rawItems))
+
+		for i, raw := range rawItems {
+			results[i].Index = i
+
+			var req CreateSubscriptionRequest
+			if err := json.Unmarshal(raw, &req); err != nil {
+				results[i].Status = BatchStatusInvalid
+				results[i].Error = ErrInvalidArguments + ": " + err.Error()
+				continue
+			}
+
+			input, err := batchInput(req)
+			if err != nil {
+				results[i].Status = BatchStatusInvalid
+				results[i].Error = ErrInvalidArguments + ": " + err.Error()
+				continue
+			}
+
+			inputs = append(inputs, input)
+			indexes = append(indexes, i)
+		}
+
+		if err := runBatch(ctx, subscriptionService, reqLog, atomic, results, inputs, indexes); err != nil {
+			reqLog.Error("create subscriptions failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		resp := BatchCreateResponse{Mode: mode, Results: results}
+		resp.Created, resp.Failed = countBatchResults(results)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func parseBatchMode(r *http.Request) (string, bool) {
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		return batchModeTransaction, true
+	}
+	return mode, mode == batchModeTransaction || mode == batchModePerItem
+}
+
+func batchInput(req CreateSubscriptionRequest) (serv.CreateSubscriptionInput, error) {
+	if err := validateCreateSubscriptionRequest(req); err != nil {
+		return serv.CreateSubscriptionInput{}, err
+	}
+
+	var endDate string
+	if req.EndDate != nil {
+		endDate = *req.EndDate
+	}
+
+	return serv.CreateSubscriptionInput{
+		ServiceName: strings.TrimSpace(req.ServiceName),
+		Price:       req.Price,
+		UserID:      req.UserID,
+		StartDate:   req.StartDate,
+		EndDate:     endDate,
+	}, nil
+}
+
+// runBatch creates the valid inputs and fills their results; indexes maps every input
+// to its position in results. In atomic mode nothing is created when some item was
+// already rejected by the handler.
+func runBatch(
+	ctx context.Context,
+	subscriptionService SubscriptionService,
+	reqLog *slog.Logger,
+	atomic bool,
+	results []BatchItemResult,
+	inputs []serv.CreateSubscriptionInput,
+	indexes []int,
+) error {
+	if atomic && len(inputs) < len(results) {
+		for _, i := range indexes {
+			results[i].Status = BatchStatusNotCreated
+			results[i].Error = repository.ErrSubscriptionNotCreated.Error()
+		}
+		return nil
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	created, err := subscriptionService.CreateSubscriptions(ctx, inputs, atomic)
+	if err != nil {
+		return err
+	}
+
+	for j, res := range created {
+		results[indexes[j]] = batchItemResult(indexes[j], res, reqLog)
+	}
+	return nil
+}
+
+func countBatchResults(results []BatchItemResult) (created, failed int) {
+	for _, res := range results {
+		if res.Status == BatchStatusCreated {
+			created++
+		} else {
+			failed++
+		}
+	}
+	return created, failed
+}
+
+func batchItemResult(index int, res serv.BatchResult, reqLog *slog.Logger) BatchItemResult {
+	result := BatchItemResult{Index: index}
+
+	switch {
+	case res.Err == nil:
+		result.Status = BatchStatusCreated
+		result.ID = res.ID
+	case errors.Is(res.Err, serv.ErrValidation):
+		result.Status = BatchStatusInvalid
+		result.Error = ErrInvalidArguments + ": " + res.Err.Error()
+	case errors.Is(res.Err, repository.ErrSubscriptionAlreadyExists):
+		result.Status = BatchStatusDuplicate
+		result.Error = ErrSubscriptionExists
+	case errors.Is(res.Err, repository.ErrSubscriptionNotCreated):
+		result.Status = BatchStatusNotCreated
+		result.Error = res.Err.Error()
+	default:
+		reqLog.Error("create subscription failed", slog.Int("index", index), slog.String("err", res.Err.Error()))
+		result.Status = BatchStatusFailed
+		result.Error = ErrInternalServer
+	}
+
+	return result
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/mock/gomock"
+)
+
+const batchBody = `[
+	{"service_name": "Netflix", "price": 500, "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "start_date": "01-2024"},
+	{"service_name": "Spotify", "price": 200, "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "start_date": "02-2024"}
+]`
+
+func (s *SubscriptionHandlersSuite) serveBatch(path, body string) (*httptest.ResponseRecorder, BatchCreateResponse) {
+	router := chi.NewRouter()
+	router.Post("/subscriptions/batch", SaveSubscriptionsBatch(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var response BatchCreateResponse
+	if w.Code == http.StatusOK {
+		s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w, response
+}
+
+func (s *SubscriptionHandlersSuite) TestSaveSubscriptionsBatch_PerItem() {
+	s.subscriptionService.EXPECT().
+		CreateSubscriptions(gomock.Any(), gomock.Len(2), false).
+		Return([]serv.BatchResult{
+			{ID: 1},
+			{Err: repository.ErrSubscriptionAlreadyExists},
+		}, nil)
+
+	w, response := s.serveBatch("/subscriptions/batch?mode=per_item", batchBody)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("per_item", response.Mode)
+	s.Equal(1, response.Created)
+	s.Equal(1, response.Failed)
+	s.Require().Len(response.Results, 2)
+	s.Equal(BatchStatusCreated, response.Results[0].Status)
+	s.Equal(int64(1), response.Results[0].ID)
+	s.Equal(BatchStatusDuplicate, response.Results[1].Status)
+	s.Equal(1, response.Results[1].Index)
+}
+
+func (s *SubscriptionHandlersSuite) TestSaveSubscriptionsBatch_TransactionWithInvalidItem() {
+	body := `[
+		{"service_name": "Netflix", "price": 500, "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "start_date": "01-2024"},
+		{"service_name": "Spotify", "price": -1, "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "start_date": "01-2024"},
+		"not an object"
+	]`
+
+	w, response := s.serveBatch("/subscriptions/batch", body)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("transaction", response.Mode)
+	s.Equal(0, response.Created)
+	s.Equal(3, response.Failed)
+	s.Require().Len(response.Results, 3)
+	s.Equal(BatchStatusNotCreated, response.Results[0].Status)
+	s.Equal(BatchStatusInvalid, response.Results[1].Status)
+	s.Equal(BatchStatusInvalid, response.Results[2].Status)
+}
+
+func (s *SubscriptionHandlersSuite) TestSaveSubscriptionsBatch_InvalidMode() {
+	w, _ := s.serveBatch("/subscriptions/batch?mode=all", batchBody)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestSaveSubscriptionsBatch_Empty() {
+	w, _ := s.serveBatch("/subscriptions/batch", `[]`)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+// fullBatchBody builds a batch of n valid items.
+func fullBatchBody(n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"service_name": "Service %d", "price": 500, "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "start_date": "01-2024"}`, i)
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+func (s *SubscriptionHandlersSuite) TestSaveSubscriptionsBatch_MaxSize() {
+	results := make([]serv.BatchResult, maxBatchSize)
+	for i := range results {
+		results[i].ID = int64(i + 1)
+	}
+	s.subscriptionService.EXPECT().
+		CreateSubscriptions(gomock.Any(), gomock.Len(maxBatchSize), false).
+		Return(results, nil)
+
+	w, response := s.serveBatch("/subscriptions/batch?mode=per_item", fullBatchBody(maxBatchSize))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(maxBatchSize, response.Created)
+	s.Len(response.Results, maxBatchSize)
+
+	w, _ = s.serveBatch("/subscriptions/batch?mode=per_item", fullBatchBody(maxBatchSize+1))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestSaveSubscriptionsBatch_OutlivesServerTimeouts() {
+	results := make([]serv.BatchResult, maxBatchSize)
+	for i := range results {
+		results[i].ID = int64(i + 1)
+	}
+	s.subscriptionService.EXPECT().
+		CreateSubscriptions(gomock.Any(), gomock.Len(maxBatchSize), false).
+		DoAndReturn(func(_ any, _ []serv.CreateSubscriptionInput, _ bool) ([]serv.BatchResult, error) {
+			time.Sleep(150 * time.Millisecond)
+			return results, nil
+		})
+
+	srv := httptest.NewUnstartedServer(SaveSubscriptionsBatch(s.subscriptionService, s.logger))
+	srv.Config.ReadTimeout = 50 * time.Millisecond
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/subscriptions/batch?mode=per_item", "application/json", strings.NewReader(fullBatchBody(maxBatchSize)))
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	var response BatchCreateResponse
+	s.Require().NoError(json.Unmarshal(body, &response))
+	s.Equal(maxBatchSize, response.Created)
+}
+
+func (s *SubscriptionHandlersSuite) TestSaveSubscriptionsBatch_ServiceError() {
+	s.subscriptionService.EXPECT().
+		CreateSubscriptions(gomock.Any(), gomock.Any(), true).
+		Return(nil, errors.New("db down"))
+
+	w, _ := s.serveBatch("/subscriptions/batch", batchBody)
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,402 @@
+//go:generate go run go.uber.org/mock/mockgen@latest -destination=budget_mock.go -source=budget.go -package=handlers
+
+package handlers
+
+import (
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+const (
+	ErrBudgetNotFound      = "budget not found"
+	ErrBudgetExists        = "budget already exists"
+	ErrInvalidMonthlyLimit = "monthly_limit is required and cannot be negative"
+	ErrInvalidBudgetPeriod = "invalid start_date or end_date, expected MM-YYYY"
+)
+
+type BudgetService interface {
+	GetBudget(ctx context.Context, userID uuid.UUID) (*repository.Budget, error)
+	CreateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error
+	UpdateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error
+	DeleteBudget(ctx context.Context, userID uuid.UUID) error
+	GetBudgetStatus(ctx context.Context, userID uuid.UUID, startDate, endDate *time.Time) (*repository.BudgetStatus, error)
+}
+
+type BudgetRequest struct {
+	MonthlyLimit *int `json:"monthly_limit" example:"1500"`
+}
+
+type BudgetResponse struct {
+	UserID       string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	MonthlyLimit int    `json:"monthly_limit" example:"1500"`
+	CreatedAt    string `json:"created_at" example:"2025-01-15T10:00:00Z"`
+	UpdatedAt    string `json:"updated_at" example:"2025-01-15T10:00:00Z"`
+}
+
+type BudgetMonthItem struct {
+	Month              string `json:"month" example:"03-2025"`
+	TotalCost          int    `json:"total_cost" example:"1800"`
+	SubscriptionsCount int    `json:"subscriptions_count" example:"3"`
+	Overspend          int    `json:"overspend" example:"300"`
+}
+
+type BudgetStatusResponse struct {
+	UserID           string            `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	MonthlyLimit     int               `json:"monthly_limit" example:"1500"`
+	Period           Period            `json:"period"`
+	TotalCost        int               `json:"total_cost"`
+	MonthsCount      int               `json:"months_count"`
+	OverBudget       bool              `json:"over_budget"`
+	OverBudgetMonths []BudgetMonthItem `json:"over_budget_months"`
+}
+
+func parseBudgetUserID(r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func decodeBudgetRequest(r *http.Request, reqLog *slog.Logger) (int, bool) {
+	var req BudgetRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+		return 0, false
+	}
+	if req.MonthlyLimit == nil || *req.MonthlyLimit < 0 {
+		return 0, false
+	}
+	return *req.MonthlyLimit, true
+}
+
+func formatMonth(month *time.Time) string {
+	if month == nil {
+		return ""
+	}
+	return month.Format("01-2006")
+}
+
+// @Summary      Get budget
+// @Description  Get the monthly subscription budget of a user
+// @Tags         budgets
+// @Produce      json
+// @Param        user_id  path      string  true  "User UUID"
+// @Success      200      {object}  BudgetResponse
+// @Failure      400      {object}  ErrorResponse  "Invalid user_id"
+// @Failure      404      {object}  ErrorResponse  "Budget not found"
+// @Failure      500      {object}  ErrorResponse  "Internal server error"
+// @Router       /users/{user_id}/budget [get]
+func GetBudget(budgetService BudgetService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.budget.GetBudget"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := parseBudgetUserID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidUserIDFormat)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		budget, err := budgetService.GetBudget(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrBudgetNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrBudgetNotFound)
+				return
+			}
+			reqLog.Error("get budget failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(BudgetResponse{
+			UserID:       budget.UserID.String(),
+			MonthlyLimit: budget.MonthlyLimit,
+			CreatedAt:    budget.CreatedAt.UTC().Format(time.RFC3339),
+			UpdatedAt:    budget.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// @Summary      Create budget
+// @Description  Set a monthly subscription budget (in rubles) for a user
+// @Tags         budgets
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string         true  "User UUID"
+// @Param        input    body      BudgetRequest  true  "Budget payload"
+// @Success      201      {object}  map[string]string  "Successfully created"
+// @Failure      400      {object}  ErrorResponse      "Invalid user_id or monthly_limit"
+// @Failure      409      {object}  ErrorResponse      "Budget already exists"
+// @Failure      500      {object}  ErrorResponse      "Internal server error"
+// @Router       /users/{user_id}/budget [post]
+func CreateBudget(budgetService BudgetService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.budget.CreateBudget"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := parseBudgetUserID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidUserIDFormat)
+			return
+		}
+
+		monthlyLimit, ok := decodeBudgetRequest(r, reqLog)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidMonthlyLimit)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		if err := budgetService.CreateBudget(ctx, userID, monthlyLimit); err != nil {
+			if errors.Is(err, serv.ErrValidation) {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidMonthlyLimit)
+				return
+			}
+			if errors.Is(err, repository.ErrBudgetAlreadyExists) {
+				response.WriteError(w, http.StatusConflict, ErrBudgetExists)
+				return
+			}
+			reqLog.Error("create budget failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Location", "/api/v1/users/"+userID.String()+"/budget")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+		})
+	}
+}
+
+// @Summary      Update budget
+// @Description  Change the monthly subscription budget of a user
+// @Tags         budgets
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string         true  "User UUID"
+// @Param        input    body      BudgetRequest  true  "Budget payload"
+// @Success      200      {object}  map[string]string  "Successfully updated"
+// @Failure      400      {object}  ErrorResponse      "Invalid user_id or monthly_limit"
+// @Failure      404      {object}  ErrorResponse      "Budget not found"
+// @Failure      500      {object}  ErrorResponse      "Internal server error"
+// @Router       /users/{user_id}/budget [put]
+func UpdateBudget(budgetService BudgetService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.budget.UpdateBudget"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := parseBudgetUserID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidUserIDFormat)
+			return
+		}
+
+		monthlyLimit, ok := decodeBudgetRequest(r, reqLog)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidMonthlyLimit)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		if err := budgetService.UpdateBudget(ctx, userID, monthlyLimit); err != nil {
+			if errors.Is(err, serv.ErrValidation) {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidMonthlyLimit)
+				return
+			}
+			if errors.Is(err, repository.ErrBudgetNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrBudgetNotFound)
+				return
+			}
+			reqLog.Error("update budget failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+		})
+	}
+}
+
+// @Summary      Delete budget
+// @Tags         budgets
+// @Param        user_id  path      string  true  "User UUID"
+// @Success      204      {string}  string         "No content"
+// @Failure      400      {object}  ErrorResponse  "Invalid user_id"
+// @Failure      404      {object}  ErrorResponse  "Budget not found"
+// @Failure      500      {object}  ErrorResponse  "Internal server error"
+// @Router       /users/{user_id}/budget [delete]
+func DeleteBudget(budgetService BudgetService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.budget.DeleteBudget"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := parseBudgetUserID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidUserIDFormat)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		if err := budgetService.DeleteBudget(ctx, userID); err != nil {
+			if errors.Is(err, repository.ErrBudgetNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrBudgetNotFound)
+				return
+			}
+			reqLog.Error("delete budget failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// @Summary      Get budget status
+// @Description  Compare the monthly budget of a user with the cost of every month of the period, computed like /stats/monthly, and list the months where the cost is over the budget. Without start_date and end_date the period runs from the first subscription of the user to the current month. Date format: MM-YYYY.
+// @Tags         budgets
+// @Produce      json
+// @Param        user_id     path      string  true   "User UUID"
+// @Param        start_date  query     string  false  "Period start (MM-YYYY)"  example(01-2025)
+// @Param        end_date    query     string  false  "Period end (MM-YYYY)"    example(12-2025)
+// @Success      200         {object}  BudgetStatusResponse
+// @Failure      400         {object}  ErrorResponse  "Invalid user_id or period"
+// @Failure      404         {object}  ErrorResponse  "Budget not found"
+// @Failure      500         {object}  ErrorResponse  "Internal server error"
+// @Router       /users/{user_id}/budget-status [get]
+func GetBudgetStatus(budgetService BudgetService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.budget.GetBudgetStatus"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := parseBudgetUserID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidUserIDFormat)
+			return
+		}
+
+		startDate, endDate, err := parseMonthRange(r.URL.Query(), "start_date", "end_date")
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidBudgetPeriod)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		status, err := budgetService.GetBudgetStatus(ctx, userID, startDate, endDate)
+		if err != nil {
+			if errors.Is(err, repository.ErrBudgetNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrBudgetNotFound)
+				return
+			}
+			reqLog.Error("get budget status failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		months := make([]BudgetMonthItem, 0, len(status.OverBudgetMonths))
+		for _, m := range status.OverBudgetMonths {
+			months = append(months, BudgetMonthItem{
+				Month:              formatMonth(&m.Month),
+				TotalCost:          m.TotalCost,
+				SubscriptionsCount: m.SubscriptionsCount,
+				Overspend:          m.Overspend,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(BudgetStatusResponse{
+			UserID:       status.Budget.UserID.String(),
+			MonthlyLimit: status.Budget.MonthlyLimit,
+			Period: Period{
+				Start: formatMonth(status.StartDate),
+				End:   formatMonth(status.EndDate),
+			},
+			TotalCost:        status.TotalCost,
+			MonthsCount:      status.MonthsCount,
+			OverBudget:       len(months) > 0,
+			OverBudgetMonths: months,
+		})
+	}
+}
+
+func GetUsersRoutes(budgetService BudgetService, log *slog.Logger) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/{user_id}/budget", GetBudget(budgetService, log))
+	r.Post("/{user_id}/budget", CreateBudget(budgetService, log))
+	r.Put("/{user_id}/budget", UpdateBudget(budgetService, log))
+	r.Delete("/{user_id}/budget", DeleteBudget(budgetService, log))
+	r.Get("/{user_id}/budget-status", GetBudgetStatus(budgetService, log))
+	return r
+}
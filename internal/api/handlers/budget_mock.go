@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: budget.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=budget_mock.go -source=budget.go -package=handlers
+//
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	repository "EffectiveMobile/internal/repository"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBudgetService is a mock of BudgetService interface.
+type MockBudgetService struct {
+	ctrl     *gomock.Controller
+	recorder *MockBudgetServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockBudgetServiceMockRecorder is the mock recorder for MockBudgetService.
+type MockBudgetServiceMockRecorder struct {
+	mock *MockBudgetService
+}
+
+// NewMockBudgetService creates a new mock instance.
+func NewMockBudgetService(ctrl *gomock.Controller) *MockBudgetService {
+	mock := &MockBudgetService{ctrl: ctrl}
+	mock.recorder = &MockBudgetServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBudgetService) EXPECT() *MockBudgetServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateBudget mocks base method.
+func (m *MockBudgetService) CreateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBudget", ctx, userID, monthlyLimit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBudget indicates an expected call of CreateBudget.
+func (mr *MockBudgetServiceMockRecorder) CreateBudget(ctx, userID, monthlyLimit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBudget", reflect.TypeOf((*MockBudgetService)(nil).CreateBudget), ctx, userID, monthlyLimit)
+}
+
+// DeleteBudget mocks base method.
+func (m *MockBudgetService) DeleteBudget(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBudget", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBudget indicates an expected call of DeleteBudget.
+func (mr *MockBudgetServiceMockRecorder) DeleteBudget(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBudget", reflect.TypeOf((*MockBudgetService)(nil).DeleteBudget), ctx, userID)
+}
+
+// GetBudget mocks base method.
+func (m *MockBudgetService) GetBudget(ctx context.Context, userID uuid.UUID) (*repository.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudget", ctx, userID)
+	ret0, _ := ret[0].(*repository.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudget indicates an expected call of GetBudget.
+func (mr *MockBudgetServiceMockRecorder) GetBudget(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudget", reflect.TypeOf((*MockBudgetService)(nil).GetBudget), ctx, userID)
+}
+
+// GetBudgetStatus mocks base method.
+func (m *MockBudgetService) GetBudgetStatus(ctx context.Context, userID uuid.UUID, startDate, endDate *time.Time) (*repository.BudgetStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudgetStatus", ctx, userID, startDate, endDate)
+	ret0, _ := ret[0].(*repository.BudgetStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudgetStatus indicates an expected call of GetBudgetStatus.
+func (mr *MockBudgetServiceMockRecorder) GetBudgetStatus(ctx, userID, startDate, endDate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudgetStatus", reflect.TypeOf((*MockBudgetService)(nil).GetBudgetStatus), ctx, userID, startDate, endDate)
+}
+
+// UpdateBudget mocks base method.
+func (m *MockBudgetService) UpdateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBudget", ctx, userID, monthlyLimit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBudget indicates an expected call of UpdateBudget.
+func (mr *MockBudgetServiceMockRecorder) UpdateBudget(ctx, userID, monthlyLimit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBudget", reflect.TypeOf((*MockBudgetService)(nil).UpdateBudget), ctx, userID, monthlyLimit)
+}
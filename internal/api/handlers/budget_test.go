@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type BudgetHandlersSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	budgetService *MockBudgetService
+	logger        *slog.Logger
+	router        chi.Router
+}
+
+func TestBudgetHandlers(t *testing.T) {
+	suite.Run(t, &BudgetHandlersSuite{})
+}
+
+func (s *BudgetHandlersSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.budgetService = NewMockBudgetService(s.ctrl)
+
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	s.router = GetUsersRoutes(s.budgetService, s.logger)
+}
+
+func (s *BudgetHandlersSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *BudgetHandlersSuite) TestCreateBudget_Success() {
+	userID := uuid.New()
+
+	s.budgetService.EXPECT().
+		CreateBudget(gomock.Any(), userID, 1500).
+		Return(nil)
+
+	req := httptest.NewRequest("POST", "/"+userID.String()+"/budget", bytes.NewReader([]byte(`{"monthly_limit":1500}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.Equal("/api/v1/users/"+userID.String()+"/budget", w.Header().Get("Location"))
+}
+
+func (s *BudgetHandlersSuite) TestCreateBudget_Conflict() {
+	userID := uuid.New()
+
+	s.budgetService.EXPECT().
+		CreateBudget(gomock.Any(), userID, 1500).
+		Return(repository.ErrBudgetAlreadyExists)
+
+	req := httptest.NewRequest("POST", "/"+userID.String()+"/budget", bytes.NewReader([]byte(`{"monthly_limit":1500}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *BudgetHandlersSuite) TestCreateBudget_InvalidArguments() {
+	cases := []struct{ path, body string }{
+		{"/not-a-uuid/budget", `{"monthly_limit":1500}`},
+		{"/" + uuid.NewString() + "/budget", `{"monthly_limit":-1}`},
+		{"/" + uuid.NewString() + "/budget", `{}`},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", tc.path, bytes.NewReader([]byte(tc.body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, tc.body)
+	}
+}
+
+func (s *BudgetHandlersSuite) TestGetBudget_NotFound() {
+	userID := uuid.New()
+
+	s.budgetService.EXPECT().
+		GetBudget(gomock.Any(), userID).
+		Return(nil, repository.ErrBudgetNotFound)
+
+	req := httptest.NewRequest("GET", "/"+userID.String()+"/budget", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *BudgetHandlersSuite) TestUpdateBudget_Success() {
+	userID := uuid.New()
+
+	s.budgetService.EXPECT().
+		UpdateBudget(gomock.Any(), userID, 0).
+		Return(nil)
+
+	req := httptest.NewRequest("PUT", "/"+userID.String()+"/budget", bytes.NewReader([]byte(`{"monthly_limit":0}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *BudgetHandlersSuite) TestDeleteBudget_Success() {
+	userID := uuid.New()
+
+	s.budgetService.EXPECT().
+		DeleteBudget(gomock.Any(), userID).
+		Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/"+userID.String()+"/budget", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNoContent, w.Code)
+}
+
+func (s *BudgetHandlersSuite) TestGetBudgetStatus_Success() {
+	userID := uuid.New()
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	s.budgetService.EXPECT().
+		GetBudgetStatus(gomock.Any(), userID, &startDate, &endDate).
+		Return(&repository.BudgetStatus{
+			Budget:      repository.Budget{UserID: userID, MonthlyLimit: 1000},
+			TotalCost:   3300,
+			StartDate:   &startDate,
+			EndDate:     &endDate,
+			MonthsCount: 3,
+			OverBudgetMonths: []repository.BudgetMonth{
+				{MonthlyCost: repository.MonthlyCost{Month: endDate, TotalCost: 1500, SubscriptionsCount: 3}, Overspend: 500},
+			},
+		}, nil)
+
+	req := httptest.NewRequest("GET", "/"+userID.String()+"/budget-status?start_date=01-2025&end_date=03-2025", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response BudgetStatusResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(userID.String(), response.UserID)
+	s.Equal(Period{Start: "01-2025", End: "03-2025"}, response.Period)
+	s.True(response.OverBudget)
+	s.Equal([]BudgetMonthItem{
+		{Month: "03-2025", TotalCost: 1500, SubscriptionsCount: 3, Overspend: 500},
+	}, response.OverBudgetMonths)
+}
+
+func (s *BudgetHandlersSuite) TestGetBudgetStatus_InvalidPeriod() {
+	for _, query := range []string{"start_date=2025-01", "start_date=03-2025&end_date=01-2025"} {
+		req := httptest.NewRequest("GET", "/"+uuid.NewString()+"/budget-status?"+query, nil)
+		w := httptest.NewRecorder()
+
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, query)
+	}
+}
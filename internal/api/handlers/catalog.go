@@ -0,0 +1,620 @@
+//go:generate go run go.uber.org/mock/mockgen@latest -destination=catalog_mock.go -source=catalog.go -package=handlers
+
+package handlers
+
+import (
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	ErrInvalidServiceID     = "invalid service id"
+	ErrServiceNotFound      = "service not found"
+	ErrServiceExists        = "service already exists"
+	ErrServiceInUse         = "service is referenced by subscriptions"
+	ErrServiceAliasNotFound = "service alias not found"
+	ErrServiceMergeConflict = "services have subscriptions of one user starting in the same month"
+)
+
+type CatalogService interface {
+	ListServices(ctx context.Context) ([]repository.Service, error)
+	GetService(ctx context.Context, id int) (*repository.Service, error)
+	CreateService(ctx context.Context, name string) (int, error)
+	RenameService(ctx context.Context, id int, name string) error
+	DeleteService(ctx context.Context, id int) error
+	ListAliases(ctx context.Context, id int) ([]string, error)
+	AddAlias(ctx context.Context, id int, alias string) error
+	DeleteAlias(ctx context.Context, id int, alias string) error
+	MergeServices(ctx context.Context, sourceID, targetID int) (int64, error)
+}
+
+type ServiceRequest struct {
+	Name string `json:"name" example:"Netflix"`
+}
+
+type CreateServiceResponse struct {
+	Status string `json:"status"`
+	ID     int    `json:"id"`
+}
+
+type ServiceItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ListServicesResponse struct {
+	Services []ServiceItem `json:"services"`
+}
+
+type ServiceAliasRequest struct {
+	Name string `json:"name" example:"YT Premium"`
+}
+
+type ListServiceAliasesResponse struct {
+	Aliases []string `json:"aliases"`
+}
+
+type MergeServicesRequest struct {
+	TargetID int `json:"target_id" example:"1"`
+}
+
+type MergeServicesResponse struct {
+	Status   string `json:"status"`
+	TargetID int    `json:"target_id"`
+	// Moved is the number of subscriptions moved to the target service.
+	Moved int64 `json:"moved"`
+}
+
+func parseServiceID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// @Summary      List services
+// @Description  List all services of the catalog ordered by name
+// @Tags         services
+// @Produce      json
+// @Success      200  {object}  ListServicesResponse
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /services [get]
+func ListServices(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.ListServices"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		services, err := catalogService.ListServices(ctx)
+		if err != nil {
+			reqLog.Error("list services failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		items := make([]ServiceItem, 0, len(services))
+		for _, sv := range services {
+			items = append(items, ServiceItem{ID: sv.ID, Name: sv.Name})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(ListServicesResponse{Services: items})
+	}
+}
+
+// @Summary      Get service
+// @Tags         services
+// @Produce      json
+// @Param        id   path      int  true  "Service ID"
+// @Success      200  {object}  ServiceItem
+// @Failure      400  {object}  ErrorResponse  "Invalid service ID"
+// @Failure      404  {object}  ErrorResponse  "Service not found"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /services/{id} [get]
+func GetService(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.GetService"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseServiceID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidServiceID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		sv, err := catalogService.GetService(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrServiceNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrServiceNotFound)
+				return
+			}
+			reqLog.Error("get service failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(ServiceItem{ID: sv.ID, Name: sv.Name})
+	}
+}
+
+// @Summary      Create service
+// @Description  Add a new service to the catalog
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        input  body      ServiceRequest  true  "Service payload"
+// @Success      201    {object}  CreateServiceResponse
+// @Failure      400    {object}  ErrorResponse  "Invalid request body or empty name"
+// @Failure      409    {object}  ErrorResponse  "Service already exists"
+// @Failure      500    {object}  ErrorResponse  "Internal server error"
+// @Router       /services [post]
+func CreateService(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.CreateService"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req ServiceRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		if strings.TrimSpace(req.Name) == "" {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		id, err := catalogService.CreateService(ctx, req.Name)
+		if err != nil {
+			if errors.Is(err, serv.ErrValidation) {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceNameExists) {
+				response.WriteError(w, http.StatusConflict, ErrServiceExists)
+				return
+			}
+			reqLog.Error("create service failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Location", "/api/v1/services/"+strconv.Itoa(id))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(CreateServiceResponse{
+			Status: "ok",
+			ID:     id,
+		})
+	}
+}
+
+// @Summary      Rename service
+// @Description  Change the name of a service. All subscriptions of the service follow the new name.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int             true  "Service ID"
+// @Param        input  body      ServiceRequest  true  "Service payload"
+// @Success      200    {object}  map[string]string  "Successfully renamed"
+// @Failure      400    {object}  ErrorResponse      "Invalid request body or empty name"
+// @Failure      404    {object}  ErrorResponse      "Service not found"
+// @Failure      409    {object}  ErrorResponse      "Service with this name already exists"
+// @Failure      500    {object}  ErrorResponse      "Internal server error"
+// @Router       /services/{id} [put]
+func RenameService(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.RenameService"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseServiceID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidServiceID)
+			return
+		}
+
+		var req ServiceRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		if strings.TrimSpace(req.Name) == "" {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		err := catalogService.RenameService(ctx, id, req.Name)
+		if err != nil {
+			if errors.Is(err, serv.ErrValidation) {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrServiceNotFound)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceNameExists) {
+				response.WriteError(w, http.StatusConflict, ErrServiceExists)
+				return
+			}
+			reqLog.Error("rename service failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+		})
+	}
+}
+
+// @Summary      Delete service
+// @Description  Delete a service from the catalog. A service that is still referenced by subscriptions cannot be deleted.
+// @Tags         services
+// @Param        id   path  int  true  "Service ID"
+// @Success      204  {string}  string  "No content"
+// @Failure      400  {object}  ErrorResponse  "Invalid service ID"
+// @Failure      404  {object}  ErrorResponse  "Service not found"
+// @Failure      409  {object}  ErrorResponse  "Service is referenced by subscriptions"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /services/{id} [delete]
+func DeleteService(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.DeleteService"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseServiceID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidServiceID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		err := catalogService.DeleteService(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrServiceNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrServiceNotFound)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceInUse) {
+				response.WriteError(w, http.StatusConflict, ErrServiceInUse)
+				return
+			}
+			reqLog.Error("delete service failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// @Summary      List service aliases
+// @Description  List other names under which the service is found
+// @Tags         services
+// @Produce      json
+// @Param        id   path      int  true  "Service ID"
+// @Success      200  {object}  ListServiceAliasesResponse
+// @Failure      400  {object}  ErrorResponse  "Invalid service ID"
+// @Failure      404  {object}  ErrorResponse  "Service not found"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /services/{id}/aliases [get]
+func ListServiceAliases(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.ListServiceAliases"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseServiceID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidServiceID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		aliases, err := catalogService.ListAliases(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrServiceNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrServiceNotFound)
+				return
+			}
+			reqLog.Error("list aliases failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(ListServiceAliasesResponse{Aliases: aliases})
+	}
+}
+
+// @Summary      Add service alias
+// @Description  Add another name of the service. Subscriptions created or filtered by the alias use the service. Names and aliases are compared ignoring case and extra whitespace.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                  true  "Service ID"
+// @Param        input  body      ServiceAliasRequest  true  "Alias payload"
+// @Success      201    {object}  map[string]string  "Successfully added"
+// @Failure      400    {object}  ErrorResponse      "Invalid request body or empty alias"
+// @Failure      404    {object}  ErrorResponse      "Service not found"
+// @Failure      409    {object}  ErrorResponse      "Name is already used by a service or alias"
+// @Failure      500    {object}  ErrorResponse      "Internal server error"
+// @Router       /services/{id}/aliases [post]
+func AddServiceAlias(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.AddServiceAlias"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseServiceID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidServiceID)
+			return
+		}
+
+		var req ServiceAliasRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		if strings.TrimSpace(req.Name) == "" {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		err := catalogService.AddAlias(ctx, id, req.Name)
+		if err != nil {
+			if errors.Is(err, serv.ErrValidation) {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrServiceNotFound)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceNameExists) {
+				response.WriteError(w, http.StatusConflict, ErrServiceExists)
+				return
+			}
+			reqLog.Error("add alias failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+		})
+	}
+}
+
+// @Summary      Delete service alias
+// @Tags         services
+// @Param        id     path  int     true  "Service ID"
+// @Param        alias  path  string  true  "Alias"
+// @Success      204  {string}  string  "No content"
+// @Failure      400  {object}  ErrorResponse  "Invalid service ID"
+// @Failure      404  {object}  ErrorResponse  "Alias not found"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /services/{id}/aliases/{alias} [delete]
+func DeleteServiceAlias(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.DeleteServiceAlias"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseServiceID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidServiceID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		err := catalogService.DeleteAlias(ctx, id, chi.URLParam(r, "alias"))
+		if err != nil {
+			if errors.Is(err, repository.ErrServiceAliasNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrServiceAliasNotFound)
+				return
+			}
+			reqLog.Error("delete alias failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// @Summary      Merge services
+// @Description  Admin operation: move all subscriptions of the service to the target service and delete it. The name of the merged service becomes an alias of the target.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                   true  "ID of the service to merge"
+// @Param        input  body      MergeServicesRequest  true  "Target service"
+// @Success      200    {object}  MergeServicesResponse
+// @Failure      400    {object}  ErrorResponse  "Invalid service ID or request body"
+// @Failure      404    {object}  ErrorResponse  "Service not found"
+// @Failure      409    {object}  ErrorResponse  "A user has subscriptions to both services starting in the same month"
+// @Failure      500    {object}  ErrorResponse  "Internal server error"
+// @Router       /services/{id}/merge [post]
+func MergeServices(catalogService CatalogService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.catalog.MergeServices"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseServiceID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidServiceID)
+			return
+		}
+
+		var req MergeServicesRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		moved, err := catalogService.MergeServices(ctx, id, req.TargetID)
+		if err != nil {
+			if errors.Is(err, serv.ErrValidation) {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrServiceNotFound)
+				return
+			}
+			if errors.Is(err, repository.ErrServiceMergeConflict) {
+				response.WriteError(w, http.StatusConflict, ErrServiceMergeConflict)
+				return
+			}
+			reqLog.Error("merge services failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(MergeServicesResponse{
+			Status:   "ok",
+			TargetID: req.TargetID,
+			Moved:    moved,
+		})
+	}
+}
+
+func GetServicesRoutes(catalogService CatalogService, log *slog.Logger) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", ListServices(catalogService, log))
+	r.Post("/", CreateService(catalogService, log))
+	r.Get("/{id}", GetService(catalogService, log))
+	r.Put("/{id}", RenameService(catalogService, log))
+	r.Delete("/{id}", DeleteService(catalogService, log))
+	r.Get("/{id}/aliases", ListServiceAliases(catalogService, log))
+	r.Post("/{id}/aliases", AddServiceAlias(catalogService, log))
+	r.Delete("/{id}/aliases/{alias}", DeleteServiceAlias(catalogService, log))
+	r.Post("/{id}/merge", MergeServices(catalogService, log))
+	return r
+}
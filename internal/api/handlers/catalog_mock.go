@@ -0,0 +1,173 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: catalog.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=catalog_mock.go -source=catalog.go -package=handlers
+//
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	repository "EffectiveMobile/internal/repository"
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCatalogService is a mock of CatalogService interface.
+type MockCatalogService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockCatalogServiceMockRecorder is the mock recorder for MockCatalogService.
+type MockCatalogServiceMockRecorder struct {
+	mock *MockCatalogService
+}
+
+// NewMockCatalogService creates a new mock instance.
+func NewMockCatalogService(ctrl *gomock.Controller) *MockCatalogService {
+	mock := &MockCatalogService{ctrl: ctrl}
+	mock.recorder = &MockCatalogServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalogService) EXPECT() *MockCatalogServiceMockRecorder {
+	return m.recorder
+}
+
+// AddAlias mocks base method.
+func (m *MockCatalogService) AddAlias(ctx context.Context, id int, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAlias", ctx, id, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAlias indicates an expected call of AddAlias.
+func (mr *MockCatalogServiceMockRecorder) AddAlias(ctx, id, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAlias", reflect.TypeOf((*MockCatalogService)(nil).AddAlias), ctx, id, alias)
+}
+
+// CreateService mocks base method.
+func (m *MockCatalogService) CreateService(ctx context.Context, name string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateService", ctx, name)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateService indicates an expected call of CreateService.
+func (mr *MockCatalogServiceMockRecorder) CreateService(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateService", reflect.TypeOf((*MockCatalogService)(nil).CreateService), ctx, name)
+}
+
+// DeleteAlias mocks base method.
+func (m *MockCatalogService) DeleteAlias(ctx context.Context, id int, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlias", ctx, id, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAlias indicates an expected call of DeleteAlias.
+func (mr *MockCatalogServiceMockRecorder) DeleteAlias(ctx, id, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlias", reflect.TypeOf((*MockCatalogService)(nil).DeleteAlias), ctx, id, alias)
+}
+
+// DeleteService mocks base method.
+func (m *MockCatalogService) DeleteService(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteService", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteService indicates an expected call of DeleteService.
+func (mr *MockCatalogServiceMockRecorder) DeleteService(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteService", reflect.TypeOf((*MockCatalogService)(nil).DeleteService), ctx, id)
+}
+
+// GetService mocks base method.
+func (m *MockCatalogService) GetService(ctx context.Context, id int) (*repository.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetService", ctx, id)
+	ret0, _ := ret[0].(*repository.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetService indicates an expected call of GetService.
+func (mr *MockCatalogServiceMockRecorder) GetService(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetService", reflect.TypeOf((*MockCatalogService)(nil).GetService), ctx, id)
+}
+
+// ListAliases mocks base method.
+func (m *MockCatalogService) ListAliases(ctx context.Context, id int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAliases", ctx, id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAliases indicates an expected call of ListAliases.
+func (mr *MockCatalogServiceMockRecorder) ListAliases(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAliases", reflect.TypeOf((*MockCatalogService)(nil).ListAliases), ctx, id)
+}
+
+// ListServices mocks base method.
+func (m *MockCatalogService) ListServices(ctx context.Context) ([]repository.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServices", ctx)
+	ret0, _ := ret[0].([]repository.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServices indicates an expected call of ListServices.
+func (mr *MockCatalogServiceMockRecorder) ListServices(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServices", reflect.TypeOf((*MockCatalogService)(nil).ListServices), ctx)
+}
+
+// MergeServices mocks base method.
+func (m *MockCatalogService) MergeServices(ctx context.Context, sourceID, targetID int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeServices", ctx, sourceID, targetID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeServices indicates an expected call of MergeServices.
+func (mr *MockCatalogServiceMockRecorder) MergeServices(ctx, sourceID, targetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeServices", reflect.TypeOf((*MockCatalogService)(nil).MergeServices), ctx, sourceID, targetID)
+}
+
+// RenameService mocks base method.
+func (m *MockCatalogService) RenameService(ctx context.Context, id int, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameService", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameService indicates an expected call of RenameService.
+func (mr *MockCatalogServiceMockRecorder) RenameService(ctx, id, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameService", reflect.TypeOf((*MockCatalogService)(nil).RenameService), ctx, id, name)
+}
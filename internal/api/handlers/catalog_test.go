@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type CatalogHandlersSuite struct {
+	suite.Suite
+
+	ctrl           *gomock.Controller
+	catalogService *MockCatalogService
+	logger         *slog.Logger
+	router         chi.Router
+}
+
+func TestCatalogHandlers(t *testing.T) {
+	suite.Run(t, &CatalogHandlersSuite{})
+}
+
+func (s *CatalogHandlersSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.catalogService = NewMockCatalogService(s.ctrl)
+
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	s.router = GetServicesRoutes(s.catalogService, s.logger)
+}
+
+func (s *CatalogHandlersSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *CatalogHandlersSuite) TestListServices_Success() {
+	s.catalogService.EXPECT().
+		ListServices(gomock.Any()).
+		Return([]repository.Service{{ID: 1, Name: "Netflix"}, {ID: 2, Name: "Spotify"}}, nil)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response ListServicesResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal([]ServiceItem{{ID: 1, Name: "Netflix"}, {ID: 2, Name: "Spotify"}}, response.Services)
+}
+
+func (s *CatalogHandlersSuite) TestCreateService_Success() {
+	s.catalogService.EXPECT().
+		CreateService(gomock.Any(), "Netflix").
+		Return(4, nil)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"name":"Netflix"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.Equal("/api/v1/services/4", w.Header().Get("Location"))
+
+	var response CreateServiceResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(4, response.ID)
+}
+
+func (s *CatalogHandlersSuite) TestCreateService_EmptyName() {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"name":"  "}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestCreateService_Conflict() {
+	s.catalogService.EXPECT().
+		CreateService(gomock.Any(), "Netflix").
+		Return(0, repository.ErrServiceNameExists)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"name":"Netflix"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestGetService_NotFound() {
+	s.catalogService.EXPECT().
+		GetService(gomock.Any(), 9).
+		Return(nil, repository.ErrServiceNotFound)
+
+	req := httptest.NewRequest("GET", "/9", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestRenameService_Success() {
+	s.catalogService.EXPECT().
+		RenameService(gomock.Any(), 1, "Netflix").
+		Return(nil)
+
+	req := httptest.NewRequest("PUT", "/1", bytes.NewReader([]byte(`{"name":"Netflix"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestRenameService_InvalidID() {
+	req := httptest.NewRequest("PUT", "/abc", bytes.NewReader([]byte(`{"name":"Netflix"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestDeleteService_Success() {
+	s.catalogService.EXPECT().
+		DeleteService(gomock.Any(), 1).
+		Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/1", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNoContent, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestDeleteService_InUse() {
+	s.catalogService.EXPECT().
+		DeleteService(gomock.Any(), 1).
+		Return(repository.ErrServiceInUse)
+
+	req := httptest.NewRequest("DELETE", "/1", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusConflict, w.Code)
+
+	var response map[string]string
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(ErrServiceInUse, response["error"])
+}
+
+func (s *CatalogHandlersSuite) TestListServiceAliases_Success() {
+	s.catalogService.EXPECT().
+		ListAliases(gomock.Any(), 5).
+		Return([]string{"YT Premium"}, nil)
+
+	req := httptest.NewRequest("GET", "/5/aliases", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response ListServiceAliasesResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal([]string{"YT Premium"}, response.Aliases)
+}
+
+func (s *CatalogHandlersSuite) TestAddServiceAlias_Conflict() {
+	s.catalogService.EXPECT().
+		AddAlias(gomock.Any(), 5, "Netflix").
+		Return(repository.ErrServiceNameExists)
+
+	req := httptest.NewRequest("POST", "/5/aliases", bytes.NewReader([]byte(`{"name":"Netflix"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestDeleteServiceAlias_NotFound() {
+	s.catalogService.EXPECT().
+		DeleteAlias(gomock.Any(), 5, "YT Premium").
+		Return(repository.ErrServiceAliasNotFound)
+
+	req := httptest.NewRequest("DELETE", "/5/aliases/YT%20Premium", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *CatalogHandlersSuite) TestMergeServices_Success() {
+	s.catalogService.EXPECT().
+		MergeServices(gomock.Any(), 2, 1).
+		Return(int64(3), nil)
+
+	req := httptest.NewRequest("POST", "/2/merge", bytes.NewReader([]byte(`{"target_id":1}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response MergeServicesResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(1, response.TargetID)
+	s.Equal(int64(3), response.Moved)
+}
+
+func (s *CatalogHandlersSuite) TestMergeServices_Conflict() {
+	s.catalogService.EXPECT().
+		MergeServices(gomock.Any(), 2, 1).
+		Return(int64(0), repository.ErrServiceMergeConflict)
+
+	req := httptest.NewRequest("POST", "/2/merge", bytes.NewReader([]byte(`{"target_id":1}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+const (
+	exportFormatCSV = "csv"
+
+	maxImportRows  = 10000
+	maxImportBytes = 10 << 20
+
+	// exportTimeout and importTimeout replace the server read and write timeouts, which
+	// are sized for single-row requests.
+	exportTimeout = 10 * time.Minute
+	importTimeout = 2 * time.Minute
+)
+
+// formulaPrefixes start a cell that spreadsheets evaluate as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+const (
+	ErrInvalidExportFormat = "invalid format, expected csv"
+	ErrInvalidCSV          = "invalid csv"
+	ErrInvalidCSVHeader    = "csv header must contain service_name, price, user_id and start_date"
+	ErrInvalidImportSize   = "csv must contain from 1 to 10000 rows"
+)
+
+var exportColumns = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "deleted_at"}
+
+var requiredImportColumns = []string{"service_name", "price", "user_id", "start_date"}
+
+type ImportLineResult struct {
+	Line   int    `json:"line" example:"2"`
+	Status string `json:"status" example:"created"`
+	ID     int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ImportSubscriptionsResponse struct {
+	Mode    string             `json:"mode" example:"transaction"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Results []ImportLineResult `json:"results"`
+}
+
+// @Summary      Export subscriptions
+// @Description  Stream all subscriptions matching the filters, in the requested order, as CSV with the columns id, service_name, price, user_id, start_date, end_date (MM-YYYY) and deleted_at (RFC3339). A service_name starting with =, +, -, @, tab or carriage return is prefixed with ' so spreadsheets do not run it as a formula.
+// @Tags         subscriptions
+// @Produce      text/csv
+// @Param        format           query     string    false  "Export format"  Enums(csv)  default(csv)
+// @Param        user_id          query     []string  false  "User uuids, repeated or comma-separated"  collectionFormat(multi)
+// @Param        service_name     query     []string  false  "Service names, repeated"  collectionFormat(multi)
+// @Param        price_min        query     int       false  "Minimal price"  minimum(0)
+// @Param        price_max        query     int       false  "Maximal price"  minimum(0)
+// @Param        active_at        query     string    false  "Only subscriptions active in the month (MM-YYYY)"  example(03-2024)
+// @Param        start_date_from  query     string    false  "Earliest start_date (MM-YYYY)"  example(01-2024)
+// @Param        start_date_to    query     string    false  "Latest start_date (MM-YYYY)"    example(12-2024)
+// @Param        end_date_from    query     string    false  "Earliest end_date (MM-YYYY), excludes subscriptions without end_date"  example(01-2024)
+// @Param        end_date_to      query     string    false  "Latest end_date (MM-YYYY), excludes subscriptions without end_date"    example(12-2024)
+// @Param        has_end_date     query     bool      false  "Only subscriptions with (true) or without (false) end_date"
+// @Param        sort             query     string    false  "Sort key, prefixed with - for descending order"  Enums(id, -id, price, -price, start_date, -start_date, end_date, -end_date, service_name, -service_name)  default(id)
+// @Param        include_deleted  query     bool      false  "Also export deleted subscriptions"  default(false)
+// @Success      200              {string}  string         "CSV file"
+// @Failure      400              {object}  ErrorResponse  "Invalid format, filter or sort"
+// @Failure      500              {object}  ErrorResponse  "Internal server error"
+// @Router       /subscriptions/export [get]
+func ExportSubscriptions(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.csv.ExportSubscriptions"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if format := r.URL.Query().Get("format"); format != "" && format != exportFormatCSV {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidExportFormat)
+			return
+		}
+
+		params, err := parseSubscriptionFilters(r)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		extendDeadlines(w, exportTimeout, reqLog)
+		ctx, cancel := context.WithTimeout(r.Context(), exportTimeout)
+		defer cancel()
+
+		// The header is written with the first row, so a failing query can still be
+		// answered with an error status.
+		writer := csv.NewWriter(w)
+		started := false
+		start := func() error {
+			started = true
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="subscriptions.csv"`)
+			w.WriteHeader(http.StatusOK)
+			return writer.Write(exportColumns)
+		}
+
+		rows := 0
+		err = subscriptionService.ExportSubscriptions(ctx, params, func(s repository.Subscription) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			if err := writer.Write(exportRecord(s)); err != nil {
+				return err
+			}
+
+			rows++
+			if rows%500 == 0 {
+				writer.Flush()
+				return writer.Error()
+			}
+			return nil
+		})
+		if err != nil {
+			reqLog.Error("export subscriptions failed", slog.String("err", err.Error()), slog.Int("rows", rows))
+			if !started {
+				response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			}
+			return
+		}
+
+		if !started {
+			if err := start(); err != nil {
+				reqLog.Error("failed to write csv", slog.String("err", err.Error()))
+				return
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			reqLog.Error("failed to write csv", slog.String("err", err.Error()))
+		}
+	}
+}
+
+func exportRecord(s repository.Subscription) []string {
+	var endDate, deletedAt string
+	if s.EndDate != nil {
+		endDate = s.EndDate.Format("01-2006")
+	}
+	if formatted := formatDeletedAt(s.DeletedAt); formatted != nil {
+		deletedAt = *formatted
+	}
+
+	return []string{
+		strconv.FormatInt(s.ID, 10),
+		escapeFormula(s.ServiceName),
+		strconv.Itoa(s.Price),
+		s.UserID.String(),
+		s.StartDate.Format("01-2006"),
+		endDate,
+		deletedAt,
+	}
+}
+
+// escapeFormula prefixes a cell spreadsheets would evaluate as a formula with '.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unescapeFormula reverts escapeFormula, so an exported file imports the same names.
+func unescapeFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+// extendDeadlines lets a bulk request read its body and write its response for up to
+// timeout, past the server ReadTimeout and WriteTimeout.
+func extendDeadlines(w http.ResponseWriter, timeout time.Duration, log *slog.Logger) {
+	deadline := time.Now().Add(timeout)
+	rc := http.NewResponseController(w)
+
+	for _, set := range []func(time.Time) error{rc.SetReadDeadline, rc.SetWriteDeadline} {
+		if err := set(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Error("failed to extend deadline", slog.String("err", err.Error()))
+		}
+	}
+}
+
+// @Summary      Import subscriptions
+// @Description  Create subscriptions from a CSV file. The first line is a header with the columns service_name, price, user_id, start_date and optionally end_date (MM-YYYY), in any order; other columns are ignored, so an export can be imported back (a service_name escaped with ' on export is unescaped). Every data line is validated like POST /subscriptions and gets a result with its line number. The mode works like in POST /subscriptions/batch.
+// @Tags         subscriptions
+// @Accept       text/csv
+// @Produce      json
+// @Param        mode   query     string  false  "transaction or per_item"  Enums(transaction, per_item)  default(transaction)
+// @Param        input  body      string  true   "CSV file"
+// @Success      200    {object}  ImportSubscriptionsResponse
+// @Failure      400    {object}  ErrorResponse  "Invalid mode, header or file size"
+// @Failure      500    {object}  ErrorResponse  "Internal server error"
+// @Router       /subscriptions/import [post]
+func ImportSubscriptions(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.csv.ImportSubscriptions"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		mode, ok := parseBatchMode(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidBatchMode)
+			return
+		}
+		atomic := mode == batchModeTransaction
+
+		extendDeadlines(w, importTimeout, reqLog)
+		ctx, cancel := context.WithTimeout(r.Context(), importTimeout)
+		defer cancel()
+
+		reader := csv.NewReader(http.MaxBytesReader(w, r.Body, maxImportBytes))
+		reader.TrimLeadingSpace = true
+
+		header, err := reader.Read()
+		if err != nil {
+			reqLog.Error("failed to read csv header", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidCSVHeader)
+			return
+		}
+		columns, ok := importColumns(header)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidCSVHeader)
+			return
+		}
+		reader.FieldsPerRecord = len(header)
+
+		var (
+			results []BatchItemResult
+			lines   []int
+			inputs  []serv.CreateSubscriptionInput
+			indexes []int
+		)
+
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			var parseErr *csv.ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				reqLog.Error("failed to read csv", slog.String("err", err.Error()))
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidCSV)
+				return
+			}
+
+			if len(results) == maxImportRows {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidImportSize)
+				return
+			}
+
+			i := len(results)
+			results = append(results, BatchItemResult{Index: i})
+			if parseErr != nil {
+				lines = append(lines, parseErr.StartLine)
+				results[i].Status = BatchStatusInvalid
+				results[i].Error = ErrInvalidArguments + ": " + parseErr.Err.Error()
+				continue
+			}
+
+			line, _ := reader.FieldPos(0)
+			lines = append(lines, line)
+
+			input, err := importInput(record, columns)
+			if err != nil {
+				results[i].Status = BatchStatusInvalid
+				results[i].Error = ErrInvalidArguments + ": " + err.Error()
+				continue
+			}
+
+			inputs = append(inputs, input)
+			indexes = append(indexes, i)
+		}
+
+		if len(results) == 0 {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidImportSize)
+			return
+		}
+
+		if err := runBatch(ctx, subscriptionService, reqLog, atomic, results, inputs, indexes); err != nil {
+			reqLog.Error("import subscriptions failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		resp := ImportSubscriptionsResponse{Mode: mode, Results: make([]ImportLineResult, len(results))}
+		resp.Created, resp.Failed = countBatchResults(results)
+		for i, res := range results {
+			resp.Results[i] = ImportLineResult{Line: lines[i], Status: res.Status, ID: res.ID, Error: res.Error}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// importColumns maps the known column names of the header to their positions.
+func importColumns(header []string) (map[string]int, bool) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, false
+		}
+	}
+	return columns, true
+}
+
+func importInput(record []string, columns map[string]int) (serv.CreateSubscriptionInput, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	price, err := strconv.Atoi(field("price"))
+	if err != nil {
+		return serv.CreateSubscriptionInput{}, fmt.Errorf("invalid price %q", field("price"))
+	}
+
+	userID, err := uuid.Parse(field("user_id"))
+	if err != nil {
+		return serv.CreateSubscriptionInput{}, errors.New(ErrInvalidUserIDFormat)
+	}
+
+	req := CreateSubscriptionRequest{
+		ServiceName: unescapeFormula(field("service_name")),
+		Price:       price,
+		UserID:      userID,
+		StartDate:   field("start_date"),
+	}
+	if endDate := field("end_date"); endDate != "" {
+		req.EndDate = &endDate
+	}
+
+	return batchInput(req)
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *SubscriptionHandlersSuite) TestExportSubscriptions_Success() {
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	endDate := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	s.subscriptionService.EXPECT().
+		ExportSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{UserIDs: []uuid.UUID{userID}}, gomock.Any()).
+		DoAndReturn(func(_ any, _ repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error {
+			s.Require().NoError(fn(repository.Subscription{
+				ID: 1, ServiceName: "Netflix", Price: 500, UserID: userID,
+				StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &endDate,
+			}))
+			return fn(repository.Subscription{
+				ID: 2, ServiceName: "Yandex, Plus", Price: 300, UserID: userID,
+				StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			})
+		})
+
+	req := httptest.NewRequest("GET", "/subscriptions/export?format=csv&user_id="+userID.String(), nil)
+	w := httptest.NewRecorder()
+
+	ExportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Header().Get("Content-Type"), "text/csv")
+	s.Equal(
+		"id,service_name,price,user_id,start_date,end_date,deleted_at\n"+
+			"1,Netflix,500,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,12-2024,\n"+
+			"2,\"Yandex, Plus\",300,60601fee-2bf1-4721-ae6f-7636e79a0cba,03-2024,,\n",
+		w.Body.String(),
+	)
+}
+
+func (s *SubscriptionHandlersSuite) TestExportSubscriptions_EscapesFormulas() {
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+
+	s.subscriptionService.EXPECT().
+		ExportSubscriptions(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, _ repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error {
+			for i, name := range []string{"=HYPERLINK(\"http://x\")", "+1", "-1", "@SUM(A1)", "\tcmd", "\rcmd", "Net=flix"} {
+				err := fn(repository.Subscription{
+					ID: int64(i + 1), ServiceName: name, Price: 1, UserID: userID,
+					StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				})
+				s.Require().NoError(err)
+			}
+			return nil
+		})
+
+	req := httptest.NewRequest("GET", "/subscriptions/export", nil)
+	w := httptest.NewRecorder()
+
+	ExportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(
+		"id,service_name,price,user_id,start_date,end_date,deleted_at\n"+
+			"1,\"'=HYPERLINK(\"\"http://x\"\")\",1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,,\n"+
+			"2,'+1,1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,,\n"+
+			"3,'-1,1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,,\n"+
+			"4,'@SUM(A1),1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,,\n"+
+			"5,'\tcmd,1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,,\n"+
+			"6,\"'\rcmd\",1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,,\n"+
+			"7,Net=flix,1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024,,\n",
+		w.Body.String(),
+	)
+}
+
+func (s *SubscriptionHandlersSuite) TestExportSubscriptions_OutlivesServerTimeouts() {
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+
+	s.subscriptionService.EXPECT().
+		ExportSubscriptions(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, _ repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error {
+			for i := range 3 {
+				time.Sleep(50 * time.Millisecond)
+				err := fn(repository.Subscription{
+					ID: int64(i + 1), ServiceName: "Netflix", Price: 500, UserID: userID,
+					StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				})
+				s.Require().NoError(err)
+			}
+			return nil
+		})
+
+	srv := httptest.NewUnstartedServer(ExportSubscriptions(s.subscriptionService, s.logger))
+	srv.Config.ReadTimeout = 50 * time.Millisecond
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/subscriptions/export")
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal(4, strings.Count(string(body), "\n"))
+}
+
+func (s *SubscriptionHandlersSuite) TestExportSubscriptions_InvalidFormat() {
+	req := httptest.NewRequest("GET", "/subscriptions/export?format=xlsx", nil)
+	w := httptest.NewRecorder()
+
+	ExportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestExportSubscriptions_ServiceError() {
+	s.subscriptionService.EXPECT().
+		ExportSubscriptions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("db down"))
+
+	req := httptest.NewRequest("GET", "/subscriptions/export", nil)
+	w := httptest.NewRecorder()
+
+	ExportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestImportSubscriptions_PerLineErrors() {
+	body := "\ufeffuser_id,service_name,price,start_date,end_date\n" +
+		"60601fee-2bf1-4721-ae6f-7636e79a0cba,Netflix,500,01-2024,12-2024\n" +
+		"not-a-uuid,Spotify,200,01-2024,\n" +
+		"60601fee-2bf1-4721-ae6f-7636e79a0cba,Spotify,abc,01-2024,\n" +
+		"60601fee-2bf1-4721-ae6f-7636e79a0cba,Spotify,200\n" +
+		"60601fee-2bf1-4721-ae6f-7636e79a0cba,Spotify,200,13-2024,\n"
+
+	s.subscriptionService.EXPECT().
+		CreateSubscriptions(gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ any, items []serv.CreateSubscriptionInput, _ bool) ([]serv.BatchResult, error) {
+			s.Require().Len(items, 2)
+			s.Equal("Netflix", items[0].ServiceName)
+			s.Equal("12-2024", items[0].EndDate)
+			return []serv.BatchResult{
+				{ID: 7},
+				{Err: serv.ErrValidation},
+			}, nil
+		})
+
+	req := httptest.NewRequest("POST", "/subscriptions/import?mode=per_item", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	ImportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response ImportSubscriptionsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(1, response.Created)
+	s.Equal(4, response.Failed)
+	s.Require().Len(response.Results, 5)
+
+	for i, status := range []string{BatchStatusCreated, BatchStatusInvalid, BatchStatusInvalid, BatchStatusInvalid, BatchStatusInvalid} {
+		s.Equal(i+2, response.Results[i].Line)
+		s.Equal(status, response.Results[i].Status)
+	}
+	s.Equal(int64(7), response.Results[0].ID)
+}
+
+func (s *SubscriptionHandlersSuite) TestImportSubscriptions_UnescapesFormulas() {
+	body := "service_name,price,user_id,start_date\n" +
+		"'=Netflix,500,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024\n" +
+		"'Okko,300,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2024\n"
+
+	s.subscriptionService.EXPECT().
+		CreateSubscriptions(gomock.Any(), gomock.Any(), true).
+		DoAndReturn(func(_ any, items []serv.CreateSubscriptionInput, _ bool) ([]serv.BatchResult, error) {
+			s.Require().Len(items, 2)
+			s.Equal("=Netflix", items[0].ServiceName)
+			s.Equal("'Okko", items[1].ServiceName)
+			return []serv.BatchResult{{ID: 1}, {ID: 2}}, nil
+		})
+
+	req := httptest.NewRequest("POST", "/subscriptions/import", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	ImportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestImportSubscriptions_MissingColumn() {
+	body := "service_name,price,start_date\nNetflix,500,01-2024\n"
+
+	req := httptest.NewRequest("POST", "/subscriptions/import", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	ImportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestImportSubscriptions_Empty() {
+	body := "service_name,price,user_id,start_date\n"
+
+	req := httptest.NewRequest("POST", "/subscriptions/import", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	ImportSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"EffectiveMobile/internal/repository"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 10
+	maxListLimit     = 1000
+	maxFilterValues  = 100
+)
+
+const (
+	ErrInvalidPagination     = "invalid limit or offset, expected limit from 1 to 1000 and offset >= 0"
+	ErrTooManyFilterValues   = "too many user_id or service_name values, at most 100 are allowed"
+	ErrInvalidPriceRange     = "invalid price_min or price_max"
+	ErrInvalidActiveAt       = "invalid active_at, expected MM-YYYY"
+	ErrInvalidStartDateRange = "invalid start_date_from or start_date_to, expected MM-YYYY"
+	ErrInvalidEndDateRange   = "invalid end_date_from or end_date_to, expected MM-YYYY"
+	ErrInvalidHasEndDate     = "invalid has_end_date value"
+	ErrInvalidSort           = "invalid sort, expected id, price, start_date, end_date or service_name, prefixed with - for descending order"
+)
+
+// parseSubscriptionFilters reads the filters and the sort order shared by the list
+// and export endpoints. The returned error is meant to be sent to the client as is.
+func parseSubscriptionFilters(r *http.Request) (repository.ListSubscriptionsParams, error) {
+	var params repository.ListSubscriptionsParams
+	query := r.URL.Query()
+
+	userIDs := splitValues(query["user_id"], ",")
+	serviceNames := splitValues(query["service_name"], "")
+	if len(userIDs) > maxFilterValues || len(serviceNames) > maxFilterValues {
+		return params, errors.New(ErrTooManyFilterValues)
+	}
+
+	for _, value := range userIDs {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return params, errors.New(ErrInvalidUserIDFormat)
+		}
+		params.UserIDs = append(params.UserIDs, id)
+	}
+	params.ServiceNames = serviceNames
+
+	var err error
+	if params.PriceMin, err = parsePrice(query, "price_min"); err != nil {
+		return params, err
+	}
+	if params.PriceMax, err = parsePrice(query, "price_max"); err != nil {
+		return params, err
+	}
+	if params.PriceMin != nil && params.PriceMax != nil && *params.PriceMin > *params.PriceMax {
+		return params, errors.New(ErrInvalidPriceRange)
+	}
+
+	if params.ActiveAt, err = parseMonthParam(query, "active_at"); err != nil {
+		return params, errors.New(ErrInvalidActiveAt)
+	}
+
+	if params.StartFrom, params.StartTo, err = parseMonthRange(query, "start_date_from", "start_date_to"); err != nil {
+		return params, errors.New(ErrInvalidStartDateRange)
+	}
+	if params.EndFrom, params.EndTo, err = parseMonthRange(query, "end_date_from", "end_date_to"); err != nil {
+		return params, errors.New(ErrInvalidEndDateRange)
+	}
+
+	if value := query.Get("has_end_date"); value != "" {
+		hasEndDate, err := strconv.ParseBool(value)
+		if err != nil {
+			return params, errors.New(ErrInvalidHasEndDate)
+		}
+		params.HasEndDate = &hasEndDate
+	}
+
+	if params.SortBy, params.SortDesc, err = parseSort(query.Get("sort")); err != nil {
+		return params, err
+	}
+
+	includeDeleted, err := parseIncludeDeleted(r)
+	if err != nil {
+		return params, errors.New(ErrInvalidIncludeDeleted)
+	}
+	params.IncludeDeleted = includeDeleted
+
+	return params, nil
+}
+
+// parsePagination reads limit and offset of the list endpoint.
+func parsePagination(query url.Values) (limit, offset int, err error) {
+	limit, offset = defaultListLimit, 0
+
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			return 0, 0, errors.New(ErrInvalidPagination)
+		}
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New(ErrInvalidPagination)
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// splitValues collects the non-empty values of a repeated query parameter. With a
+// separator every value may also hold a list.
+func splitValues(values []string, sep string) []string {
+	var result []string
+	for _, value := range values {
+		parts := []string{value}
+		if sep != "" {
+			parts = strings.Split(value, sep)
+		}
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+func parsePrice(query url.Values, key string) (*int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	price, err := strconv.Atoi(value)
+	if err != nil || price < 0 {
+		return nil, errors.New(ErrInvalidPriceRange)
+	}
+	return &price, nil
+}
+
+func parseMonthParam(query url.Values, key string) (*time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	month, err := time.Parse("01-2006", value)
+	if err != nil {
+		return nil, err
+	}
+	return &month, nil
+}
+
+func parseMonthRange(query url.Values, fromKey, toKey string) (from, to *time.Time, err error) {
+	if from, err = parseMonthParam(query, fromKey); err != nil {
+		return nil, nil, err
+	}
+	if to, err = parseMonthParam(query, toKey); err != nil {
+		return nil, nil, err
+	}
+	if from != nil && to != nil && from.After(*to) {
+		return nil, nil, errors.New("range start is after its end")
+	}
+	return from, to, nil
+}
+
+// parseSort reads sort values like "price" or "-start_date". An empty value keeps
+// the default order by id.
+func parseSort(value string) (repository.SubscriptionSort, bool, error) {
+	if value == "" {
+		return "", false, nil
+	}
+
+	desc := strings.HasPrefix(value, "-")
+	sortBy := repository.SubscriptionSort(strings.TrimPrefix(value, "-"))
+	if !sortBy.IsValid() {
+		return "", false, errors.New(ErrInvalidSort)
+	}
+	return sortBy, desc, nil
+}
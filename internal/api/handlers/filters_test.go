@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_Filters() {
+	first, second := uuid.New(), uuid.New()
+	priceMin, priceMax := 100, 500
+	hasEndDate := true
+	month := func(m time.Month) *time.Time {
+		t := time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/subscriptions?"+
+		"user_id="+first.String()+","+second.String()+
+		"&service_name=Netflix&service_name=Yandex%2C%20Plus"+
+		"&price_min=100&price_max=500&active_at=03-2024"+
+		"&start_date_from=01-2024&start_date_to=02-2024"+
+		"&end_date_from=06-2024&end_date_to=12-2024"+
+		"&has_end_date=true&sort=-end_date", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		ListSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{
+			Limit:        10,
+			UserIDs:      []uuid.UUID{first, second},
+			ServiceNames: []string{"Netflix", "Yandex, Plus"},
+			PriceMin:     &priceMin,
+			PriceMax:     &priceMax,
+			ActiveAt:     month(time.March),
+			StartFrom:    month(time.January),
+			StartTo:      month(time.February),
+			EndFrom:      month(time.June),
+			EndTo:        month(time.December),
+			HasEndDate:   &hasEndDate,
+			SortBy:       repository.SortByEndDate,
+			SortDesc:     true,
+		}).
+		Return(&repository.SubscriptionPage{}, nil)
+
+	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_MalformedCursorValue() {
+	for sortBy, cursor := range map[string]repository.ListCursor{
+		"price":        {SortBy: repository.SortByPrice, Value: "abc", ID: 1},
+		"-price":       {SortBy: repository.SortByPrice, Desc: true, Value: "99999999999", ID: 1},
+		"start_date":   {SortBy: repository.SortByStartDate, Value: "01-2024", ID: 1},
+		"end_date":     {SortBy: repository.SortByEndDate, Value: "tomorrow", ID: 1},
+		"service_name": {SortBy: repository.SortByServiceName, ID: 1},
+	} {
+		req := httptest.NewRequest("GET", "/api/v1/subscriptions?sort="+sortBy+"&cursor="+cursor.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, sortBy)
+	}
+}
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_InvalidFilters() {
+	priceCursor := repository.ListCursor{SortBy: repository.SortByPrice, Value: "500", ID: 1}
+
+	for _, query := range []string{
+		"limit=0",
+		"limit=1001",
+		"offset=-1",
+		"user_id=" + uuid.NewString() + ",nope",
+		"price_min=-1",
+		"price_min=500&price_max=100",
+		"price_max=cheap",
+		"active_at=2024-03",
+		"start_date_from=05-2024&start_date_to=01-2024",
+		"end_date_to=13-2024",
+		"has_end_date=maybe",
+		"sort=user_id",
+		"sort=--price",
+		"sort=-price&cursor=" + priceCursor.Encode(),
+	} {
+		req := httptest.NewRequest("GET", "/api/v1/subscriptions?"+query, nil)
+		w := httptest.NewRecorder()
+
+		ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_SortedCursor() {
+	cursor := repository.ListCursor{SortBy: repository.SortByPrice, Desc: true, Value: "500", ID: 1}
+
+	req := httptest.NewRequest("GET", "/api/v1/subscriptions?sort=-price&cursor="+cursor.Encode(), nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		ListSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{
+			Limit:    10,
+			SortBy:   repository.SortByPrice,
+			SortDesc: true,
+			After:    &cursor,
+		}).
+		Return(&repository.SubscriptionPage{}, nil)
+
+	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
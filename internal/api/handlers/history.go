@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type SubscriptionSnapshot struct {
+	ServiceName string  `json:"service_name"`
+	Price       int     `json:"price"`
+	UserID      string  `json:"user_id"`
+	StartDate   string  `json:"start_date" example:"01-2024"`
+	EndDate     *string `json:"end_date,omitempty" example:"12-2024"`
+	Version     int     `json:"version"`
+	DeletedAt   *string `json:"deleted_at,omitempty"`
+}
+
+type SubscriptionHistoryItem struct {
+	ID        int64                 `json:"id"`
+	Action    string                `json:"action" example:"updated"`
+	Before    *SubscriptionSnapshot `json:"before,omitempty"`
+	After     *SubscriptionSnapshot `json:"after,omitempty"`
+	RequestID string                `json:"request_id,omitempty"`
+	ChangedAt string                `json:"changed_at" example:"2025-01-15T10:00:00Z"`
+}
+
+type GetSubscriptionHistoryResponse struct {
+	SubscriptionID int64                     `json:"subscription_id"`
+	History        []SubscriptionHistoryItem `json:"history"`
+}
+
+func toSubscriptionSnapshot(snapshot *repository.SubscriptionSnapshot) *SubscriptionSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+
+	result := &SubscriptionSnapshot{
+		ServiceName: snapshot.ServiceName,
+		Price:       snapshot.PriceRub,
+		UserID:      snapshot.UserID.String(),
+		StartDate:   snapshot.StartDate.Format("01-2006"),
+		Version:     snapshot.Version,
+		DeletedAt:   formatDeletedAt(snapshot.DeletedAt),
+	}
+	if snapshot.EndDate != nil {
+		endDate := snapshot.EndDate.Format("01-2006")
+		result.EndDate = &endDate
+	}
+
+	return result
+}
+
+// @Summary      Get subscription history
+// @Description  List every change of the subscription, oldest first, with the field values before and after the change
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path      int  true  "Subscription ID"
+// @Success      200  {object}  GetSubscriptionHistoryResponse
+// @Failure      400  {object}  ErrorResponse  "Invalid subscription ID"
+// @Failure      404  {object}  ErrorResponse  "Subscription not found"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /subscriptions/{id}/history [get]
+func GetSubscriptionHistory(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.history.GetSubscriptionHistory"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidSubscriptionID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		records, err := subscriptionService.GetSubscriptionHistory(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrSubscriptionNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrSubscriptionNotFound)
+				return
+			}
+			reqLog.Error("get subscription history failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		items := make([]SubscriptionHistoryItem, 0, len(records))
+		for _, record := range records {
+			items = append(items, SubscriptionHistoryItem{
+				ID:        record.ID,
+				Action:    string(record.Action),
+				Before:    toSubscriptionSnapshot(record.Before),
+				After:     toSubscriptionSnapshot(record.After),
+				RequestID: record.RequestID,
+				ChangedAt: record.ChangedAt.UTC().Format(time.RFC3339),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(GetSubscriptionHistoryResponse{
+			SubscriptionID: id,
+			History:        items,
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *SubscriptionHandlersSuite) TestGetSubscriptionHistory_Success() {
+	subscriptionID := int64(42)
+	userID := uuid.New()
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	changedAt := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions/{id}/history", GetSubscriptionHistory(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("GET", "/subscriptions/42/history", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		GetSubscriptionHistory(gomock.Any(), subscriptionID).
+		Return([]repository.SubscriptionHistoryRecord{
+			{
+				ID:             7,
+				SubscriptionID: subscriptionID,
+				Action:         repository.HistoryActionUpdated,
+				Before:         &repository.SubscriptionSnapshot{ServiceName: "Netflix", PriceRub: 500, UserID: userID, StartDate: startDate, Version: 1},
+				After:          &repository.SubscriptionSnapshot{ServiceName: "Netflix", PriceRub: 600, UserID: userID, StartDate: startDate, Version: 2},
+				RequestID:      "req-1",
+				ChangedAt:      changedAt,
+			},
+		}, nil)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response GetSubscriptionHistoryResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(subscriptionID, response.SubscriptionID)
+	s.Require().Len(response.History, 1)
+
+	item := response.History[0]
+	s.Equal("updated", item.Action)
+	s.Equal("req-1", item.RequestID)
+	s.Equal("2025-02-03T04:05:06Z", item.ChangedAt)
+	s.Require().NotNil(item.Before)
+	s.Require().NotNil(item.After)
+	s.Equal(500, item.Before.Price)
+	s.Equal(600, item.After.Price)
+	s.Equal("01-2024", item.After.StartDate)
+}
+
+func (s *SubscriptionHandlersSuite) TestGetSubscriptionHistory_NotFound() {
+	router := chi.NewRouter()
+	router.Get("/subscriptions/{id}/history", GetSubscriptionHistory(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("GET", "/subscriptions/42/history", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		GetSubscriptionHistory(gomock.Any(), int64(42)).
+		Return(nil, repository.ErrSubscriptionNotFound)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestGetSubscriptionHistory_InvalidID() {
+	router := chi.NewRouter()
+	router.Get("/subscriptions/{id}/history", GetSubscriptionHistory(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("GET", "/subscriptions/abc/history", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
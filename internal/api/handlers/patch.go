@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	serv "EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+const ErrUnsupportedMediaType = "unsupported content type, expected application/merge-patch+json"
+
+// SubscriptionMergePatch documents the PATCH body. Omitted fields are kept, null
+// end_date makes the subscription open-ended; other fields cannot be null.
+type SubscriptionMergePatch struct {
+	ServiceName        *string `json:"service_name,omitempty" example:"Netflix"`
+	Price              *int    `json:"price,omitempty" example:"600"`
+	PriceEffectiveFrom *string `json:"price_effective_from,omitempty" example:"03-2025"`
+	ResetPriceHistory  *bool   `json:"reset_price_history,omitempty" example:"false"`
+	StartDate          *string `json:"start_date,omitempty" example:"01-2024"`
+	EndDate            *string `json:"end_date,omitempty" example:"12-2024" extensions:"x-nullable"`
+}
+
+// @Summary      Patch subscription
+// @Description  Partially update a subscription with a JSON Merge Patch (RFC 7396). Omitted fields are kept, "end_date": null makes the subscription open-ended. service_name, price and start_date cannot be null, user_id cannot be changed. Date format: MM-YYYY.
+// @Tags         subscriptions
+// @Accept       application/merge-patch+json,json
+// @Produce      json
+// @Param        id        path      int                     true   "Subscription ID"
+// @Param        If-Match  header    string                  false  "ETag from GET /subscriptions/{id}"
+// @Param        input     body      SubscriptionMergePatch  true   "Fields to change"
+// @Success      200       {object}  map[string]string       "Successfully updated"
+// @Header       200       {string}  ETag                    "New subscription version"
+// @Failure      400       {object}  ErrorResponse           "Invalid patch or validation error"
+// @Failure      404       {object}  ErrorResponse           "Subscription not found"
+// @Failure      409       {object}  ErrorResponse           "Conflict - duplicate subscription (user_id + service_id + start_date)"
+// @Failure      412       {object}  ErrorResponse           "Subscription was modified since the ETag was issued"
+// @Failure      415       {object}  ErrorResponse           "Unsupported content type"
+// @Failure      500       {object}  ErrorResponse           "Internal server error"
+// @Router       /subscriptions/{id} [patch]
+func PatchSubscription(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.patch.PatchSubscription"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidSubscriptionID)
+			return
+		}
+
+		if !isMergePatchContentType(r.Header.Get("Content-Type")) {
+			response.WriteError(w, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType)
+			return
+		}
+
+		version, ok := parseIfMatch(r)
+		if !ok {
+			response.WriteError(w, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+
+		var doc map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil || doc == nil {
+			reqLog.Error("failed to decode request", slog.Any("err", err))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		patch, err := parseMergePatch(doc)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments+": "+err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		newVersion, err := subscriptionService.UpdateSubscription(ctx, id, patch, version)
+		if err != nil {
+			writeUpdateError(w, reqLog, err)
+			return
+		}
+
+		w.Header().Set("ETag", formatETag(newVersion))
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+		})
+	}
+}
+
+// isMergePatchContentType accepts merge patches and, for clients that cannot set the
+// type, plain JSON.
+func isMergePatchContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == mergePatchContentType || mediaType == "application/json"
+}
+
+// parseMergePatch turns a merge patch document into a SubscriptionPatch. A null
+// member removes the field, which is only allowed for end_date.
+func parseMergePatch(doc map[string]json.RawMessage) (serv.SubscriptionPatch, error) {
+	var patch serv.SubscriptionPatch
+
+	if len(doc) == 0 {
+		return patch, errors.New("at least one field must be provided")
+	}
+
+	for key, raw := range doc {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+
+		switch key {
+		case "service_name":
+			value, err := patchString(key, raw, isNull)
+			if err != nil {
+				return patch, err
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return patch, errors.New("service_name cannot be empty")
+			}
+			patch.ServiceName = &value
+		case "price":
+			if isNull {
+				return patch, errors.New("price cannot be null")
+			}
+			var price int
+			if err := json.Unmarshal(raw, &price); err != nil {
+				return patch, errors.New("price must be an integer")
+			}
+			patch.Price = &price
+		case "price_effective_from":
+			// The month only qualifies the price change, null is the same as omitting it.
+			if isNull {
+				continue
+			}
+			value, err := patchString(key, raw, false)
+			if err != nil {
+				return patch, err
+			}
+			patch.PriceEffectiveFrom = &value
+		case "reset_price_history":
+			if isNull {
+				continue
+			}
+			if err := json.Unmarshal(raw, &patch.ResetPriceHistory); err != nil {
+				return patch, errors.New("reset_price_history must be a boolean")
+			}
+		case "start_date":
+			value, err := patchString(key, raw, isNull)
+			if err != nil {
+				return patch, err
+			}
+			patch.StartDate = &value
+		case "end_date":
+			if isNull {
+				patch.ClearEndDate = true
+				continue
+			}
+			value, err := patchString(key, raw, false)
+			if err != nil {
+				return patch, err
+			}
+			patch.EndDate = &value
+		default:
+			return patch, fmt.Errorf("field %q cannot be changed", key)
+		}
+	}
+
+	return patch, nil
+}
+
+func patchString(key string, raw json.RawMessage, isNull bool) (string, error) {
+	if isNull {
+		return "", fmt.Errorf("%s cannot be null", key)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return value, nil
+}
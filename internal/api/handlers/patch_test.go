@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *SubscriptionHandlersSuite) servePatch(body, contentType string, headers map[string]string) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.Patch("/subscriptions/{id}", PatchSubscription(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("PATCH", "/subscriptions/123", bytes.NewReader([]byte(body)))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *SubscriptionHandlersSuite) TestPatchSubscription_ClearEndDate() {
+	price := 700
+
+	s.subscriptionService.EXPECT().
+		UpdateSubscription(gomock.Any(), int64(123), serv.SubscriptionPatch{
+			Price:        &price,
+			ClearEndDate: true,
+		}, nil).
+		Return(2, nil)
+
+	w := s.servePatch(`{"price":700,"end_date":null}`, "application/merge-patch+json", nil)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestPatchSubscription_StartAfterEnd() {
+	startDate := "12-2025"
+
+	s.subscriptionService.EXPECT().
+		UpdateSubscription(gomock.Any(), int64(123), serv.SubscriptionPatch{StartDate: &startDate}, nil).
+		Return(0, fmt.Errorf("%w: end date must be after start date", serv.ErrValidation))
+
+	w := s.servePatch(`{"start_date":"12-2025"}`, "application/merge-patch+json", nil)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestPatchSubscription_Fields() {
+	serviceName := "Spotify"
+	effectiveFrom := "03-2025"
+	price := 700
+	startDate := "01-2024"
+	endDate := "12-2025"
+	version := 3
+
+	s.subscriptionService.EXPECT().
+		UpdateSubscription(gomock.Any(), int64(123), serv.SubscriptionPatch{
+			ServiceName:        &serviceName,
+			Price:              &price,
+			PriceEffectiveFrom: &effectiveFrom,
+			StartDate:          &startDate,
+			EndDate:            &endDate,
+		}, &version).
+		Return(2, nil)
+
+	body := `{"service_name":" Spotify ","price":700,"price_effective_from":"03-2025","start_date":"01-2024","end_date":"12-2025"}`
+	w := s.servePatch(body, "application/json", map[string]string{"If-Match": `"3"`})
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(`"2"`, w.Header().Get("ETag"))
+}
+
+func (s *SubscriptionHandlersSuite) TestPatchSubscription_ResetPriceHistory() {
+	price := 700
+
+	s.subscriptionService.EXPECT().
+		UpdateSubscription(gomock.Any(), int64(123), serv.SubscriptionPatch{
+			Price:             &price,
+			ResetPriceHistory: true,
+		}, nil).
+		Return(2, nil)
+
+	w := s.servePatch(`{"price":700,"reset_price_history":true}`, "application/merge-patch+json", nil)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestPatchSubscription_InvalidPatch() {
+	for _, body := range []string{
+		`{}`,
+		`[]`,
+		`null`,
+		`"price"`,
+		`{"price":null}`,
+		`{"price":"600"}`,
+		`{"price":6.5}`,
+		`{"service_name":null}`,
+		`{"service_name":""}`,
+		`{"start_date":null}`,
+		`{"end_date":12}`,
+		`{"price":700,"reset_price_history":"yes"}`,
+		`{"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba"}`,
+	} {
+		w := s.servePatch(body, "application/merge-patch+json", nil)
+
+		s.Equal(http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func (s *SubscriptionHandlersSuite) TestPatchSubscription_UnsupportedMediaType() {
+	w := s.servePatch(`{"price":700}`, "text/plain", nil)
+
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestPatchSubscription_Errors() {
+	for err, code := range map[error]int{
+		repository.ErrSubscriptionNotFound:      http.StatusNotFound,
+		repository.ErrVersionMismatch:           http.StatusPreconditionFailed,
+		repository.ErrSubscriptionAlreadyExists: http.StatusConflict,
+		serv.ErrValidation:                      http.StatusBadRequest,
+	} {
+		s.subscriptionService.EXPECT().
+			UpdateSubscription(gomock.Any(), int64(123), gomock.Any(), nil).
+			Return(0, err)
+
+		w := s.servePatch(`{"start_date":"02-2024"}`, "", nil)
+
+		s.Equal(code, w.Code, err.Error())
+	}
+}
@@ -5,12 +5,15 @@ package handlers
 import (
 	"EffectiveMobile/internal/repository"
 	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -24,29 +27,76 @@ const (
 	ErrInternalServerStats   = "internal server error"
 )
 
+const (
+	defaultForecastMonths = 12
+	maxForecastMonths     = 60
+)
+
 var ErrValidation = errors.New("validation error")
 
 type StatsService interface {
-	GetTotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time) (*repository.TotalCostStats, error)
+	GetTotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.TotalCostStats, error)
+	GetGroupedCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, groupBy []repository.GroupByField, includeDeleted bool) (*repository.GroupedCostStats, error)
+	GetMonthlyCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.MonthlyCostStats, error)
+	GetForecast(ctx context.Context, userID *uuid.UUID, serviceName *string, months int, groupBy []repository.GroupByField) (*repository.ForecastStats, error)
 	ParseMonth(s string) (time.Time, error)
 	FormatDate(date *time.Time) string
 	FormatUUID(uuid *uuid.UUID) *string
 }
 
 type GetTotalStatsRequest struct {
-	UserID      *string `json:"user_id,omitempty"`
-	ServiceName *string `json:"service_name,omitempty"`
-	StartDate   *string `json:"start_date,omitempty"`
-	EndDate     *string `json:"end_date,omitempty"`
+	UserID         *string `json:"user_id,omitempty"`
+	ServiceName    *string `json:"service_name,omitempty"`
+	StartDate      *string `json:"start_date,omitempty"`
+	EndDate        *string `json:"end_date,omitempty"`
+	GroupBy        *string `json:"group_by,omitempty"`
+	IncludeDeleted *string `json:"include_deleted,omitempty"`
 }
 
 type GetTotalStatsResponse struct {
+	TotalCost          int             `json:"total_cost"`
+	Period             Period          `json:"period"`
+	Filters            Filters         `json:"filters"`
+	SubscriptionsCount int             `json:"subscriptions_count"`
+	GroupBy            []string        `json:"group_by,omitempty"`
+	Groups             []CostGroupItem `json:"groups,omitempty"`
+}
+
+type CostGroupItem struct {
+	ServiceName        *string `json:"service_name,omitempty" example:"Netflix"`
+	UserID             *string `json:"user_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
 	TotalCost          int     `json:"total_cost"`
-	Period             Period  `json:"period"`
-	Filters            Filters `json:"filters"`
 	SubscriptionsCount int     `json:"subscriptions_count"`
 }
 
+type MonthlyStatsItem struct {
+	Month              string `json:"month" example:"01-2024"`
+	TotalCost          int    `json:"total_cost"`
+	SubscriptionsCount int    `json:"subscriptions_count"`
+}
+
+type GetMonthlyStatsResponse struct {
+	TotalCost int                `json:"total_cost"`
+	Period    Period             `json:"period"`
+	Filters   Filters            `json:"filters"`
+	Months    []MonthlyStatsItem `json:"months"`
+}
+
+type ForecastMonthItem struct {
+	Month              string          `json:"month" example:"01-2024"`
+	TotalCost          int             `json:"total_cost"`
+	SubscriptionsCount int             `json:"subscriptions_count"`
+	Groups             []CostGroupItem `json:"groups"`
+}
+
+type GetForecastStatsResponse struct {
+	TotalCost int                 `json:"total_cost"`
+	Period    Period              `json:"period"`
+	Filters   Filters             `json:"filters"`
+	GroupBy   []string            `json:"group_by"`
+	Months    []ForecastMonthItem `json:"months"`
+}
+
 func getStringParam(r *http.Request, key string) *string {
 	if value := r.URL.Query().Get(key); value != "" {
 		return &value
@@ -55,10 +105,12 @@ func getStringParam(r *http.Request, key string) *string {
 }
 
 type validatedStatsParams struct {
-	UserID      *uuid.UUID
-	ServiceName *string
-	StartDate   *time.Time
-	EndDate     *time.Time
+	UserID         *uuid.UUID
+	ServiceName    *string
+	StartDate      *time.Time
+	EndDate        *time.Time
+	GroupBy        []repository.GroupByField
+	IncludeDeleted bool
 }
 
 func validateStatsParams(req GetTotalStatsRequest, statsService StatsService) (*validatedStatsParams, error) {
@@ -96,9 +148,44 @@ func validateStatsParams(req GetTotalStatsRequest, statsService StatsService) (*
 		return nil, fmt.Errorf("%w: end date must be after start date", ErrValidation)
 	}
 
+	if req.GroupBy != nil {
+		groupBy, err := parseGroupBy(*req.GroupBy)
+		if err != nil {
+			return nil, err
+		}
+		params.GroupBy = groupBy
+	}
+
+	if req.IncludeDeleted != nil {
+		includeDeleted, err := strconv.ParseBool(*req.IncludeDeleted)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrValidation, ErrInvalidIncludeDeleted)
+		}
+		params.IncludeDeleted = includeDeleted
+	}
+
 	return params, nil
 }
 
+func parseGroupBy(value string) ([]repository.GroupByField, error) {
+	var groupBy []repository.GroupByField
+	seen := make(map[repository.GroupByField]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		field := repository.GroupByField(strings.TrimSpace(part))
+		if field != repository.GroupByService && field != repository.GroupByUser {
+			return nil, fmt.Errorf("%w: unknown group_by value: %s", ErrValidation, part)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("%w: duplicate group_by value: %s", ErrValidation, field)
+		}
+		seen[field] = true
+		groupBy = append(groupBy, field)
+	}
+
+	return groupBy, nil
+}
+
 type Period struct {
 	Start string `json:"start"`
 	End   string `json:"end"`
@@ -114,13 +201,15 @@ type ErrorResponse struct {
 }
 
 // @Summary      Get total stats
-// @Description  Calculate total cost of subscriptions for the period. Date format: MM-YYYY (e.g., "01-2024", "12-2024")
+// @Description  Calculate total cost of subscriptions for the period. Date format: MM-YYYY (e.g., "01-2024", "12-2024"). With group_by the cost is also split into buckets per service, per user or per service and user.
 // @Tags         stats
 // @Produce      json
 // @Param        user_id       query     string  false  "User UUID"                    example(550e8400-e29b-41d4-a716-446655440000)
 // @Param        service_name  query     string  false  "Service name"                 example(Netflix)
 // @Param        start_date    query     string  false  "Period start (MM-YYYY)"       example(01-2024)
 // @Param        end_date      query     string  false  "Period end (MM-YYYY)"         example(12-2024)
+// @Param        group_by      query     string  false  "Grouping: service, user or service,user"  example(service,user)
+// @Param        include_deleted  query  bool    false  "Also count deleted subscriptions, until the month they were deleted in"  default(false)
 // @Success      200           {object}  GetTotalStatsResponse
 // @Failure      400           {object}  ErrorResponse  "Invalid arguments or date format"
 // @Failure      500           {object}  ErrorResponse  "Internal server error"
@@ -130,15 +219,21 @@ func GetTotalStats(statsService StatsService, log *slog.Logger) http.HandlerFunc
 	log = log.With(slog.String("op", op))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		reqLog := log.With(
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
 		req := GetTotalStatsRequest{
-			UserID:      getStringParam(r, "user_id"),
-			ServiceName: getStringParam(r, "service_name"),
-			StartDate:   getStringParam(r, "start_date"),
-			EndDate:     getStringParam(r, "end_date"),
+			UserID:         getStringParam(r, "user_id"),
+			ServiceName:    getStringParam(r, "service_name"),
+			StartDate:      getStringParam(r, "start_date"),
+			EndDate:        getStringParam(r, "end_date"),
+			GroupBy:        getStringParam(r, "group_by"),
+			IncludeDeleted: getStringParam(r, "include_deleted"),
 		}
 
 		params, err := validateStatsParams(req, statsService)
@@ -150,7 +245,12 @@ func GetTotalStats(statsService StatsService, log *slog.Logger) http.HandlerFunc
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
-		stats, err := statsService.GetTotalCost(ctx, params.UserID, params.ServiceName, params.StartDate, params.EndDate)
+		if params.GroupBy != nil {
+			writeGroupedStats(ctx, w, reqLog, statsService, params)
+			return
+		}
+
+		stats, err := statsService.GetTotalCost(ctx, params.UserID, params.ServiceName, params.StartDate, params.EndDate, params.IncludeDeleted)
 
 		if err != nil {
 			reqLog.Error("get total cost failed", slog.String("err", err.Error()))
@@ -177,8 +277,241 @@ func GetTotalStats(statsService StatsService, log *slog.Logger) http.HandlerFunc
 	}
 }
 
+func writeGroupedStats(ctx context.Context, w http.ResponseWriter, reqLog *slog.Logger, statsService StatsService, params *validatedStatsParams) {
+	stats, err := statsService.GetGroupedCost(ctx, params.UserID, params.ServiceName, params.StartDate, params.EndDate, params.GroupBy, params.IncludeDeleted)
+	if err != nil {
+		reqLog.Error("get grouped cost failed", slog.String("err", err.Error()))
+		response.WriteError(w, http.StatusInternalServerError, ErrInternalServerStats)
+		return
+	}
+
+	statsResponse := GetTotalStatsResponse{
+		TotalCost: stats.TotalCost,
+		Period: Period{
+			Start: statsService.FormatDate(stats.StartDate),
+			End:   statsService.FormatDate(stats.EndDate),
+		},
+		Filters: Filters{
+			UserID:      statsService.FormatUUID(stats.UserID),
+			ServiceName: stats.ServiceName,
+		},
+		SubscriptionsCount: stats.SubscriptionsCount,
+		GroupBy:            groupByNames(stats.GroupBy),
+		Groups:             costGroupItems(stats.Groups),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(statsResponse)
+}
+
+func groupByNames(groupBy []repository.GroupByField) []string {
+	names := make([]string, 0, len(groupBy))
+	for _, field := range groupBy {
+		names = append(names, string(field))
+	}
+	return names
+}
+
+func costGroupItems(groups []repository.CostGroup) []CostGroupItem {
+	items := make([]CostGroupItem, 0, len(groups))
+	for _, g := range groups {
+		item := CostGroupItem{
+			ServiceName:        g.ServiceName,
+			TotalCost:          g.TotalCost,
+			SubscriptionsCount: g.SubscriptionsCount,
+		}
+		if g.UserID != nil {
+			userID := g.UserID.String()
+			item.UserID = &userID
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// @Summary      Get monthly stats
+// @Description  Cost breakdown by calendar month for the period. Every month of the period is returned, including months without active subscriptions. The sum of all months equals /stats/total for the same filters. Date format: MM-YYYY (e.g., "01-2024", "12-2024")
+// @Tags         stats
+// @Produce      json
+// @Param        user_id       query     string  false  "User UUID"                    example(550e8400-e29b-41d4-a716-446655440000)
+// @Param        service_name  query     string  false  "Service name"                 example(Netflix)
+// @Param        start_date    query     string  false  "Period start (MM-YYYY)"       example(01-2024)
+// @Param        end_date      query     string  false  "Period end (MM-YYYY)"         example(12-2024)
+// @Param        include_deleted  query  bool    false  "Also count deleted subscriptions, until the month they were deleted in"  default(false)
+// @Success      200           {object}  GetMonthlyStatsResponse
+// @Failure      400           {object}  ErrorResponse  "Invalid arguments or date format"
+// @Failure      500           {object}  ErrorResponse  "Internal server error"
+// @Router       /stats/monthly [get]
+func GetMonthlyStats(statsService StatsService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.stats.GetMonthlyStats"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		req := GetTotalStatsRequest{
+			UserID:         getStringParam(r, "user_id"),
+			ServiceName:    getStringParam(r, "service_name"),
+			StartDate:      getStringParam(r, "start_date"),
+			EndDate:        getStringParam(r, "end_date"),
+			IncludeDeleted: getStringParam(r, "include_deleted"),
+		}
+
+		params, err := validateStatsParams(req, statsService)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		stats, err := statsService.GetMonthlyCost(ctx, params.UserID, params.ServiceName, params.StartDate, params.EndDate, params.IncludeDeleted)
+		if err != nil {
+			reqLog.Error("get monthly cost failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServerStats)
+			return
+		}
+
+		months := make([]MonthlyStatsItem, 0, len(stats.Months))
+		for _, m := range stats.Months {
+			months = append(months, MonthlyStatsItem{
+				Month:              statsService.FormatDate(&m.Month),
+				TotalCost:          m.TotalCost,
+				SubscriptionsCount: m.SubscriptionsCount,
+			})
+		}
+
+		statsResponse := GetMonthlyStatsResponse{
+			TotalCost: stats.TotalCost,
+			Period: Period{
+				Start: statsService.FormatDate(stats.StartDate),
+				End:   statsService.FormatDate(stats.EndDate),
+			},
+			Filters: Filters{
+				UserID:      statsService.FormatUUID(stats.UserID),
+				ServiceName: stats.ServiceName,
+			},
+			Months: months,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(statsResponse)
+	}
+}
+
+// @Summary      Get spend forecast
+// @Description  Projected cost of the current month and the following ones, per month and split per service, per user or per service and user. Open-ended subscriptions are expected to continue, subscriptions with end_date stop after that month. Price changes scheduled with price_effective_from are taken into account.
+// @Tags         stats
+// @Produce      json
+// @Param        months        query     int     false  "Number of months, starting with the current one"  minimum(1)  maximum(60)  default(12)
+// @Param        group_by      query     string  false  "Grouping: service, user or service,user"  default(service)  example(user)
+// @Param        user_id       query     string  false  "User UUID"     example(550e8400-e29b-41d4-a716-446655440000)
+// @Param        service_name  query     string  false  "Service name"  example(Netflix)
+// @Success      200           {object}  GetForecastStatsResponse
+// @Failure      400           {object}  ErrorResponse  "Invalid arguments"
+// @Failure      500           {object}  ErrorResponse  "Internal server error"
+// @Router       /stats/forecast [get]
+func GetForecastStats(statsService StatsService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.stats.GetForecastStats"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		months, err := parseForecastMonths(r.URL.Query().Get("months"))
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		groupBy := string(repository.GroupByService)
+		if value := getStringParam(r, "group_by"); value != nil {
+			groupBy = *value
+		}
+
+		req := GetTotalStatsRequest{
+			UserID:      getStringParam(r, "user_id"),
+			ServiceName: getStringParam(r, "service_name"),
+			GroupBy:     &groupBy,
+		}
+
+		params, err := validateStatsParams(req, statsService)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		forecast, err := statsService.GetForecast(ctx, params.UserID, params.ServiceName, months, params.GroupBy)
+		if err != nil {
+			reqLog.Error("get forecast failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServerStats)
+			return
+		}
+
+		items := make([]ForecastMonthItem, 0, len(forecast.Months))
+		for _, m := range forecast.Months {
+			items = append(items, ForecastMonthItem{
+				Month:              statsService.FormatDate(&m.Month),
+				TotalCost:          m.TotalCost,
+				SubscriptionsCount: m.SubscriptionsCount,
+				Groups:             costGroupItems(m.Groups),
+			})
+		}
+
+		statsResponse := GetForecastStatsResponse{
+			TotalCost: forecast.TotalCost,
+			Period: Period{
+				Start: statsService.FormatDate(&forecast.StartDate),
+				End:   statsService.FormatDate(&forecast.EndDate),
+			},
+			Filters: Filters{
+				UserID:      statsService.FormatUUID(forecast.UserID),
+				ServiceName: forecast.ServiceName,
+			},
+			GroupBy: groupByNames(forecast.GroupBy),
+			Months:  items,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(statsResponse)
+	}
+}
+
+func parseForecastMonths(value string) (int, error) {
+	if value == "" {
+		return defaultForecastMonths, nil
+	}
+
+	months, err := strconv.Atoi(value)
+	if err != nil || months < 1 || months > maxForecastMonths {
+		return 0, fmt.Errorf("%w: months must be from 1 to %d", ErrValidation, maxForecastMonths)
+	}
+	return months, nil
+}
+
 func GetStatRoutes(statsService StatsService, log *slog.Logger) chi.Router {
 	r := chi.NewRouter()
 	r.Get("/total", GetTotalStats(statsService, log))
+	r.Get("/monthly", GetMonthlyStats(statsService, log))
+	r.Get("/forecast", GetForecastStats(statsService, log))
 	return r
 }
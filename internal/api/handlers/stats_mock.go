@@ -71,19 +71,64 @@ func (mr *MockStatsServiceMockRecorder) FormatUUID(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FormatUUID", reflect.TypeOf((*MockStatsService)(nil).FormatUUID), arg0)
 }
 
+// GetForecast mocks base method.
+func (m *MockStatsService) GetForecast(ctx context.Context, userID *uuid.UUID, serviceName *string, months int, groupBy []repository.GroupByField) (*repository.ForecastStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForecast", ctx, userID, serviceName, months, groupBy)
+	ret0, _ := ret[0].(*repository.ForecastStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForecast indicates an expected call of GetForecast.
+func (mr *MockStatsServiceMockRecorder) GetForecast(ctx, userID, serviceName, months, groupBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecast", reflect.TypeOf((*MockStatsService)(nil).GetForecast), ctx, userID, serviceName, months, groupBy)
+}
+
+// GetGroupedCost mocks base method.
+func (m *MockStatsService) GetGroupedCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, groupBy []repository.GroupByField, includeDeleted bool) (*repository.GroupedCostStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupedCost", ctx, userID, serviceName, startDate, endDate, groupBy, includeDeleted)
+	ret0, _ := ret[0].(*repository.GroupedCostStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupedCost indicates an expected call of GetGroupedCost.
+func (mr *MockStatsServiceMockRecorder) GetGroupedCost(ctx, userID, serviceName, startDate, endDate, groupBy, includeDeleted any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupedCost", reflect.TypeOf((*MockStatsService)(nil).GetGroupedCost), ctx, userID, serviceName, startDate, endDate, groupBy, includeDeleted)
+}
+
+// GetMonthlyCost mocks base method.
+func (m *MockStatsService) GetMonthlyCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.MonthlyCostStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMonthlyCost", ctx, userID, serviceName, startDate, endDate, includeDeleted)
+	ret0, _ := ret[0].(*repository.MonthlyCostStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMonthlyCost indicates an expected call of GetMonthlyCost.
+func (mr *MockStatsServiceMockRecorder) GetMonthlyCost(ctx, userID, serviceName, startDate, endDate, includeDeleted any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMonthlyCost", reflect.TypeOf((*MockStatsService)(nil).GetMonthlyCost), ctx, userID, serviceName, startDate, endDate, includeDeleted)
+}
+
 // GetTotalCost mocks base method.
-func (m *MockStatsService) GetTotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time) (*repository.TotalCostStats, error) {
+func (m *MockStatsService) GetTotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.TotalCostStats, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTotalCost", ctx, userID, serviceName, startDate, endDate)
+	ret := m.ctrl.Call(m, "GetTotalCost", ctx, userID, serviceName, startDate, endDate, includeDeleted)
 	ret0, _ := ret[0].(*repository.TotalCostStats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTotalCost indicates an expected call of GetTotalCost.
-func (mr *MockStatsServiceMockRecorder) GetTotalCost(ctx, userID, serviceName, startDate, endDate any) *gomock.Call {
+func (mr *MockStatsServiceMockRecorder) GetTotalCost(ctx, userID, serviceName, startDate, endDate, includeDeleted any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalCost", reflect.TypeOf((*MockStatsService)(nil).GetTotalCost), ctx, userID, serviceName, startDate, endDate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalCost", reflect.TypeOf((*MockStatsService)(nil).GetTotalCost), ctx, userID, serviceName, startDate, endDate, includeDeleted)
 }
 
 // ParseMonth mocks base method.
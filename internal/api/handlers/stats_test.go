@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -50,28 +51,8 @@ func (s *StatsHandlersSuite) TestGetTotalStats_Success() {
 	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)
 
-	subscriptions := []repository.SubscriptionCost{
-		{
-			ID:          1,
-			StartDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
-			EndDate:     &endDate,
-			PriceRub:    100,
-			UserID:      userID,
-			ServiceName: serviceName,
-		},
-		{
-			ID:          2,
-			StartDate:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
-			EndDate:     &endDate,
-			PriceRub:    200,
-			UserID:      userID,
-			ServiceName: serviceName,
-		},
-	}
-
 	expectedStats := &repository.TotalCostStats{
 		TotalCost:          2600,
-		Subscriptions:      subscriptions,
 		UserID:             &userID,
 		ServiceName:        &serviceName,
 		StartDate:          &startDate,
@@ -91,7 +72,7 @@ func (s *StatsHandlersSuite) TestGetTotalStats_Success() {
 		Return(endDate, nil)
 
 	s.statsService.EXPECT().
-		GetTotalCost(gomock.Any(), &userID, &serviceName, &startDate, &endDate).
+		GetTotalCost(gomock.Any(), &userID, &serviceName, &startDate, &endDate, false).
 		Return(expectedStats, nil)
 
 	s.statsService.EXPECT().
@@ -117,6 +98,24 @@ func (s *StatsHandlersSuite) TestGetTotalStats_Success() {
 	s.Equal(expectedStats.SubscriptionsCount, response.SubscriptionsCount)
 }
 
+func (s *StatsHandlersSuite) TestGetTotalStats_IncludeDeleted() {
+	s.statsService.EXPECT().
+		GetTotalCost(gomock.Any(), nil, nil, nil, nil, true).
+		Return(&repository.TotalCostStats{TotalCost: 100}, nil)
+	s.statsService.EXPECT().FormatDate(gomock.Any()).Return("").Times(2)
+	s.statsService.EXPECT().FormatUUID(nil).Return(nil)
+
+	w := httptest.NewRecorder()
+	GetTotalStats(s.statsService, s.logger)(w, httptest.NewRequest("GET", "/stats/total?include_deleted=true", nil))
+
+	s.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	GetTotalStats(s.statsService, s.logger)(w, httptest.NewRequest("GET", "/stats/total?include_deleted=maybe", nil))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
 func (s *StatsHandlersSuite) TestGetTotalStats_ServiceError() {
 	userID := uuid.New()
 	serviceName := "Netflix"
@@ -135,7 +134,7 @@ func (s *StatsHandlersSuite) TestGetTotalStats_ServiceError() {
 		Return(endDate, nil)
 
 	s.statsService.EXPECT().
-		GetTotalCost(gomock.Any(), &userID, &serviceName, &startDate, &endDate).
+		GetTotalCost(gomock.Any(), &userID, &serviceName, &startDate, &endDate, false).
 		Return(nil, repository.ErrSubscriptionNotCreated)
 
 	GetTotalStats(s.statsService, s.logger)(w, req)
@@ -151,7 +150,7 @@ func (s *StatsHandlersSuite) TestGetTotalStats_InvalidDate() {
 
 	s.statsService.EXPECT().
 		ParseMonth("invalid-date").
-		Return(time.Time{}, repository.ErrInvalidDateFormat)
+		Return(time.Time{}, errors.New("invalid date format"))
 
 	GetTotalStats(s.statsService, s.logger)(w, req)
 
@@ -178,3 +177,177 @@ func (s *StatsHandlersSuite) TestGetTotalStats_EndDateBeforeStartDate() {
 
 	s.Equal(http.StatusBadRequest, w.Code)
 }
+
+func (s *StatsHandlersSuite) TestGetMonthlyStats_Success() {
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	expectedStats := &repository.MonthlyCostStats{
+		TotalCost: 900,
+		Months: []repository.MonthlyCost{
+			{Month: startDate, TotalCost: 400, SubscriptionsCount: 1},
+			{Month: endDate, TotalCost: 500, SubscriptionsCount: 2},
+		},
+		StartDate: &startDate,
+		EndDate:   &endDate,
+	}
+
+	req := httptest.NewRequest("GET", "/stats/monthly?start_date=01-2024&end_date=02-2024", nil)
+	w := httptest.NewRecorder()
+
+	s.statsService.EXPECT().ParseMonth("01-2024").Return(startDate, nil)
+	s.statsService.EXPECT().ParseMonth("02-2024").Return(endDate, nil)
+
+	s.statsService.EXPECT().
+		GetMonthlyCost(gomock.Any(), nil, nil, &startDate, &endDate, false).
+		Return(expectedStats, nil)
+
+	s.statsService.EXPECT().FormatDate(gomock.Any()).DoAndReturn(func(date *time.Time) string {
+		return date.Format("01-2006")
+	}).Times(4)
+	s.statsService.EXPECT().FormatUUID(nil).Return(nil)
+
+	GetMonthlyStats(s.statsService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response GetMonthlyStatsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(900, response.TotalCost)
+	s.Equal(Period{Start: "01-2024", End: "02-2024"}, response.Period)
+	s.Equal([]MonthlyStatsItem{
+		{Month: "01-2024", TotalCost: 400, SubscriptionsCount: 1},
+		{Month: "02-2024", TotalCost: 500, SubscriptionsCount: 2},
+	}, response.Months)
+}
+
+func (s *StatsHandlersSuite) TestGetMonthlyStats_InvalidUserID() {
+	req := httptest.NewRequest("GET", "/stats/monthly?user_id=not-a-uuid", nil)
+	w := httptest.NewRecorder()
+
+	GetMonthlyStats(s.statsService, s.logger)(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *StatsHandlersSuite) TestGetTotalStats_GroupBy() {
+	userID := uuid.New()
+	serviceName := "Netflix"
+	groupBy := []repository.GroupByField{repository.GroupByService, repository.GroupByUser}
+
+	expectedStats := &repository.GroupedCostStats{
+		TotalCost:          1500,
+		SubscriptionsCount: 2,
+		GroupBy:            groupBy,
+		Groups: []repository.CostGroup{
+			{ServiceName: &serviceName, UserID: &userID, TotalCost: 1500, SubscriptionsCount: 2},
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/stats/total?group_by=service,user", nil)
+	w := httptest.NewRecorder()
+
+	s.statsService.EXPECT().
+		GetGroupedCost(gomock.Any(), nil, nil, nil, nil, groupBy, false).
+		Return(expectedStats, nil)
+	s.statsService.EXPECT().FormatDate(nil).Return("").Times(2)
+	s.statsService.EXPECT().FormatUUID(nil).Return(nil)
+
+	GetTotalStats(s.statsService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response GetTotalStatsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(1500, response.TotalCost)
+	s.Equal([]string{"service", "user"}, response.GroupBy)
+	s.Require().Len(response.Groups, 1)
+	s.Equal(serviceName, *response.Groups[0].ServiceName)
+	s.Equal(userID.String(), *response.Groups[0].UserID)
+	s.Equal(2, response.Groups[0].SubscriptionsCount)
+}
+
+func (s *StatsHandlersSuite) TestGetTotalStats_InvalidGroupBy() {
+	for _, groupBy := range []string{"plan", "service,service", "service,"} {
+		req := httptest.NewRequest("GET", "/stats/total?group_by="+groupBy, nil)
+		w := httptest.NewRecorder()
+
+		GetTotalStats(s.statsService, s.logger)(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, groupBy)
+	}
+}
+
+func (s *StatsHandlersSuite) TestGetForecastStats_Success() {
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	groupBy := []repository.GroupByField{repository.GroupByUser}
+
+	forecast := &repository.ForecastStats{
+		TotalCost: 900,
+		Months: []repository.ForecastMonth{
+			{Month: startDate, TotalCost: 500, SubscriptionsCount: 1, Groups: []repository.CostGroup{
+				{UserID: &userID, TotalCost: 500, SubscriptionsCount: 1},
+			}},
+			{Month: endDate, TotalCost: 400, SubscriptionsCount: 1, Groups: []repository.CostGroup{
+				{UserID: &userID, TotalCost: 400, SubscriptionsCount: 1},
+			}},
+		},
+		GroupBy:   groupBy,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	req := httptest.NewRequest("GET", "/stats/forecast?months=2&group_by=user", nil)
+	w := httptest.NewRecorder()
+
+	s.statsService.EXPECT().
+		GetForecast(gomock.Any(), nil, nil, 2, groupBy).
+		Return(forecast, nil)
+	s.statsService.EXPECT().FormatDate(gomock.Any()).DoAndReturn(func(date *time.Time) string {
+		return date.Format("01-2006")
+	}).Times(4)
+	s.statsService.EXPECT().FormatUUID(nil).Return(nil)
+
+	GetForecastStats(s.statsService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response GetForecastStatsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(900, response.TotalCost)
+	s.Equal(Period{Start: "01-2025", End: "02-2025"}, response.Period)
+	s.Equal([]string{"user"}, response.GroupBy)
+	s.Require().Len(response.Months, 2)
+	s.Equal("02-2025", response.Months[1].Month)
+	s.Require().Len(response.Months[1].Groups, 1)
+	s.Equal(userID.String(), *response.Months[1].Groups[0].UserID)
+	s.Equal(400, response.Months[1].Groups[0].TotalCost)
+}
+
+func (s *StatsHandlersSuite) TestGetForecastStats_Defaults() {
+	req := httptest.NewRequest("GET", "/stats/forecast", nil)
+	w := httptest.NewRecorder()
+
+	s.statsService.EXPECT().
+		GetForecast(gomock.Any(), nil, nil, defaultForecastMonths, []repository.GroupByField{repository.GroupByService}).
+		Return(&repository.ForecastStats{}, nil)
+	s.statsService.EXPECT().FormatDate(gomock.Any()).Return("").Times(2)
+	s.statsService.EXPECT().FormatUUID(nil).Return(nil)
+
+	GetForecastStats(s.statsService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *StatsHandlersSuite) TestGetForecastStats_InvalidArguments() {
+	for _, query := range []string{"months=0", "months=61", "months=abc", "group_by=plan", "user_id=not-a-uuid"} {
+		req := httptest.NewRequest("GET", "/stats/forecast?"+query, nil)
+		w := httptest.NewRecorder()
+
+		GetForecastStats(s.statsService, s.logger)(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, query)
+	}
+}
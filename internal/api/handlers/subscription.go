@@ -6,6 +6,7 @@ import (
 	"EffectiveMobile/internal/repository"
 	serv "EffectiveMobile/internal/service"
 	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
 	"context"
 	"encoding/json"
 	"errors"
@@ -24,20 +25,30 @@ import (
 )
 
 const (
-	ErrInvalidArguments      = "invalid arguments"
-	ErrInvalidSubscriptionID = "invalid subscription id"
-	ErrSubscriptionNotFound  = "subscription not found"
-	ErrSubscriptionExists    = "subscription already exists"
-	ErrInternalServer        = "internal server error"
-	ErrInvalidUserIDFormat   = "invalid user_id format"
+	ErrInvalidArguments       = "invalid arguments"
+	ErrInvalidSubscriptionID  = "invalid subscription id"
+	ErrSubscriptionNotFound   = "subscription not found"
+	ErrSubscriptionExists     = "subscription already exists"
+	ErrInternalServer         = "internal server error"
+	ErrInvalidUserIDFormat    = "invalid user_id format"
+	ErrPreconditionFailed     = "subscription was modified by another request"
+	ErrInvalidIncludeDeleted  = "invalid include_deleted value"
+	ErrSubscriptionNotDeleted = "subscription is not deleted"
+	ErrInvalidCursor          = "invalid cursor"
+	ErrCursorWithOffset       = "cursor cannot be combined with offset"
+	ErrInvalidIncludeTotal    = "invalid include_total value"
 )
 
 type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, serviceName string, price int, userID uuid.UUID, startDate, endDate string) (int64, error)
-	GetSubscription(ctx context.Context, id int64) (*repository.Subscription, error)
-	UpdateSubscription(ctx context.Context, id int64, serviceName *string, price *int, startDate, endDate *string) error
-	DeleteSubscription(ctx context.Context, id int64) error
-	ListSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams) ([]repository.Subscription, int, error)
+	CreateSubscriptions(ctx context.Context, items []serv.CreateSubscriptionInput, atomic bool) ([]serv.BatchResult, error)
+	GetSubscription(ctx context.Context, id int64, includeDeleted bool) (*repository.Subscription, error)
+	UpdateSubscription(ctx context.Context, id int64, patch serv.SubscriptionPatch, version *int) (int, error)
+	DeleteSubscription(ctx context.Context, id int64, version *int) error
+	RestoreSubscription(ctx context.Context, id int64) error
+	GetSubscriptionHistory(ctx context.Context, id int64) ([]repository.SubscriptionHistoryRecord, error)
+	ListSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams) (*repository.SubscriptionPage, error)
+	ExportSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error
 }
 
 type CreateSubscriptionRequest struct {
@@ -64,41 +75,90 @@ type CreateSubscriptionResponse struct {
 	ID     int64  `json:"id"`
 }
 
+// UpdateSubscriptionRequest replaces every field of the subscription, a missing or
+// null end_date makes it open-ended. The user cannot be changed, a body with user_id
+// or any other unknown field is rejected.
 type UpdateSubscriptionRequest struct {
-    ServiceName *string `json:"service_name,omitempty" validate:"omitempty,min=1"`
-	Price       *int    `json:"price,omitempty" validate:"omitempty,min=0"`
-	StartDate   *string `json:"start_date,omitempty"`
-	EndDate     *string `json:"end_date,omitempty"`
+	ServiceName string `json:"service_name" validate:"required" example:"Netflix"`
+	Price       *int   `json:"price" validate:"required,min=0" example:"600"`
+	// PriceEffectiveFrom is the first month (MM-YYYY) the new price applies to.
+	// Without it a changed price applies from the current month.
+	PriceEffectiveFrom *string `json:"price_effective_from,omitempty" example:"03-2025"`
+	// ResetPriceHistory applies the price to the whole lifetime of the subscription
+	// and drops its price history.
+	ResetPriceHistory bool    `json:"reset_price_history,omitempty" example:"false"`
+	StartDate         string  `json:"start_date" validate:"required" example:"01-2024"`
+	EndDate           *string `json:"end_date,omitempty" example:"12-2024"`
 }
 
 func validateUpdateSubscriptionRequest(req UpdateSubscriptionRequest) error {
 	validate := validator.New()
-    if req.ServiceName != nil {
-        trimmed := strings.TrimSpace(*req.ServiceName)
-        if trimmed == "" {
-            req.ServiceName = nil
-        } else {
-            req.ServiceName = &trimmed
-        }
-    }
-	if err := validate.Struct(req); err != nil {
-		return err
+
+	req.ServiceName = strings.TrimSpace(req.ServiceName)
+	if req.ServiceName == "" {
+		return fmt.Errorf("service_name is required")
+	}
+
+	return validate.Struct(req)
+}
+
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseIfMatch returns the subscription version required by the If-Match header.
+// A missing header or "*" accepts any version. ok is false when the header can
+// never match, e.g. for weak or malformed entity tags.
+func parseIfMatch(r *http.Request) (version *int, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, false
+	}
+
+	v, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return nil, false
 	}
 
-	if req.ServiceName == nil && req.Price == nil && req.StartDate == nil && req.EndDate == nil {
-		return fmt.Errorf("at least one field must be provided")
+	return &v, true
+}
+
+// parseIncludeDeleted reads the include_deleted query parameter, false by default.
+func parseIncludeDeleted(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("include_deleted")
+	if value == "" {
+		return false, nil
 	}
+	return strconv.ParseBool(value)
+}
 
-	return nil
+func formatDeletedAt(deletedAt *time.Time) *string {
+	if deletedAt == nil {
+		return nil
+	}
+	formatted := deletedAt.UTC().Format(time.RFC3339)
+	return &formatted
 }
 
 type GetSubscriptionResponse struct {
-	ID          int64   `json:"id"`
-	ServiceName string  `json:"service_name"`
-	Price       int     `json:"price"`
-	UserID      string  `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	StartDate   string  `json:"start_date" example:"01-2024"`
-	EndDate     *string `json:"end_date,omitempty" example:"12-2024"`
+	ID          int64             `json:"id"`
+	ServiceName string            `json:"service_name"`
+	Price       int               `json:"price"`
+	UserID      string            `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	StartDate   string            `json:"start_date" example:"01-2024"`
+	EndDate     *string           `json:"end_date,omitempty" example:"12-2024"`
+	DeletedAt   *string           `json:"deleted_at,omitempty" example:"2025-01-15T10:00:00Z"`
+	Prices      []PricePeriodItem `json:"prices,omitempty"`
+}
+
+type PricePeriodItem struct {
+	EffectiveFrom string `json:"effective_from" example:"03-2025"`
+	Price         int    `json:"price"`
 }
 
 type ListSubscriptionsItem struct {
@@ -108,13 +168,17 @@ type ListSubscriptionsItem struct {
 	UserID      string  `json:"user_id"`
 	StartDate   string  `json:"start_date"`
 	EndDate     *string `json:"end_date,omitempty"`
+	DeletedAt   *string `json:"deleted_at,omitempty"`
 }
 
 type ListSubscriptionsResponse struct {
 	Subscriptions []ListSubscriptionsItem `json:"subscriptions"`
-	Total         int                     `json:"total"`
-	Limit         int                     `json:"limit"`
-	Offset        int                     `json:"offset"`
+	// Total is omitted when the client passes include_total=false.
+	Total  *int `json:"total,omitempty"`
+	Limit  int  `json:"limit"`
+	Offset int  `json:"offset"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiaWQiLCJpZCI6NDJ9"`
 }
 
 // @Summary      Create subscription
@@ -133,6 +197,10 @@ func SaveSubscription(subscriptionService SubscriptionService, log *slog.Logger)
 	log = log.With(slog.String("op", op))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		reqLog := log.With(
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -183,11 +251,14 @@ func SaveSubscription(subscriptionService SubscriptionService, log *slog.Logger)
 }
 
 // @Summary      Get subscription
+// @Description  Get a subscription. The ETag header holds the current version of the subscription, pass it in If-Match to update or delete it safely.
 // @Tags         subscriptions
 // @Produce      json
-// @Param        id   path      int  true  "Subscription ID"
+// @Param        id               path      int   true   "Subscription ID"
+// @Param        include_deleted  query     bool  false  "Return the subscription even if it is deleted"  default(false)
 // @Success      200  {object}  GetSubscriptionResponse
-// @Failure      400  {object}  ErrorResponse  "Invalid subscription ID"
+// @Header       200  {string}  ETag  "Subscription version"
+// @Failure      400  {object}  ErrorResponse  "Invalid subscription ID or include_deleted"
 // @Failure      404  {object}  ErrorResponse  "Subscription not found"
 // @Failure      500  {object}  ErrorResponse  "Internal server error"
 // @Router       /subscriptions/{id} [get]
@@ -196,6 +267,10 @@ func GetSubscription(subscriptionService SubscriptionService, log *slog.Logger)
 	log = log.With(slog.String("op", op))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		reqLog := log.With(
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -207,10 +282,16 @@ func GetSubscription(subscriptionService SubscriptionService, log *slog.Logger)
 			return
 		}
 
+		includeDeleted, err := parseIncludeDeleted(r)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidIncludeDeleted)
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
-		subscription, err := subscriptionService.GetSubscription(ctx, id)
+		subscription, err := subscriptionService.GetSubscription(ctx, id, includeDeleted)
 		if err != nil {
 			if errors.Is(err, repository.ErrSubscriptionNotFound) {
 				response.WriteError(w, http.StatusNotFound, ErrSubscriptionNotFound)
@@ -227,36 +308,51 @@ func GetSubscription(subscriptionService SubscriptionService, log *slog.Logger)
 			Price:       subscription.Price,
 			UserID:      subscription.UserID.String(),
 			StartDate:   subscription.StartDate.Format("01-2006"),
+			DeletedAt:   formatDeletedAt(subscription.DeletedAt),
+		}
+		for _, period := range subscription.Prices {
+			subscriptionResponse.Prices = append(subscriptionResponse.Prices, PricePeriodItem{
+				EffectiveFrom: period.EffectiveFrom.Format("01-2006"),
+				Price:         period.PriceRub,
+			})
 		}
 		if subscription.EndDate != nil {
 			endDate := subscription.EndDate.Format("01-2006")
 			subscriptionResponse.EndDate = &endDate
 		}
 
+		w.Header().Set("ETag", formatETag(subscription.Version))
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(subscriptionResponse)
 	}
 }
 
-// @Summary      Update subscription
-// @Description  Update subscription fields (partial update). Date format: MM-YYYY (e.g., "12-2024"). Can change service_name, price, start_date, and end_date.
+// @Summary      Replace subscription
+// @Description  Replace all fields of the subscription: service_name, price and start_date are required, a missing end_date makes the subscription open-ended. Date format: MM-YYYY (e.g., "12-2024"). The price history is kept when the price does not change. user_id cannot be changed: a body with user_id or any other unknown field is rejected. Use PATCH for partial updates.
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        id     path      int                       true  "Subscription ID"
-// @Param        input  body      UpdateSubscriptionRequest  true  "Update payload"
-// @Success      200    {object}  map[string]string          "Successfully updated"
-// @Failure      400    {object}  ErrorResponse              "Invalid request body or validation error"
-// @Failure      404    {object}  ErrorResponse              "Subscription not found"
-// @Failure      409    {object}  ErrorResponse              "Conflict - duplicate subscription (user_id + service_id + start_date)"
-// @Failure      500    {object}  ErrorResponse              "Internal server error"
+// @Param        id        path      int                       true   "Subscription ID"
+// @Param        If-Match  header    string                    false  "ETag from GET /subscriptions/{id}"
+// @Param        input     body      UpdateSubscriptionRequest  true   "New state of the subscription"
+// @Success      200       {object}  map[string]string          "Successfully updated"
+// @Header       200       {string}  ETag                       "New subscription version"
+// @Failure      400       {object}  ErrorResponse              "Invalid request body or validation error"
+// @Failure      404       {object}  ErrorResponse              "Subscription not found"
+// @Failure      409       {object}  ErrorResponse              "Conflict - duplicate subscription (user_id + service_id + start_date)"
+// @Failure      412       {object}  ErrorResponse              "Subscription was modified since the ETag was issued"
+// @Failure      500       {object}  ErrorResponse              "Internal server error"
 // @Router       /subscriptions/{id} [put]
 func UpdateSubscription(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
 	const op = "handlers.api.subscription.UpdateSubscription"
 	log = log.With(slog.String("op", op))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		reqLog := log.With(
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -268,9 +364,22 @@ func UpdateSubscription(subscriptionService SubscriptionService, log *slog.Logge
 			return
 		}
 
+		version, ok := parseIfMatch(r)
+		if !ok {
+			response.WriteError(w, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+
+		// Unknown fields are rejected, so a user_id in the body is not silently dropped.
 		var req UpdateSubscriptionRequest
-		if err := render.DecodeJSON(r.Body, &req); err != nil {
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
 			reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+			if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments+": field "+field+" cannot be changed")
+				return
+			}
 			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
 			return
 		}
@@ -280,28 +389,27 @@ func UpdateSubscription(subscriptionService SubscriptionService, log *slog.Logge
 			return
 		}
 
+		serviceName := strings.TrimSpace(req.ServiceName)
+		patch := serv.SubscriptionPatch{
+			ServiceName:        &serviceName,
+			Price:              req.Price,
+			PriceEffectiveFrom: req.PriceEffectiveFrom,
+			ResetPriceHistory:  req.ResetPriceHistory,
+			StartDate:          &req.StartDate,
+			EndDate:            req.EndDate,
+			ClearEndDate:       req.EndDate == nil,
+		}
+
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
-		err = subscriptionService.UpdateSubscription(ctx, id, req.ServiceName, req.Price, req.StartDate, req.EndDate)
+		newVersion, err := subscriptionService.UpdateSubscription(ctx, id, patch, version)
 		if err != nil {
-			if errors.Is(err, serv.ErrValidation) {
-				response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
-				return
-			}
-			if errors.Is(err, repository.ErrSubscriptionNotFound) {
-				response.WriteError(w, http.StatusNotFound, ErrSubscriptionNotFound)
-				return
-			}
-		if errors.Is(err, repository.ErrSubscriptionAlreadyExists) {
-			response.WriteError(w, http.StatusConflict, ErrSubscriptionExists)
-			return
-		}
-			reqLog.Error("update subscription failed", slog.String("err", err.Error()))
-			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			writeUpdateError(w, reqLog, err)
 			return
 		}
 
+		w.Header().Set("ETag", formatETag(newVersion))
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"status": "ok",
@@ -309,12 +417,37 @@ func UpdateSubscription(subscriptionService SubscriptionService, log *slog.Logge
 	}
 }
 
+// writeUpdateError maps the errors of SubscriptionService.UpdateSubscription, shared
+// by PUT and PATCH.
+func writeUpdateError(w http.ResponseWriter, reqLog *slog.Logger, err error) {
+	if errors.Is(err, serv.ErrValidation) {
+		response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+		return
+	}
+	if errors.Is(err, repository.ErrSubscriptionNotFound) {
+		response.WriteError(w, http.StatusNotFound, ErrSubscriptionNotFound)
+		return
+	}
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		response.WriteError(w, http.StatusPreconditionFailed, ErrPreconditionFailed)
+		return
+	}
+	if errors.Is(err, repository.ErrSubscriptionAlreadyExists) {
+		response.WriteError(w, http.StatusConflict, ErrSubscriptionExists)
+		return
+	}
+	reqLog.Error("update subscription failed", slog.String("err", err.Error()))
+	response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+}
+
 // @Summary      Delete subscription
 // @Tags         subscriptions
-// @Param        id   path  int  true  "Subscription ID"
+// @Param        id        path    int     true   "Subscription ID"
+// @Param        If-Match  header  string  false  "ETag from GET /subscriptions/{id}"
 // @Success      204  {string}  string  "No content"
 // @Failure      400  {object}  ErrorResponse  "Invalid subscription ID"
 // @Failure      404  {object}  ErrorResponse  "Subscription not found"
+// @Failure      412  {object}  ErrorResponse  "Subscription was modified since the ETag was issued"
 // @Failure      500  {object}  ErrorResponse  "Internal server error"
 // @Router       /subscriptions/{id} [delete]
 func DeleteSubscription(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
@@ -322,6 +455,10 @@ func DeleteSubscription(subscriptionService SubscriptionService, log *slog.Logge
 	log = log.With(slog.String("op", op))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		reqLog := log.With(
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -333,15 +470,25 @@ func DeleteSubscription(subscriptionService SubscriptionService, log *slog.Logge
 			return
 		}
 
+		version, ok := parseIfMatch(r)
+		if !ok {
+			response.WriteError(w, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
-		err = subscriptionService.DeleteSubscription(ctx, id)
+		err = subscriptionService.DeleteSubscription(ctx, id, version)
 		if err != nil {
 			if errors.Is(err, repository.ErrSubscriptionNotFound) {
 				response.WriteError(w, http.StatusNotFound, ErrSubscriptionNotFound)
 				return
 			}
+			if errors.Is(err, repository.ErrVersionMismatch) {
+				response.WriteError(w, http.StatusPreconditionFailed, ErrPreconditionFailed)
+				return
+			}
 			reqLog.Error("delete subscription failed", slog.String("err", err.Error()))
 			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
 			return
@@ -351,82 +498,154 @@ func DeleteSubscription(subscriptionService SubscriptionService, log *slog.Logge
 	}
 }
 
+// @Summary      Restore subscription
+// @Description  Restore a deleted subscription
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path      int  true  "Subscription ID"
+// @Success      200  {object}  map[string]string  "Successfully restored"
+// @Failure      400  {object}  ErrorResponse  "Invalid subscription ID"
+// @Failure      404  {object}  ErrorResponse  "Subscription not found"
+// @Failure      409  {object}  ErrorResponse  "Subscription is not deleted or an active duplicate exists"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /subscriptions/{id}/restore [post]
+func RestoreSubscription(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.subscription.RestoreSubscription"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidSubscriptionID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		err = subscriptionService.RestoreSubscription(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrSubscriptionNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrSubscriptionNotFound)
+				return
+			}
+			if errors.Is(err, repository.ErrSubscriptionNotDeleted) {
+				response.WriteError(w, http.StatusConflict, ErrSubscriptionNotDeleted)
+				return
+			}
+			if errors.Is(err, repository.ErrSubscriptionAlreadyExists) {
+				response.WriteError(w, http.StatusConflict, ErrSubscriptionExists)
+				return
+			}
+			reqLog.Error("restore subscription failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+		})
+	}
+}
+
 // @Summary      List subscriptions
 // @Tags         subscriptions
 // @Produce      json
-// @Param        limit         query     int     false  "limit"   minimum(1)  default(10)
-// @Param        offset        query     int     false  "offset"  minimum(0)  default(0)
-// @Param        user_id       query     string  false  "user uuid"
-// @Param        service_name  query     string  false  "service name"
-// @Success      200           {object}  ListSubscriptionsResponse  "List of subscriptions with pagination"
-// @Failure      400           {object}  ErrorResponse           "Invalid user_id format"
-// @Failure      500           {object}  ErrorResponse           "Internal server error"
+// @Description  Supports offset and cursor pagination. A cursor is only valid with the sort it was issued for.
+// @Param        limit            query     int       false  "limit"   minimum(1)  maximum(1000)  default(10)
+// @Param        offset           query     int       false  "offset"  minimum(0)  default(0)
+// @Param        user_id          query     []string  false  "User uuids, repeated or comma-separated"  collectionFormat(multi)
+// @Param        service_name     query     []string  false  "Service names, repeated"  collectionFormat(multi)
+// @Param        price_min        query     int       false  "Minimal price"  minimum(0)
+// @Param        price_max        query     int       false  "Maximal price"  minimum(0)
+// @Param        active_at        query     string    false  "Only subscriptions active in the month (MM-YYYY)"  example(03-2024)
+// @Param        start_date_from  query     string    false  "Earliest start_date (MM-YYYY)"  example(01-2024)
+// @Param        start_date_to    query     string    false  "Latest start_date (MM-YYYY)"    example(12-2024)
+// @Param        end_date_from    query     string    false  "Earliest end_date (MM-YYYY), excludes subscriptions without end_date"  example(01-2024)
+// @Param        end_date_to      query     string    false  "Latest end_date (MM-YYYY), excludes subscriptions without end_date"    example(12-2024)
+// @Param        has_end_date     query     bool      false  "Only subscriptions with (true) or without (false) end_date"
+// @Param        sort             query     string    false  "Sort key, prefixed with - for descending order"  Enums(id, -id, price, -price, start_date, -start_date, end_date, -end_date, service_name, -service_name)  default(id)
+// @Param        include_deleted  query     bool      false  "Also list deleted subscriptions"  default(false)
+// @Param        cursor           query     string    false  "next_cursor of the previous page, cannot be combined with offset"
+// @Param        include_total    query     bool      false  "Count all matching subscriptions"  default(true)
+// @Success      200              {object}  ListSubscriptionsResponse  "List of subscriptions with pagination"
+// @Failure      400              {object}  ErrorResponse              "Invalid pagination, filter, sort or cursor"
+// @Failure      500              {object}  ErrorResponse              "Internal server error"
 // @Router       /subscriptions [get]
 func ListSubscriptions(subscriptionService SubscriptionService, log *slog.Logger) http.HandlerFunc {
 	const op = "handlers.api.subscription.ListSubscriptions"
 	log = log.With(slog.String("op", op))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		reqLog := log.With(
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		limitStr := r.URL.Query().Get("limit")
-		offsetStr := r.URL.Query().Get("offset")
-		userIDStr := r.URL.Query().Get("user_id")
-		serviceName := r.URL.Query().Get("service_name")
-
-		limit := 10
-		offset := 0
+		limit, offset, err := parsePagination(r.URL.Query())
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
-		if limitStr != "" {
-			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-				limit = l
-			}
+		params, err := parseSubscriptionFilters(r)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
 		}
+		params.Limit = limit
+		params.Offset = offset
 
-		if offsetStr != "" {
-			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-				offset = o
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			if offset > 0 {
+				response.WriteError(w, http.StatusBadRequest, ErrCursorWithOffset)
+				return
+			}
+			if params.After, err = repository.DecodeListCursor(cursor, params.SortBy, params.SortDesc); err != nil {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidCursor)
+				return
 			}
 		}
 
-		var userID *uuid.UUID
-		if userIDStr != "" {
-			id, err := uuid.Parse(userIDStr)
+		if includeTotal := r.URL.Query().Get("include_total"); includeTotal != "" {
+			value, err := strconv.ParseBool(includeTotal)
 			if err != nil {
-				response.WriteError(w, http.StatusBadRequest, ErrInvalidUserIDFormat)
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidIncludeTotal)
 				return
 			}
-			userID = &id
-		}
-
-		var serviceNamePtr *string
-		if serviceName != "" {
-			serviceNamePtr = &serviceName
+			params.SkipTotal = !value
 		}
 
-		subscriptions, total, err := subscriptionService.ListSubscriptions(r.Context(), repository.ListSubscriptionsParams{
-			Limit:       limit,
-			Offset:      offset,
-			UserID:      userID,
-			ServiceName: serviceNamePtr,
-		})
-
+		page, err := subscriptionService.ListSubscriptions(r.Context(), params)
 		if err != nil {
 			reqLog.Error("list subscriptions failed", slog.String("err", err.Error()))
 			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
 			return
 		}
 
-		items := make([]ListSubscriptionsItem, 0, len(subscriptions))
-		for _, s := range subscriptions {
+		items := make([]ListSubscriptionsItem, 0, len(page.Subscriptions))
+		for _, s := range page.Subscriptions {
 			item := ListSubscriptionsItem{
 				ID:          s.ID,
 				ServiceName: s.ServiceName,
 				Price:       s.Price,
 				UserID:      s.UserID.String(),
 				StartDate:   s.StartDate.Format("01-2006"),
+				DeletedAt:   formatDeletedAt(s.DeletedAt),
 			}
 			if s.EndDate != nil {
 				ed := s.EndDate.Format("01-2006")
@@ -437,10 +656,13 @@ func ListSubscriptions(subscriptionService SubscriptionService, log *slog.Logger
 
 		result := ListSubscriptionsResponse{
 			Subscriptions: items,
-			Total:         total,
+			Total:         page.Total,
 			Limit:         limit,
 			Offset:        offset,
 		}
+		if page.NextCursor != nil {
+			result.NextCursor = page.NextCursor.Encode()
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -454,6 +676,9 @@ func GetSubscriptionsRoutes(subscriptionService SubscriptionService, log *slog.L
 	r.Get("/", ListSubscriptions(subscriptionService, log))
 	r.Get("/{id}", GetSubscription(subscriptionService, log))
 	r.Put("/{id}", UpdateSubscription(subscriptionService, log))
+	r.Patch("/{id}", PatchSubscription(subscriptionService, log))
 	r.Delete("/{id}", DeleteSubscription(subscriptionService, log))
+	r.Post("/{id}/restore", RestoreSubscription(subscriptionService, log))
+	r.Get("/{id}/history", GetSubscriptionHistory(subscriptionService, log))
 	return r
 }
@@ -11,6 +11,7 @@ package handlers
 
 import (
 	repository "EffectiveMobile/internal/repository"
+	service "EffectiveMobile/internal/service"
 	context "context"
 	reflect "reflect"
 
@@ -57,43 +58,86 @@ func (mr *MockSubscriptionServiceMockRecorder) CreateSubscription(ctx, serviceNa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).CreateSubscription), ctx, serviceName, price, userID, startDate, endDate)
 }
 
+// CreateSubscriptions mocks base method.
+func (m *MockSubscriptionService) CreateSubscriptions(ctx context.Context, items []service.CreateSubscriptionInput, atomic bool) ([]service.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscriptions", ctx, items, atomic)
+	ret0, _ := ret[0].([]service.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscriptions indicates an expected call of CreateSubscriptions.
+func (mr *MockSubscriptionServiceMockRecorder) CreateSubscriptions(ctx, items, atomic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscriptions", reflect.TypeOf((*MockSubscriptionService)(nil).CreateSubscriptions), ctx, items, atomic)
+}
+
 // DeleteSubscription mocks base method.
-func (m *MockSubscriptionService) DeleteSubscription(ctx context.Context, id int64) error {
+func (m *MockSubscriptionService) DeleteSubscription(ctx context.Context, id int64, version *int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteSubscription indicates an expected call of DeleteSubscription.
-func (mr *MockSubscriptionServiceMockRecorder) DeleteSubscription(ctx, id any) *gomock.Call {
+func (mr *MockSubscriptionServiceMockRecorder) DeleteSubscription(ctx, id, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).DeleteSubscription), ctx, id, version)
+}
+
+// ExportSubscriptions mocks base method.
+func (m *MockSubscriptionService) ExportSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportSubscriptions", ctx, params, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportSubscriptions indicates an expected call of ExportSubscriptions.
+func (mr *MockSubscriptionServiceMockRecorder) ExportSubscriptions(ctx, params, fn any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).DeleteSubscription), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportSubscriptions", reflect.TypeOf((*MockSubscriptionService)(nil).ExportSubscriptions), ctx, params, fn)
 }
 
 // GetSubscription mocks base method.
-func (m *MockSubscriptionService) GetSubscription(ctx context.Context, id int64) (*repository.Subscription, error) {
+func (m *MockSubscriptionService) GetSubscription(ctx context.Context, id int64, includeDeleted bool) (*repository.Subscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSubscription", ctx, id)
+	ret := m.ctrl.Call(m, "GetSubscription", ctx, id, includeDeleted)
 	ret0, _ := ret[0].(*repository.Subscription)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSubscription indicates an expected call of GetSubscription.
-func (mr *MockSubscriptionServiceMockRecorder) GetSubscription(ctx, id any) *gomock.Call {
+func (mr *MockSubscriptionServiceMockRecorder) GetSubscription(ctx, id, includeDeleted any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).GetSubscription), ctx, id, includeDeleted)
+}
+
+// GetSubscriptionHistory mocks base method.
+func (m *MockSubscriptionService) GetSubscriptionHistory(ctx context.Context, id int64) ([]repository.SubscriptionHistoryRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionHistory", ctx, id)
+	ret0, _ := ret[0].([]repository.SubscriptionHistoryRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionHistory indicates an expected call of GetSubscriptionHistory.
+func (mr *MockSubscriptionServiceMockRecorder) GetSubscriptionHistory(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).GetSubscription), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionHistory", reflect.TypeOf((*MockSubscriptionService)(nil).GetSubscriptionHistory), ctx, id)
 }
 
 // ListSubscriptions mocks base method.
-func (m *MockSubscriptionService) ListSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams) ([]repository.Subscription, int, error) {
+func (m *MockSubscriptionService) ListSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams) (*repository.SubscriptionPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListSubscriptions", ctx, params)
-	ret0, _ := ret[0].([]repository.Subscription)
-	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].(*repository.SubscriptionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ListSubscriptions indicates an expected call of ListSubscriptions.
@@ -102,16 +146,31 @@ func (mr *MockSubscriptionServiceMockRecorder) ListSubscriptions(ctx, params any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockSubscriptionService)(nil).ListSubscriptions), ctx, params)
 }
 
-// UpdateSubscription mocks base method.
-func (m *MockSubscriptionService) UpdateSubscription(ctx context.Context, id int64, serviceName *string, price *int, startDate, endDate *string) error {
+// RestoreSubscription mocks base method.
+func (m *MockSubscriptionService) RestoreSubscription(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, id, serviceName, price, startDate, endDate)
+	ret := m.ctrl.Call(m, "RestoreSubscription", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// RestoreSubscription indicates an expected call of RestoreSubscription.
+func (mr *MockSubscriptionServiceMockRecorder) RestoreSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).RestoreSubscription), ctx, id)
+}
+
+// UpdateSubscription mocks base method.
+func (m *MockSubscriptionService) UpdateSubscription(ctx context.Context, id int64, patch service.SubscriptionPatch, version *int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, id, patch, version)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateSubscription indicates an expected call of UpdateSubscription.
-func (mr *MockSubscriptionServiceMockRecorder) UpdateSubscription(ctx, id, serviceName, price, startDate, endDate any) *gomock.Call {
+func (mr *MockSubscriptionServiceMockRecorder) UpdateSubscription(ctx, id, patch, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).UpdateSubscription), ctx, id, serviceName, price, startDate, endDate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).UpdateSubscription), ctx, id, patch, version)
 }
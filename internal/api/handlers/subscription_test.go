@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -138,7 +140,7 @@ func (s *SubscriptionHandlersSuite) TestGetSubscription_Success() {
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		GetSubscription(gomock.Any(), subscriptionID).
+		GetSubscription(gomock.Any(), subscriptionID, false).
 		Return(expectedSubscription, nil)
 
 	router.ServeHTTP(w, req)
@@ -153,6 +155,83 @@ func (s *SubscriptionHandlersSuite) TestGetSubscription_Success() {
 	s.Equal(expectedSubscription.Price, response.Price)
 }
 
+func (s *SubscriptionHandlersSuite) TestGetSubscription_ETag() {
+	subscriptionID := int64(123)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions/{id}", GetSubscription(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("GET", "/subscriptions/123", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		GetSubscription(gomock.Any(), subscriptionID, false).
+		Return(&repository.Subscription{ID: subscriptionID, StartDate: time.Now(), Version: 3}, nil)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(`"3"`, w.Header().Get("ETag"))
+}
+
+func (s *SubscriptionHandlersSuite) TestGetSubscription_IncludeDeleted() {
+	subscriptionID := int64(123)
+	deletedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions/{id}", GetSubscription(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("GET", "/subscriptions/123?include_deleted=true", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		GetSubscription(gomock.Any(), subscriptionID, true).
+		Return(&repository.Subscription{ID: subscriptionID, StartDate: deletedAt, DeletedAt: &deletedAt}, nil)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response GetSubscriptionResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Require().NotNil(response.DeletedAt)
+	s.Equal("2025-03-10T12:00:00Z", *response.DeletedAt)
+}
+
+func (s *SubscriptionHandlersSuite) TestGetSubscription_Prices() {
+	subscriptionID := int64(123)
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions/{id}", GetSubscription(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("GET", "/subscriptions/123", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		GetSubscription(gomock.Any(), subscriptionID, false).
+		Return(&repository.Subscription{
+			ID:        subscriptionID,
+			Price:     600,
+			StartDate: startDate,
+			Prices: []repository.PricePeriod{
+				{EffectiveFrom: startDate, PriceRub: 500},
+				{EffectiveFrom: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), PriceRub: 600},
+			},
+		}, nil)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response GetSubscriptionResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal([]PricePeriodItem{
+		{EffectiveFrom: "01-2024", Price: 500},
+		{EffectiveFrom: "03-2025", Price: 600},
+	}, response.Prices)
+}
+
 func (s *SubscriptionHandlersSuite) TestGetSubscription_NotFound() {
 	subscriptionID := int64(123)
 
@@ -163,7 +242,7 @@ func (s *SubscriptionHandlersSuite) TestGetSubscription_NotFound() {
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		GetSubscription(gomock.Any(), subscriptionID).
+		GetSubscription(gomock.Any(), subscriptionID, false).
 		Return(nil, repository.ErrSubscriptionNotFound)
 
 	router.ServeHTTP(w, req)
@@ -171,16 +250,20 @@ func (s *SubscriptionHandlersSuite) TestGetSubscription_NotFound() {
 	s.Equal(http.StatusNotFound, w.Code)
 }
 
+const replaceBody = `{"service_name":"Netflix","price":600,"start_date":"01-2024"}`
+
 func (s *SubscriptionHandlersSuite) TestUpdateSubscription_Success() {
 	subscriptionID := int64(123)
+	serviceName := "Netflix"
 	price := 600
 	startDate := "02-2024"
 	endDate := "04-2024"
 
 	requestBody := UpdateSubscriptionRequest{
-		Price:     &price,
-		StartDate: &startDate,
-		EndDate:   &endDate,
+		ServiceName: " Netflix ",
+		Price:       &price,
+		StartDate:   startDate,
+		EndDate:     &endDate,
 	}
 
 	jsonBody, err := json.Marshal(requestBody)
@@ -194,14 +277,85 @@ func (s *SubscriptionHandlersSuite) TestUpdateSubscription_Success() {
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		UpdateSubscription(gomock.Any(), subscriptionID, nil, &price, &startDate, &endDate).
-		Return(nil)
+		UpdateSubscription(gomock.Any(), subscriptionID, serv.SubscriptionPatch{
+			ServiceName: &serviceName,
+			Price:       &price,
+			StartDate:   &startDate,
+			EndDate:     &endDate,
+		}, nil).
+		Return(2, nil)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_WithoutEndDate() {
+	subscriptionID := int64(123)
+	serviceName := "Netflix"
+	price := 600
+	startDate := "01-2024"
+
+	router := chi.NewRouter()
+	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(replaceBody)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		UpdateSubscription(gomock.Any(), subscriptionID, serv.SubscriptionPatch{
+			ServiceName:  &serviceName,
+			Price:        &price,
+			StartDate:    &startDate,
+			ClearEndDate: true,
+		}, nil).
+		Return(2, nil)
 
 	router.ServeHTTP(w, req)
 
 	s.Equal(http.StatusOK, w.Code)
 }
 
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_MissingFields() {
+	router := chi.NewRouter()
+	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
+
+	for _, body := range []string{
+		`{"price":600}`,
+		`{"service_name":"Netflix","start_date":"01-2024"}`,
+		`{"service_name":" ","price":600,"start_date":"01-2024"}`,
+		`{"service_name":"Netflix","price":600}`,
+	} {
+		req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_UserIDRejected() {
+	router := chi.NewRouter()
+	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
+
+	for _, body := range []string{
+		`{"service_name":"Netflix","price":600,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2024"}`,
+		`{"service_name":"Netflix","price":600,"start_date":"01-2024","unknown":1}`,
+	} {
+		req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, body)
+		s.Contains(w.Body.String(), "cannot be changed", body)
+	}
+}
+
 func (s *SubscriptionHandlersSuite) TestUpdateSubscription_InvalidJSON() {
 	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
@@ -214,85 +368,143 @@ func (s *SubscriptionHandlersSuite) TestUpdateSubscription_InvalidJSON() {
 
 func (s *SubscriptionHandlersSuite) TestUpdateSubscription_NotFound() {
 	subscriptionID := int64(123)
-	price := 600
-
-	requestBody := UpdateSubscriptionRequest{
-		Price: &price,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	s.Require().NoError(err)
 
 	router := chi.NewRouter()
 	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
 
-	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader(jsonBody))
+	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(replaceBody)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		UpdateSubscription(gomock.Any(), subscriptionID, nil, &price, nil, nil).
-		Return(repository.ErrSubscriptionNotFound)
+		UpdateSubscription(gomock.Any(), subscriptionID, gomock.Any(), nil).
+		Return(0, repository.ErrSubscriptionNotFound)
 
 	router.ServeHTTP(w, req)
 
 	s.Equal(http.StatusNotFound, w.Code)
 }
 
-func (s *SubscriptionHandlersSuite) TestUpdateSubscription_WithServiceName() {
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_Conflict() {
 	subscriptionID := int64(123)
-	serviceName := "Spotify"
-	price := 700
 
-	requestBody := UpdateSubscriptionRequest{
-		ServiceName: &serviceName,
-		Price:       &price,
-	}
+	router := chi.NewRouter()
+	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
 
-	jsonBody, err := json.Marshal(requestBody)
-	s.Require().NoError(err)
+	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(replaceBody)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		UpdateSubscription(gomock.Any(), subscriptionID, gomock.Any(), nil).
+		Return(0, repository.ErrSubscriptionAlreadyExists)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_IfMatch() {
+	subscriptionID := int64(123)
+	version := 3
 
 	router := chi.NewRouter()
 	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
 
-	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader(jsonBody))
+	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(replaceBody)))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"3"`)
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		UpdateSubscription(gomock.Any(), subscriptionID, &serviceName, &price, nil, nil).
-		Return(nil)
+		UpdateSubscription(gomock.Any(), subscriptionID, gomock.Any(), &version).
+		Return(4, nil)
 
 	router.ServeHTTP(w, req)
 
 	s.Equal(http.StatusOK, w.Code)
+	s.Equal(`"4"`, w.Header().Get("ETag"))
 }
 
-func (s *SubscriptionHandlersSuite) TestUpdateSubscription_Conflict() {
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_PriceEffectiveFrom() {
 	subscriptionID := int64(123)
-	startDate := "02-2024"
+	effectiveFrom := "03-2025"
 
-	requestBody := UpdateSubscriptionRequest{
-		StartDate: &startDate,
-	}
+	router := chi.NewRouter()
+	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
 
-	jsonBody, err := json.Marshal(requestBody)
-	s.Require().NoError(err)
+	body := `{"service_name":"Netflix","price":600,"price_effective_from":"03-2025","start_date":"01-2024"}`
+	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		UpdateSubscription(gomock.Any(), subscriptionID, gomock.Any(), nil).
+		DoAndReturn(func(_ any, _ int64, patch serv.SubscriptionPatch, _ *int) (int, error) {
+			s.Equal(&effectiveFrom, patch.PriceEffectiveFrom)
+			return 2, nil
+		})
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_VersionMismatch() {
+	subscriptionID := int64(123)
+	version := 2
 
 	router := chi.NewRouter()
 	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
 
-	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader(jsonBody))
+	req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(replaceBody)))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		UpdateSubscription(gomock.Any(), subscriptionID, nil, nil, &startDate, nil).
-		Return(repository.ErrSubscriptionAlreadyExists)
+		UpdateSubscription(gomock.Any(), subscriptionID, gomock.Any(), &version).
+		Return(0, repository.ErrVersionMismatch)
 
 	router.ServeHTTP(w, req)
 
-	s.Equal(http.StatusConflict, w.Code)
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestUpdateSubscription_MalformedIfMatch() {
+	router := chi.NewRouter()
+	router.Put("/subscriptions/{id}", UpdateSubscription(s.subscriptionService, s.logger))
+
+	for _, header := range []string{`W/"3"`, `3`, `"abc"`} {
+		req := httptest.NewRequest("PUT", "/subscriptions/123", bytes.NewReader([]byte(replaceBody)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", header)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusPreconditionFailed, w.Code, header)
+	}
+}
+
+func (s *SubscriptionHandlersSuite) TestDeleteSubscription_VersionMismatch() {
+	subscriptionID := int64(123)
+	version := 1
+
+	router := chi.NewRouter()
+	router.Delete("/subscriptions/{id}", DeleteSubscription(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("DELETE", "/subscriptions/123", nil)
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		DeleteSubscription(gomock.Any(), subscriptionID, &version).
+		Return(repository.ErrVersionMismatch)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
 }
 
 func (s *SubscriptionHandlersSuite) TestDeleteSubscription_Success() {
@@ -305,7 +517,7 @@ func (s *SubscriptionHandlersSuite) TestDeleteSubscription_Success() {
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		DeleteSubscription(gomock.Any(), subscriptionID).
+		DeleteSubscription(gomock.Any(), subscriptionID, nil).
 		Return(nil)
 
 	router.ServeHTTP(w, req)
@@ -323,7 +535,7 @@ func (s *SubscriptionHandlersSuite) TestDeleteSubscription_NotFound() {
 	w := httptest.NewRecorder()
 
 	s.subscriptionService.EXPECT().
-		DeleteSubscription(gomock.Any(), subscriptionID).
+		DeleteSubscription(gomock.Any(), subscriptionID, nil).
 		Return(repository.ErrSubscriptionNotFound)
 
 	router.ServeHTTP(w, req)
@@ -354,11 +566,11 @@ func (s *SubscriptionHandlersSuite) TestListSubscriptions_Success() {
 
 	s.subscriptionService.EXPECT().
 		ListSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{
-			UserID: &userID,
-			Limit:  limit,
-			Offset: offset,
+			UserIDs: []uuid.UUID{userID},
+			Limit:   limit,
+			Offset:  offset,
 		}).
-		Return(expectedSubscriptions, expectedTotal, nil)
+		Return(&repository.SubscriptionPage{Subscriptions: expectedSubscriptions, Total: &expectedTotal}, nil)
 
 	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
 
@@ -386,6 +598,153 @@ func (s *SubscriptionHandlersSuite) TestListSubscriptions_Success() {
 	s.Equal(expectedTotal, response.Total)
 }
 
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_IncludeDeleted() {
+	deletedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	req := httptest.NewRequest("GET", "/api/v1/subscriptions?include_deleted=true", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		ListSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{
+			Limit:          10,
+			Offset:         0,
+			IncludeDeleted: true,
+		}).
+		Return(&repository.SubscriptionPage{Subscriptions: []repository.Subscription{{ID: 1, StartDate: deletedAt, DeletedAt: &deletedAt}}}, nil)
+
+	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response ListSubscriptionsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Require().Len(response.Subscriptions, 1)
+	s.Require().NotNil(response.Subscriptions[0].DeletedAt)
+	s.Equal("2025-03-10T12:00:00Z", *response.Subscriptions[0].DeletedAt)
+}
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_InvalidIncludeDeleted() {
+	req := httptest.NewRequest("GET", "/api/v1/subscriptions?include_deleted=maybe", nil)
+	w := httptest.NewRecorder()
+
+	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestRestoreSubscription_Success() {
+	subscriptionID := int64(123)
+
+	router := chi.NewRouter()
+	router.Post("/subscriptions/{id}/restore", RestoreSubscription(s.subscriptionService, s.logger))
+
+	req := httptest.NewRequest("POST", "/subscriptions/123/restore", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		RestoreSubscription(gomock.Any(), subscriptionID).
+		Return(nil)
+
+	router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *SubscriptionHandlersSuite) TestRestoreSubscription_Errors() {
+	router := chi.NewRouter()
+	router.Post("/subscriptions/{id}/restore", RestoreSubscription(s.subscriptionService, s.logger))
+
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{repository.ErrSubscriptionNotFound, http.StatusNotFound},
+		{repository.ErrSubscriptionNotDeleted, http.StatusConflict},
+		{repository.ErrSubscriptionAlreadyExists, http.StatusConflict},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", "/subscriptions/123/restore", nil)
+		w := httptest.NewRecorder()
+
+		s.subscriptionService.EXPECT().
+			RestoreSubscription(gomock.Any(), int64(123)).
+			Return(tc.err)
+
+		router.ServeHTTP(w, req)
+
+		s.Equal(tc.code, w.Code, tc.err.Error())
+	}
+}
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_NextCursor() {
+	req := httptest.NewRequest("GET", "/api/v1/subscriptions?limit=1&include_total=false", nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		ListSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{
+			Limit:     1,
+			SkipTotal: true,
+		}).
+		Return(&repository.SubscriptionPage{
+			Subscriptions: []repository.Subscription{{ID: 42}},
+			NextCursor:    &repository.ListCursor{SortBy: "id", ID: 42},
+		}, nil)
+
+	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response map[string]any
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.NotContains(response, "total")
+	s.Require().Contains(response, "next_cursor")
+
+	cursor, err := repository.DecodeListCursor(response["next_cursor"].(string), "", false)
+	s.NoError(err)
+	s.Equal(int64(42), cursor.ID)
+}
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_Cursor() {
+	cursor := repository.ListCursor{SortBy: "id", ID: 42}
+
+	req := httptest.NewRequest("GET", "/api/v1/subscriptions?cursor="+cursor.Encode(), nil)
+	w := httptest.NewRecorder()
+
+	s.subscriptionService.EXPECT().
+		ListSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{
+			Limit: 10,
+			After: &cursor,
+		}).
+		Return(&repository.SubscriptionPage{}, nil)
+
+	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response ListSubscriptionsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Empty(response.NextCursor)
+}
+
+func (s *SubscriptionHandlersSuite) TestListSubscriptions_InvalidCursor() {
+	cursor := repository.ListCursor{SortBy: "id", ID: 42}
+
+	for _, query := range []string{
+		"cursor=not-a-cursor",
+		"cursor=" + cursor.Encode() + "&offset=10",
+		"include_total=sometimes",
+	} {
+		req := httptest.NewRequest("GET", "/api/v1/subscriptions?"+query, nil)
+		w := httptest.NewRecorder()
+
+		ListSubscriptions(s.subscriptionService, s.logger)(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code, query)
+	}
+}
+
 func (s *SubscriptionHandlersSuite) TestListSubscriptions_ServiceError() {
 	userID := uuid.New()
 	limit := 10
@@ -396,11 +755,11 @@ func (s *SubscriptionHandlersSuite) TestListSubscriptions_ServiceError() {
 
 	s.subscriptionService.EXPECT().
 		ListSubscriptions(gomock.Any(), repository.ListSubscriptionsParams{
-			UserID: &userID,
-			Limit:  limit,
-			Offset: offset,
+			UserIDs: []uuid.UUID{userID},
+			Limit:   limit,
+			Offset:  offset,
 		}).
-		Return(nil, 0, repository.ErrSubscriptionNotCreated)
+		Return(nil, repository.ErrSubscriptionNotCreated)
 
 	ListSubscriptions(s.subscriptionService, s.logger)(w, req)
 
@@ -0,0 +1,356 @@
+//go:generate go run go.uber.org/mock/mockgen@latest -destination=webhook_mock.go -source=webhook.go -package=handlers
+
+package handlers
+
+import (
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/api/response"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	ErrInvalidWebhookID       = "invalid webhook id"
+	ErrWebhookNotFound        = "webhook not found"
+	ErrInvalidWebhookEndpoint = "invalid webhook: url must be an absolute http or https url, events must be known event types and secret at least 16 characters long"
+)
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, rawURL, secret string, events []string) (*repository.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, id int64) (*repository.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]repository.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, endpointID int64) ([]repository.WebhookDeliveryLogRecord, error)
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" example:"https://billing.example.com/hooks/subscriptions"`
+	// Secret signs the deliveries; a random one is generated when it is empty.
+	Secret string   `json:"secret,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	Events []string `json:"events,omitempty" example:"subscription.created,subscription.deleted"`
+}
+
+type WebhookItem struct {
+	ID        int64    `json:"id"`
+	URL       string   `json:"url" example:"https://billing.example.com/hooks/subscriptions"`
+	Events    []string `json:"events" example:"subscription.created,subscription.deleted"`
+	CreatedAt string   `json:"created_at" example:"2025-01-15T10:00:00Z"`
+}
+
+type CreateWebhookResponse struct {
+	WebhookItem
+	Secret string `json:"secret" example:"9f86d081884c7d659a2feaa0c55ad015"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookItem `json:"webhooks"`
+}
+
+type WebhookDeliveryItem struct {
+	ID         int64   `json:"id"`
+	DeliveryID int64   `json:"delivery_id"`
+	EventID    string  `json:"event_id" example:"7b4c2a8e-3f0d-4c55-a1f2-0b6f4f1a9d10"`
+	EventType  string  `json:"event_type" example:"subscription.created"`
+	Attempt    int     `json:"attempt" example:"1"`
+	StatusCode *int    `json:"status_code,omitempty" example:"200"`
+	Error      *string `json:"error,omitempty"`
+	DurationMs int64   `json:"duration_ms" example:"35"`
+	CreatedAt  string  `json:"created_at" example:"2025-01-15T10:00:00Z"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryItem `json:"deliveries"`
+}
+
+func parseWebhookID(r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+func webhookItem(endpoint repository.WebhookEndpoint) WebhookItem {
+	events := endpoint.Events
+	if events == nil {
+		events = []string{}
+	}
+	return WebhookItem{
+		ID:        endpoint.ID,
+		URL:       endpoint.URL,
+		Events:    events,
+		CreatedAt: endpoint.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// @Summary      Register webhook
+// @Description  Register an endpoint for subscription events: subscription.created, subscription.updated, subscription.deleted, subscription.restored and subscription.ending_soon. Without events the endpoint receives all of them. Every delivery is a POST of the event JSON signed with the secret: the X-Webhook-Signature header is "sha256=" followed by the hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>". The secret is only returned by this request.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        input  body      CreateWebhookRequest  true  "Webhook payload"
+// @Success      201    {object}  CreateWebhookResponse
+// @Failure      400    {object}  ErrorResponse  "Invalid url, events or secret"
+// @Failure      500    {object}  ErrorResponse  "Internal server error"
+// @Router       /webhooks [post]
+func CreateWebhook(webhookService WebhookService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.webhook.CreateWebhook"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req CreateWebhookRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			reqLog.Error("failed to decode request", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidArguments)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		endpoint, err := webhookService.CreateEndpoint(ctx, req.URL, req.Secret, req.Events)
+		if err != nil {
+			if errors.Is(err, serv.ErrValidation) {
+				response.WriteError(w, http.StatusBadRequest, ErrInvalidWebhookEndpoint)
+				return
+			}
+			reqLog.Error("create webhook failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Location", "/api/v1/webhooks/"+strconv.FormatInt(endpoint.ID, 10))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(CreateWebhookResponse{
+			WebhookItem: webhookItem(*endpoint),
+			Secret:      endpoint.Secret,
+		})
+	}
+}
+
+// @Summary      List webhooks
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {object}  ListWebhooksResponse
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /webhooks [get]
+func ListWebhooks(webhookService WebhookService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.webhook.ListWebhooks"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		endpoints, err := webhookService.ListEndpoints(ctx)
+		if err != nil {
+			reqLog.Error("list webhooks failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		items := make([]WebhookItem, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			items = append(items, webhookItem(endpoint))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(ListWebhooksResponse{Webhooks: items})
+	}
+}
+
+// @Summary      Get webhook
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      int  true  "Webhook ID"
+// @Success      200  {object}  WebhookItem
+// @Failure      400  {object}  ErrorResponse  "Invalid webhook ID"
+// @Failure      404  {object}  ErrorResponse  "Webhook not found"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /webhooks/{id} [get]
+func GetWebhook(webhookService WebhookService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.webhook.GetWebhook"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseWebhookID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidWebhookID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		endpoint, err := webhookService.GetEndpoint(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrWebhookNotFound)
+				return
+			}
+			reqLog.Error("get webhook failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(webhookItem(*endpoint))
+	}
+}
+
+// @Summary      Delete webhook
+// @Description  Delete a webhook together with its pending deliveries and delivery log
+// @Tags         webhooks
+// @Param        id   path      int  true  "Webhook ID"
+// @Success      204  {string}  string         "No content"
+// @Failure      400  {object}  ErrorResponse  "Invalid webhook ID"
+// @Failure      404  {object}  ErrorResponse  "Webhook not found"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /webhooks/{id} [delete]
+func DeleteWebhook(webhookService WebhookService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.webhook.DeleteWebhook"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseWebhookID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidWebhookID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		if err := webhookService.DeleteEndpoint(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrWebhookNotFound)
+				return
+			}
+			reqLog.Error("delete webhook failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// @Summary      List webhook deliveries
+// @Description  The delivery log of a webhook: the latest 100 delivery attempts, newest first, with the response status or the error.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      int  true  "Webhook ID"
+// @Success      200  {object}  ListWebhookDeliveriesResponse
+// @Failure      400  {object}  ErrorResponse  "Invalid webhook ID"
+// @Failure      404  {object}  ErrorResponse  "Webhook not found"
+// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Router       /webhooks/{id}/deliveries [get]
+func ListWebhookDeliveries(webhookService WebhookService, log *slog.Logger) http.HandlerFunc {
+	const op = "handlers.api.webhook.ListWebhookDeliveries"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		reqLog := log.With(
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := parseWebhookID(r)
+		if !ok {
+			response.WriteError(w, http.StatusBadRequest, ErrInvalidWebhookID)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		records, err := webhookService.ListDeliveries(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+				response.WriteError(w, http.StatusNotFound, ErrWebhookNotFound)
+				return
+			}
+			reqLog.Error("list webhook deliveries failed", slog.String("err", err.Error()))
+			response.WriteError(w, http.StatusInternalServerError, ErrInternalServer)
+			return
+		}
+
+		items := make([]WebhookDeliveryItem, 0, len(records))
+		for _, record := range records {
+			items = append(items, WebhookDeliveryItem{
+				ID:         record.ID,
+				DeliveryID: record.OutboxID,
+				EventID:    record.EventID.String(),
+				EventType:  record.EventType,
+				Attempt:    record.Attempt,
+				StatusCode: record.StatusCode,
+				Error:      record.Error,
+				DurationMs: record.Duration.Milliseconds(),
+				CreatedAt:  record.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(ListWebhookDeliveriesResponse{Deliveries: items})
+	}
+}
+
+func GetWebhookRoutes(webhookService WebhookService, log *slog.Logger) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", ListWebhooks(webhookService, log))
+	r.Post("/", CreateWebhook(webhookService, log))
+	r.Get("/{id}", GetWebhook(webhookService, log))
+	r.Delete("/{id}", DeleteWebhook(webhookService, log))
+	r.Get("/{id}/deliveries", ListWebhookDeliveries(webhookService, log))
+	return r
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+	serv "EffectiveMobile/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type WebhookHandlersSuite struct {
+	suite.Suite
+
+	ctrl           *gomock.Controller
+	webhookService *MockWebhookService
+	logger         *slog.Logger
+	router         chi.Router
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	suite.Run(t, &WebhookHandlersSuite{})
+}
+
+func (s *WebhookHandlersSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.webhookService = NewMockWebhookService(s.ctrl)
+
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	s.router = GetWebhookRoutes(s.webhookService, s.logger)
+}
+
+func (s *WebhookHandlersSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *WebhookHandlersSuite) TestCreateWebhook_Success() {
+	events := []string{repository.EventSubscriptionCreated}
+
+	s.webhookService.EXPECT().
+		CreateEndpoint(gomock.Any(), "https://example.com/hooks", "", events).
+		Return(&repository.WebhookEndpoint{
+			ID:        5,
+			URL:       "https://example.com/hooks",
+			Secret:    "generated-secret-value",
+			Events:    events,
+			CreatedAt: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		}, nil)
+
+	body := `{"url":"https://example.com/hooks","events":["subscription.created"]}`
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.Equal("/api/v1/webhooks/5", w.Header().Get("Location"))
+
+	var resp CreateWebhookResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&resp))
+	s.Equal(int64(5), resp.ID)
+	s.Equal("generated-secret-value", resp.Secret)
+	s.Equal(events, resp.Events)
+	s.Equal("2025-01-15T10:00:00Z", resp.CreatedAt)
+}
+
+func (s *WebhookHandlersSuite) TestCreateWebhook_ValidationError() {
+	s.webhookService.EXPECT().
+		CreateEndpoint(gomock.Any(), "ftp://example.com", "", nil).
+		Return(nil, fmt.Errorf("%w: url must be an absolute http or https url", serv.ErrValidation))
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"url":"ftp://example.com"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *WebhookHandlersSuite) TestListWebhooks_HidesSecret() {
+	s.webhookService.EXPECT().
+		ListEndpoints(gomock.Any()).
+		Return([]repository.WebhookEndpoint{{ID: 1, URL: "https://example.com/hooks", Secret: "do-not-return-me"}}, nil)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), "do-not-return-me")
+
+	var resp ListWebhooksResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&resp))
+	s.Require().Len(resp.Webhooks, 1)
+	s.Equal([]string{}, resp.Webhooks[0].Events)
+}
+
+func (s *WebhookHandlersSuite) TestGetWebhook_NotFound() {
+	s.webhookService.EXPECT().
+		GetEndpoint(gomock.Any(), int64(9)).
+		Return(nil, repository.ErrWebhookEndpointNotFound)
+
+	req := httptest.NewRequest("GET", "/9", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *WebhookHandlersSuite) TestDeleteWebhook_InvalidID() {
+	req := httptest.NewRequest("DELETE", "/abc", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *WebhookHandlersSuite) TestListWebhookDeliveries_Success() {
+	statusCode := 500
+	message := "unexpected status 500"
+	eventID := uuid.New()
+
+	s.webhookService.EXPECT().
+		ListDeliveries(gomock.Any(), int64(2)).
+		Return([]repository.WebhookDeliveryLogRecord{{
+			ID:         11,
+			OutboxID:   4,
+			EndpointID: 2,
+			EventID:    eventID,
+			EventType:  repository.EventSubscriptionUpdated,
+			Attempt:    1,
+			StatusCode: &statusCode,
+			Error:      &message,
+			Duration:   35 * time.Millisecond,
+			CreatedAt:  time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		}}, nil)
+
+	req := httptest.NewRequest("GET", "/2/deliveries", nil)
+	w := httptest.NewRecorder()
+
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var resp ListWebhookDeliveriesResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&resp))
+	s.Require().Len(resp.Deliveries, 1)
+	s.Equal(int64(4), resp.Deliveries[0].DeliveryID)
+	s.Equal(eventID.String(), resp.Deliveries[0].EventID)
+	s.Equal(500, *resp.Deliveries[0].StatusCode)
+	s.Equal(int64(35), resp.Deliveries[0].DurationMs)
+}
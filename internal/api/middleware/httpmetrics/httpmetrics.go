@@ -0,0 +1,66 @@
+package httpmetrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"EffectiveMobile/pkg/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// unmatchedRoute labels requests no route matched, so unknown paths do not each get
+// their own series.
+const unmatchedRoute = "unmatched"
+
+// New counts requests and observes their latency per method, chi route pattern and
+// status, and registers both metrics in registry.
+func New(registry *metrics.Registry) func(next http.Handler) http.Handler {
+	requests := metrics.NewCounterVec(
+		"http_requests_total",
+		"Total number of HTTP requests.",
+		"method", "route", "status",
+	)
+	duration := metrics.NewHistogramVec(
+		"http_request_duration_seconds",
+		"HTTP request latency in seconds.",
+		metrics.DefBuckets,
+		"method", "route", "status",
+	)
+	registry.Register(requests)
+	registry.Register(duration)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			t1 := time.Now()
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				labels := []string{r.Method, routePattern(r), strconv.Itoa(status)}
+
+				requests.Inc(labels...)
+				duration.Observe(time.Since(t1).Seconds(), labels...)
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// routePattern returns the pattern of the route that served r. It is only complete
+// once the request went through the router.
+func routePattern(r *http.Request) string {
+	pattern := chi.RouteContext(r.Context()).RoutePattern()
+	if pattern == "" {
+		return unmatchedRoute
+	}
+	return pattern
+}
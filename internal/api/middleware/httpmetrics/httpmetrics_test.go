@@ -0,0 +1,95 @@
+package httpmetrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"EffectiveMobile/pkg/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+type HTTPMetricsSuite struct {
+	suite.Suite
+
+	registry *metrics.Registry
+	router   chi.Router
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	suite.Run(t, &HTTPMetricsSuite{})
+}
+
+func (s *HTTPMetricsSuite) SetupTest() {
+	s.registry = metrics.NewRegistry(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	items := chi.NewRouter()
+	items.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	s.router = chi.NewRouter()
+	s.router.Use(New(s.registry))
+	s.router.Route("/api/v1", func(r chi.Router) {
+		r.Mount("/items", items)
+	})
+}
+
+func (s *HTTPMetricsSuite) serve(method, path string) {
+	s.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+}
+
+// samples returns the values of the samples of the family, keyed by their labels.
+func (s *HTTPMetricsSuite) samples(name string) map[string]float64 {
+	families, err := s.registry.Gather(context.Background())
+	s.Require().NoError(err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, sample := range family.Samples {
+			if sample.Name != name {
+				continue
+			}
+			key := ""
+			for _, label := range sample.Labels {
+				key += label.Name + "=" + label.Value + ";"
+			}
+			values[key] = sample.Value
+		}
+	}
+	return values
+}
+
+func (s *HTTPMetricsSuite) TestRequestsArePerRoutePatternAndStatus() {
+	s.serve(http.MethodGet, "/api/v1/items/1")
+	s.serve(http.MethodGet, "/api/v1/items/2")
+	s.serve(http.MethodGet, "/api/v1/items/0")
+
+	s.Equal(map[string]float64{
+		"method=GET;route=/api/v1/items/{id};status=200;": 2,
+		"method=GET;route=/api/v1/items/{id};status=404;": 1,
+	}, s.samples("http_requests_total"))
+
+	s.Equal(map[string]float64{
+		"method=GET;route=/api/v1/items/{id};status=200;": 2,
+		"method=GET;route=/api/v1/items/{id};status=404;": 1,
+	}, s.samples("http_request_duration_seconds_count"))
+}
+
+func (s *HTTPMetricsSuite) TestUnknownPathsShareOneSeries() {
+	s.serve(http.MethodGet, "/unknown/1")
+	s.serve(http.MethodGet, "/unknown/2")
+
+	s.Equal(map[string]float64{
+		"method=GET;route=unmatched;status=404;": 2,
+	}, s.samples("http_requests_total"))
+}
@@ -0,0 +1,49 @@
+package httptracing
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"EffectiveMobile/pkg/tracing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// New starts a span for every request, continuing the trace of the incoming
+// traceparent header. The span is named after the chi route pattern once the request
+// is served, and requests answered with 5xx are marked as failed.
+func New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := tracing.Start(tracing.Extract(r.Context(), r.Header), "HTTP "+r.Method,
+				slog.String("http.method", r.Method),
+				slog.String("http.target", r.URL.Path),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				if pattern := chi.RouteContext(r.Context()).RoutePattern(); pattern != "" {
+					span.SetName(r.Method + " " + pattern)
+					span.SetAttributes(slog.String("http.route", pattern))
+				}
+				span.SetAttributes(slog.Int("http.status_code", status))
+				if status >= http.StatusInternalServerError {
+					span.SetError(fmt.Sprintf("HTTP %d", status))
+				}
+				span.End()
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,80 @@
+package httptracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"EffectiveMobile/pkg/tracing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/suite"
+)
+
+type HTTPTracingSuite struct {
+	suite.Suite
+
+	exporter *tracing.InMemoryExporter
+	router   chi.Router
+}
+
+func TestHTTPTracing(t *testing.T) {
+	suite.Run(t, &HTTPTracingSuite{})
+}
+
+func (s *HTTPTracingSuite) SetupTest() {
+	s.exporter = tracing.NewInMemoryExporter()
+	tracing.SetTracer(tracing.NewTracer(s.exporter, nil))
+
+	items := chi.NewRouter()
+	items.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "handler")
+		span.End()
+
+		if chi.URLParam(r, "id") == "0" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	s.router = chi.NewRouter()
+	s.router.Use(middleware.RequestID)
+	s.router.Use(New())
+	s.router.Mount("/items", items)
+}
+
+func (s *HTTPTracingSuite) TearDownTest() {
+	tracing.SetTracer(nil)
+}
+
+func (s *HTTPTracingSuite) TestContinuesIncomingTrace() {
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	s.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := s.exporter.Spans()
+	s.Require().Len(spans, 2)
+	handler, server := spans[0], spans[1]
+
+	s.Equal("GET /items/{id}", server.Name)
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", server.TraceID.String())
+	s.Equal("00f067aa0ba902b7", server.ParentSpanID.String())
+	s.Equal("/items/{id}", server.Attributes["http.route"])
+	s.Equal("/items/1", server.Attributes["http.target"])
+	s.Equal(int64(http.StatusOK), server.Attributes["http.status_code"])
+	s.NotEmpty(server.Attributes["request_id"])
+	s.Empty(server.Error)
+
+	s.Equal(server.TraceID, handler.TraceID)
+	s.Equal(server.SpanID, handler.ParentSpanID)
+}
+
+func (s *HTTPTracingSuite) TestServerErrorMarksSpan() {
+	s.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/0", nil))
+
+	spans := s.exporter.Spans()
+	s.Require().Len(spans, 2)
+	s.Equal("HTTP 500", spans[1].Error)
+	s.Equal(tracing.SpanID{}, spans[1].ParentSpanID)
+}
@@ -2,9 +2,14 @@ package api
 
 import (
 	"EffectiveMobile/internal/api/handlers"
+	"EffectiveMobile/internal/api/middleware/httpmetrics"
+	"EffectiveMobile/internal/api/middleware/httptracing"
 	"EffectiveMobile/internal/api/middleware/logger"
+	"EffectiveMobile/internal/health"
 	"EffectiveMobile/internal/repository"
 	"EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/metrics"
+	"EffectiveMobile/pkg/postgres"
 	"log/slog"
 	"net/http"
 
@@ -15,36 +20,63 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func NewRouter(log *slog.Logger, serviceRepo *repository.ServiceRepository, subscriptionRepo *repository.SubscriptionRepository, statsRepo *repository.StatsRepository) chi.Router {
+// requestTimeout bounds every request but the bulk ones, which set their own deadlines.
+const requestTimeout = 10 * time.Second
+
+func NewRouter(log *slog.Logger, provider *postgres.Provider, serviceRepo *repository.ServiceRepository, subscriptionService *service.SubscriptionService, statsRepo *repository.StatsRepository, budgetRepo *repository.BudgetRepository, webhookRepo *repository.WebhookRepository, readiness *health.Readiness) chi.Router {
 	router := chi.NewRouter()
 
+	registry := metrics.NewRegistry(log)
+	registry.Register(metrics.NewDBStatsCollector(provider.GetConn().Stats))
+	registry.Register(service.NewBusinessMetrics(statsRepo, log))
+
 	router.Use(middleware.RequestID)
+	router.Use(httptracing.New())
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.URLFormat)
-	router.Use(middleware.Timeout(10 * time.Second))
 
 	router.Use(logger.New(log))
+	router.Use(httpmetrics.New(registry))
 
-	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
-
-	subscriptionService := service.NewSubscriptionService(serviceRepo, subscriptionRepo, log)
+	webhookService := service.NewWebhookService(webhookRepo, log)
 	statsService := service.NewStatsService(statsRepo, log)
+	catalogService := service.NewCatalogService(serviceRepo, log)
+	budgetService := service.NewBudgetService(budgetRepo, statsService, log)
 
-	fs := http.FileServer(http.Dir(".static/swagger"))
-	router.Handle("/static/swagger/*", http.StripPrefix("/static/swagger", fs))
+	router.Group(func(router chi.Router) {
+		router.Use(middleware.Timeout(requestTimeout))
 
-	router.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("/static/swagger/swagger.json"),
-	))
+		router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		router.Get("/livez", health.LiveHandler())
+		router.Get("/readyz", readiness.Handler())
 
-	router.Route("/api/v1", func(r chi.Router) {
-		r.Route("/subscriptions", func(r chi.Router) {
-			r.Mount("/", handlers.GetSubscriptionsRoutes(subscriptionService, log))
+		router.Method(http.MethodGet, "/metrics", registry.Handler())
+
+		fs := http.FileServer(http.Dir(".static/swagger"))
+		router.Handle("/static/swagger/*", http.StripPrefix("/static/swagger", fs))
+
+		router.Get("/swagger/*", httpSwagger.Handler(
+			httpSwagger.URL("/static/swagger/swagger.json"),
+		))
+
+		router.Route("/api/v1", func(r chi.Router) {
+			r.Route("/subscriptions", func(r chi.Router) {
+				r.Mount("/", handlers.GetSubscriptionsRoutes(subscriptionService, log))
+			})
+			r.Mount("/services", handlers.GetServicesRoutes(catalogService, log))
+			r.Mount("/stats", handlers.GetStatRoutes(statsService, log))
+			r.Mount("/users", handlers.GetUsersRoutes(budgetService, log))
+			r.Mount("/webhooks", handlers.GetWebhookRoutes(webhookService, log))
 		})
-		r.Mount("/stats", handlers.GetStatRoutes(statsService, log))
 	})
 
+	// Bulk endpoints move thousands of rows and extend the server deadlines themselves,
+	// so they are routed around requestTimeout.
+	router.Get("/api/v1/subscriptions/export", handlers.ExportSubscriptions(subscriptionService, log))
+	router.Post("/api/v1/subscriptions/import", handlers.ImportSubscriptions(subscriptionService, log))
+	router.Post("/api/v1/subscriptions/batch", handlers.SaveSubscriptionsBatch(subscriptionService, log))
+
 	return router
 }
@@ -14,6 +14,12 @@ type Config struct {
 	Env         string `yaml:"env" env-default:"development"`
 	HTTPServer  `yaml:"http_server"`
 	SQLDataBase SQLConnection `yaml:"sql_data_base"`
+	Webhooks    Webhooks      `yaml:"webhooks"`
+	Outbox      Outbox        `yaml:"outbox"`
+	Tracing     Tracing       `yaml:"tracing"`
+	// MigrateOnStart applies the migrations embedded in the binary before the server
+	// starts.
+	MigrateOnStart bool `yaml:"migrate_on_start" env-default:"false"`
 }
 
 type SQLConnection struct {
@@ -26,6 +32,41 @@ type HTTPServer struct {
 	Address     string        `yaml:"address" env-default:"localhost:8080"`
 	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"5s"`
+	// DrainDelay is how long /readyz fails before the server stops accepting
+	// connections, giving load balancers time to take the instance out of rotation.
+	DrainDelay time.Duration `yaml:"drain_delay" env-default:"5s"`
+}
+
+type Webhooks struct {
+	PollInterval       time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize          int           `yaml:"batch_size" env-default:"50"`
+	MaxAttempts        int           `yaml:"max_attempts" env-default:"10"`
+	InitialBackoff     time.Duration `yaml:"initial_backoff" env-default:"10s"`
+	MaxBackoff         time.Duration `yaml:"max_backoff" env-default:"1h"`
+	RequestTimeout     time.Duration `yaml:"request_timeout" env-default:"5s"`
+	EndingSoonWithin   time.Duration `yaml:"ending_soon_within" env-default:"168h"`
+	EndingSoonInterval time.Duration `yaml:"ending_soon_interval" env-default:"1h"`
+}
+
+// Outbox configures the worker publishing the outbox events. Events always go to the
+// webhooks; LogEvents, HTTPURL and FilePath enable the other publishers.
+type Outbox struct {
+	PollInterval   time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize      int           `yaml:"batch_size" env-default:"100"`
+	MaxAttempts    int           `yaml:"max_attempts" env-default:"20"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" env-default:"1s"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" env-default:"10m"`
+	LogEvents      bool          `yaml:"log_events" env-default:"false"`
+	HTTPURL        string        `yaml:"http_url"`
+	HTTPTimeout    time.Duration `yaml:"http_timeout" env-default:"5s"`
+	FilePath       string        `yaml:"file_path"`
+}
+
+// Tracing selects where spans go: "none" turns tracing off, "stdout" writes them as
+// JSON lines to stdout and "file" appends them to FilePath.
+type Tracing struct {
+	Exporter string `yaml:"exporter" env-default:"none"`
+	FilePath string `yaml:"file_path"`
 }
 
 func MustLoad() (*Config, error) {
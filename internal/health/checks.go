@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Pinger is satisfied by *postgres.Provider.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// DatabaseCheck fails when the database does not answer a ping in time.
+func DatabaseCheck(db Pinger) Check {
+	return func(ctx context.Context) Result {
+		started := time.Now()
+		err := db.Ping(ctx)
+		details := map[string]any{"latency_ms": time.Since(started).Milliseconds()}
+
+		if err != nil {
+			return Result{Status: StatusFail, Error: err.Error(), Details: details}
+		}
+		return Result{Status: StatusOK, Details: details}
+	}
+}
+
+// MigrationVersioner is satisfied by *postgres.Provider.
+type MigrationVersioner interface {
+	MigrationVersion(ctx context.Context, table string) (uint, bool, error)
+}
+
+// MigrationsCheck fails when the schema recorded in table is dirty or older than
+// expected, the last migration this binary knows. A newer schema is fine: it is what
+// the old replicas see during a rolling update.
+func MigrationsCheck(db MigrationVersioner, table string, expected uint) Check {
+	return func(ctx context.Context) Result {
+		version, dirty, err := db.MigrationVersion(ctx, table)
+		if err != nil {
+			return Result{Status: StatusFail, Error: err.Error(), Details: map[string]any{"expected": expected}}
+		}
+
+		details := map[string]any{"version": version, "expected": expected, "dirty": dirty}
+		switch {
+		case dirty:
+			return Result{Status: StatusFail, Error: fmt.Sprintf("migration %d failed halfway", version), Details: details}
+		case version < expected:
+			return Result{Status: StatusFail, Error: fmt.Sprintf("schema version %d is behind %d", version, expected), Details: details}
+		}
+		return Result{Status: StatusOK, Details: details}
+	}
+}
+
+// PoolCheck reports the connection pool usage. An exhausted pool, where queries wait
+// for a connection, is only a warning: failing it would move the load onto the other
+// replicas, which are likely as busy.
+func PoolCheck(stats func() sql.DBStats) Check {
+	return func(context.Context) Result {
+		s := stats()
+
+		details := map[string]any{
+			"open":       s.OpenConnections,
+			"in_use":     s.InUse,
+			"idle":       s.Idle,
+			"max_open":   s.MaxOpenConnections,
+			"wait_count": s.WaitCount,
+			"wait_ms":    s.WaitDuration.Milliseconds(),
+			"saturation": 0.0,
+		}
+		if s.MaxOpenConnections > 0 {
+			details["saturation"] = float64(s.InUse) / float64(s.MaxOpenConnections)
+			if s.InUse >= s.MaxOpenConnections {
+				return Result{Status: StatusWarn, Error: "connection pool exhausted", Details: details}
+			}
+		}
+		return Result{Status: StatusOK, Details: details}
+	}
+}
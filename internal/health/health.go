@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK = "ok"
+	// StatusWarn is reported for a degraded dependency that does not make the service
+	// unready.
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// shutdownCheck is the check reported once Drain is called.
+const shutdownCheck = "shutdown"
+
+// Result is the outcome of one check.
+type Result struct {
+	Status  string         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Check reports the state of a dependency. It must return once ctx is done.
+type Check func(ctx context.Context) Result
+
+// Report is the readiness of the service: failing when any check fails.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Readiness decides whether the service should receive traffic.
+type Readiness struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewReadiness returns a readiness whose checks are each given timeout to complete.
+func NewReadiness(timeout time.Duration) *Readiness {
+	return &Readiness{
+		timeout: timeout,
+	}
+}
+
+// Add registers a check. Checks are added before the handler serves requests.
+func (r *Readiness) Add(name string, check Check) {
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes the service unready for good, so load balancers stop sending it traffic
+// before the server shuts down.
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+// Check runs every check concurrently.
+func (r *Readiness) Check(ctx context.Context) Report {
+	results := make([]Result, len(r.checks))
+
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+
+			results[i] = c.check(checkCtx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(r.checks)+1),
+	}
+	for i, c := range r.checks {
+		report.Checks[c.name] = results[i]
+	}
+	if r.draining.Load() {
+		report.Checks[shutdownCheck] = Result{Status: StatusFail, Error: "shutting down"}
+	}
+
+	for _, result := range report.Checks {
+		if result.Status == StatusFail {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+// Handler serves the report with 200 when the service is ready and 503 otherwise.
+func (r *Readiness) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context())
+
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, status, report)
+	}
+}
+
+// LiveHandler reports that the process is up and serving requests. It checks no
+// dependencies, so a database outage does not get the service restarted.
+func LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusOK, Checks: map[string]Result{}})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,167 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeDatabase struct {
+	pingErr    error
+	version    uint
+	dirty      bool
+	versionErr error
+}
+
+func (f *fakeDatabase) Ping(ctx context.Context) error {
+	return f.pingErr
+}
+
+func (f *fakeDatabase) MigrationVersion(ctx context.Context, table string) (uint, bool, error) {
+	return f.version, f.dirty, f.versionErr
+}
+
+type ReadinessSuite struct {
+	suite.Suite
+
+	db        *fakeDatabase
+	stats     sql.DBStats
+	readiness *Readiness
+}
+
+func TestReadiness(t *testing.T) {
+	suite.Run(t, &ReadinessSuite{})
+}
+
+func (s *ReadinessSuite) SetupTest() {
+	s.db = &fakeDatabase{version: 12}
+	s.stats = sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2}
+
+	s.readiness = NewReadiness(time.Second)
+	s.readiness.Add("database", DatabaseCheck(s.db))
+	s.readiness.Add("migrations", MigrationsCheck(s.db, "schema_migrations", 12))
+	s.readiness.Add("pool", PoolCheck(func() sql.DBStats { return s.stats }))
+}
+
+func (s *ReadinessSuite) serve(handler http.Handler) (int, Report) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+	s.Equal("no-store", rec.Header().Get("Cache-Control"))
+
+	var report Report
+	s.Require().NoError(json.NewDecoder(rec.Body).Decode(&report))
+	return rec.Code, report
+}
+
+func (s *ReadinessSuite) TestReady() {
+	code, report := s.serve(s.readiness.Handler())
+
+	s.Equal(http.StatusOK, code)
+	s.Equal(StatusOK, report.Status)
+	s.Len(report.Checks, 3)
+	for name, result := range report.Checks {
+		s.Equal(StatusOK, result.Status, name)
+	}
+	s.Equal(float64(12), report.Checks["migrations"].Details["version"])
+	s.Equal(0.1, report.Checks["pool"].Details["saturation"])
+}
+
+func (s *ReadinessSuite) TestDatabaseDown() {
+	s.db.pingErr = errors.New("connection refused")
+	s.db.versionErr = errors.New("connection refused")
+
+	code, report := s.serve(s.readiness.Handler())
+
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal(StatusFail, report.Status)
+	s.Equal(StatusFail, report.Checks["database"].Status)
+	s.Equal("connection refused", report.Checks["database"].Error)
+	s.Equal(StatusFail, report.Checks["migrations"].Status)
+	s.Equal(StatusOK, report.Checks["pool"].Status)
+}
+
+func (s *ReadinessSuite) TestDirtyMigration() {
+	s.db.dirty = true
+
+	code, report := s.serve(s.readiness.Handler())
+
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal(StatusFail, report.Checks["migrations"].Status)
+	s.Equal(true, report.Checks["migrations"].Details["dirty"])
+}
+
+func (s *ReadinessSuite) TestSchemaBehind() {
+	s.db.version = 11
+
+	code, report := s.serve(s.readiness.Handler())
+
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal("schema version 11 is behind 12", report.Checks["migrations"].Error)
+}
+
+func (s *ReadinessSuite) TestSchemaAhead() {
+	s.db.version = 13
+
+	code, _ := s.serve(s.readiness.Handler())
+
+	s.Equal(http.StatusOK, code)
+}
+
+func (s *ReadinessSuite) TestExhaustedPoolIsOnlyAWarning() {
+	s.stats.InUse = 10
+	s.stats.WaitCount = 4
+
+	code, report := s.serve(s.readiness.Handler())
+
+	s.Equal(http.StatusOK, code)
+	s.Equal(StatusOK, report.Status)
+	s.Equal(StatusWarn, report.Checks["pool"].Status)
+	s.Equal(float64(1), report.Checks["pool"].Details["saturation"])
+	s.Equal(float64(4), report.Checks["pool"].Details["wait_count"])
+}
+
+func (s *ReadinessSuite) TestDrain() {
+	s.readiness.Drain()
+
+	code, report := s.serve(s.readiness.Handler())
+
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal(StatusFail, report.Status)
+	s.Equal(StatusFail, report.Checks[shutdownCheck].Status)
+	s.Equal(StatusOK, report.Checks["database"].Status)
+}
+
+func (s *ReadinessSuite) TestCheckTimeout() {
+	readiness := NewReadiness(20 * time.Millisecond)
+	readiness.Add("slow", func(ctx context.Context) Result {
+		<-ctx.Done()
+		return Result{Status: StatusFail, Error: ctx.Err().Error()}
+	})
+
+	started := time.Now()
+	report := readiness.Check(context.Background())
+
+	s.Less(time.Since(started), time.Second)
+	s.Equal(StatusFail, report.Status)
+	s.Equal(context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func (s *ReadinessSuite) TestLiveIgnoresDependencies() {
+	s.db.pingErr = errors.New("connection refused")
+	s.readiness.Drain()
+
+	code, report := s.serve(LiveHandler())
+
+	s.Equal(http.StatusOK, code)
+	s.Equal(StatusOK, report.Status)
+	s.Empty(report.Checks)
+}
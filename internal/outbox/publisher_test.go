@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type PublisherSuite struct {
+	suite.Suite
+
+	ctx context.Context
+}
+
+func TestPublishers(t *testing.T) {
+	suite.Run(t, &PublisherSuite{})
+}
+
+func (s *PublisherSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func testEvent() repository.Event {
+	return repository.Event{
+		ID:         uuid.New(),
+		Type:       repository.EventSubscriptionDeleted,
+		OccurredAt: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Data:       json.RawMessage(`{"subscription_id":7}`),
+	}
+}
+
+func (s *PublisherSuite) TestHTTPPublisher() {
+	event := testEvent()
+
+	var received repository.Event
+	var eventID string
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		eventID = r.Header.Get("X-Event-Id")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, time.Second)
+
+	s.Require().NoError(publisher.Publish(s.ctx, event))
+	s.Require().Equal(event.ID, received.ID)
+	s.Require().Equal(event.Type, received.Type)
+	s.Require().JSONEq(string(event.Data), string(received.Data))
+	s.Require().Equal(event.ID.String(), eventID)
+
+	status = http.StatusServiceUnavailable
+	s.Require().Error(publisher.Publish(s.ctx, event))
+}
+
+func (s *PublisherSuite) TestFilePublisher() {
+	path := filepath.Join(s.T().TempDir(), "events.jsonl")
+
+	publisher, err := NewFilePublisher(path)
+	s.Require().NoError(err)
+
+	first, second := testEvent(), testEvent()
+	s.Require().NoError(publisher.Publish(s.ctx, first))
+	s.Require().NoError(publisher.Publish(s.ctx, second))
+	s.Require().NoError(publisher.Close())
+
+	file, err := os.Open(path)
+	s.Require().NoError(err)
+	defer file.Close()
+
+	var ids []uuid.UUID
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event repository.Event
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.ID)
+	}
+	s.Require().NoError(scanner.Err())
+	s.Require().Equal([]uuid.UUID{first.ID, second.ID}, ids)
+}
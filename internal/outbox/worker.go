@@ -0,0 +1,121 @@
+package outbox
+
+//go:generate mockgen -destination=worker_mock.go -source=worker.go -package=outbox
+
+import (
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Publisher hands outbox events to another system. An event is published at least
+// once: it is handed to every publisher again when any of them fails.
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, event repository.Event) error
+}
+
+type Store interface {
+	ProcessEvents(ctx context.Context, limit int, handle func(context.Context, repository.OutboxEvent) repository.OutboxOutcome) (int, error)
+}
+
+type Config struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Worker publishes the events written to the outbox by the repositories.
+type Worker struct {
+	store      Store
+	publishers []Publisher
+	cfg        Config
+	log        *slog.Logger
+}
+
+func NewWorker(store Store, publishers []Publisher, cfg Config, log *slog.Logger) *Worker {
+	return &Worker{
+		store:      store,
+		publishers: publishers,
+		cfg:        cfg,
+		log:        log.With(slog.String("op", "outbox.Worker")),
+	}
+}
+
+// Run polls the outbox until ctx is done. A batch that already started is finished
+// first, so its events are not published again after a restart.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		processed, err := w.store.ProcessEvents(context.WithoutCancel(ctx), w.cfg.BatchSize, w.handle)
+		if err != nil {
+			w.log.Error("process outbox failed", slog.String("err", err.Error()))
+		}
+
+		// A full batch means there are probably more due events.
+		if err == nil && processed == w.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) handle(ctx context.Context, event repository.OutboxEvent) repository.OutboxOutcome {
+	ctx, span := tracing.Start(ctx, "outbox.Worker.handle",
+		slog.String("event_id", event.Event.ID.String()),
+		slog.String("event", event.Event.Type),
+		slog.Int("attempt", event.Attempt),
+	)
+	defer span.End()
+
+	var errs []error
+	for _, publisher := range w.publishers {
+		if err := publisher.Publish(ctx, event.Event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", publisher.Name(), err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return repository.OutboxOutcome{Published: true}
+	}
+
+	message := errors.Join(errs...).Error()
+	span.SetError(message)
+	outcome := repository.OutboxOutcome{Error: &message}
+	if event.Attempt < w.cfg.MaxAttempts {
+		next := time.Now().Add(Backoff(w.cfg.InitialBackoff, w.cfg.MaxBackoff, event.Attempt))
+		outcome.NextAttemptAt = &next
+	}
+
+	w.log.Warn("publish event failed",
+		slog.String("event_id", event.Event.ID.String()),
+		slog.String("event", event.Event.Type),
+		slog.Int("attempt", event.Attempt),
+		slog.String("err", message),
+	)
+
+	return outcome
+}
+
+// Backoff returns the delay after the given failed attempt: initial, doubled with
+// every further attempt, at most maxDelay.
+func Backoff(initial, maxDelay time.Duration, attempt int) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type WorkerSuite struct {
+	suite.Suite
+
+	ctrl    *gomock.Controller
+	store   *MockStore
+	webhook *MockPublisher
+	file    *MockPublisher
+	worker  *Worker
+	ctx     context.Context
+}
+
+func TestWorker(t *testing.T) {
+	suite.Run(t, &WorkerSuite{})
+}
+
+func (s *WorkerSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.store = NewMockStore(s.ctrl)
+	s.webhook = NewMockPublisher(s.ctrl)
+	s.webhook.EXPECT().Name().Return("webhook").AnyTimes()
+	s.file = NewMockPublisher(s.ctrl)
+	s.file.EXPECT().Name().Return("file").AnyTimes()
+	s.ctx = context.Background()
+
+	s.worker = NewWorker(s.store, []Publisher{s.webhook, s.file}, Config{
+		PollInterval:   time.Hour,
+		BatchSize:      2,
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}, slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+}
+
+func (s *WorkerSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *WorkerSuite) outboxEvent(attempt int) repository.OutboxEvent {
+	return repository.OutboxEvent{
+		ID: 1,
+		Event: repository.Event{
+			ID:         uuid.New(),
+			Type:       repository.EventSubscriptionCreated,
+			OccurredAt: time.Now(),
+			Data:       []byte(`{"subscription_id":1}`),
+		},
+		Attempt: attempt,
+	}
+}
+
+func (s *WorkerSuite) TestHandle_PublishesToEveryPublisher() {
+	event := s.outboxEvent(1)
+	s.webhook.EXPECT().Publish(s.ctx, event.Event).Return(nil)
+	s.file.EXPECT().Publish(s.ctx, event.Event).Return(nil)
+
+	outcome := s.worker.handle(s.ctx, event)
+
+	s.True(outcome.Published)
+	s.Nil(outcome.Error)
+}
+
+func (s *WorkerSuite) TestHandle_FailedPublisherReschedules() {
+	event := s.outboxEvent(2)
+	s.webhook.EXPECT().Publish(s.ctx, event.Event).Return(nil)
+	s.file.EXPECT().Publish(s.ctx, event.Event).Return(errors.New("disk full"))
+
+	before := time.Now()
+	outcome := s.worker.handle(s.ctx, event)
+
+	s.False(outcome.Published)
+	s.Require().NotNil(outcome.Error)
+	s.Equal("file: disk full", *outcome.Error)
+	s.Require().NotNil(outcome.NextAttemptAt)
+	s.WithinDuration(before.Add(2*time.Second), *outcome.NextAttemptAt, time.Second)
+}
+
+func (s *WorkerSuite) TestHandle_LastAttemptGivesUp() {
+	event := s.outboxEvent(3)
+	s.webhook.EXPECT().Publish(s.ctx, event.Event).Return(errors.New("connection refused"))
+	s.file.EXPECT().Publish(s.ctx, event.Event).Return(nil)
+
+	outcome := s.worker.handle(s.ctx, event)
+
+	s.False(outcome.Published)
+	s.NotNil(outcome.Error)
+	s.Nil(outcome.NextAttemptAt)
+}
+
+func (s *WorkerSuite) TestRun_DrainsFullBatchesAndStops() {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	gomock.InOrder(
+		s.store.EXPECT().ProcessEvents(gomock.Any(), 2, gomock.Any()).Return(2, nil),
+		s.store.EXPECT().ProcessEvents(gomock.Any(), 2, gomock.Any()).DoAndReturn(
+			func(context.Context, int, func(context.Context, repository.OutboxEvent) repository.OutboxOutcome) (int, error) {
+				cancel()
+				return 1, nil
+			}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		s.worker.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.Fail("worker did not stop")
+	}
+}
+
+func (s *WorkerSuite) TestBackoff() {
+	s.Equal(10*time.Second, Backoff(10*time.Second, time.Hour, 1))
+	s.Equal(20*time.Second, Backoff(10*time.Second, time.Hour, 2))
+	s.Equal(80*time.Second, Backoff(10*time.Second, time.Hour, 4))
+	s.Equal(time.Hour, Backoff(10*time.Second, time.Hour, 30))
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrBudgetNotFound      = errors.New("budget not found")
+	ErrBudgetAlreadyExists = errors.New("budget already exists")
+)
+
+type Budget struct {
+	UserID       uuid.UUID
+	MonthlyLimit int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// BudgetMonth is a month of a budget status period with the cost charged for it.
+type BudgetMonth struct {
+	MonthlyCost
+	Overspend int
+}
+
+type BudgetStatus struct {
+	Budget           Budget
+	TotalCost        int
+	StartDate        *time.Time
+	EndDate          *time.Time
+	MonthsCount      int
+	OverBudgetMonths []BudgetMonth
+}
+
+type BudgetRepository struct {
+	provider Provider
+	logger   Logger
+}
+
+func NewBudgetRepository(provider Provider, logger Logger) *BudgetRepository {
+	return &BudgetRepository{
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+func (r *BudgetRepository) GetBudget(ctx context.Context, userID uuid.UUID) (*Budget, error) {
+	query, args, err := squirrel.Select("user_id", "monthly_limit_rub", "created_at", "updated_at").
+		From("budget").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	var budget Budget
+	err = r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).
+		Scan(&budget.UserID, &budget.MonthlyLimit, &budget.CreatedAt, &budget.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBudgetNotFound
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return &budget, nil
+}
+
+func (r *BudgetRepository) CreateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	query, args, err := squirrel.Insert("budget").
+		Columns("user_id", "monthly_limit_rub").
+		Values(userID, monthlyLimit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrBudgetAlreadyExists
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BudgetRepository) UpdateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	query, args, err := squirrel.Update("budget").
+		Set("monthly_limit_rub", monthlyLimit).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	return r.execAffectingBudget(ctx, query, args)
+}
+
+func (r *BudgetRepository) DeleteBudget(ctx context.Context, userID uuid.UUID) error {
+	query, args, err := squirrel.Delete("budget").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	return r.execAffectingBudget(ctx, query, args)
+}
+
+func (r *BudgetRepository) execAffectingBudget(ctx context.Context, query string, args []any) error {
+	result, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrBudgetNotFound
+	}
+
+	return nil
+}
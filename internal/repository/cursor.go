@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type SubscriptionSort string
+
+const (
+	SortByID          SubscriptionSort = "id"
+	SortByPrice       SubscriptionSort = "price"
+	SortByStartDate   SubscriptionSort = "start_date"
+	SortByEndDate     SubscriptionSort = "end_date"
+	SortByServiceName SubscriptionSort = "service_name"
+)
+
+type sortKey struct {
+	// expr is the sort expression; subscriptions without end_date sort as if they
+	// ended at infinity, so the key is never NULL.
+	expr string
+	// sqlType is used to cast the cursor value back in the keyset condition.
+	sqlType string
+	value   func(s Subscription) string
+	// valid reports whether a cursor value can be cast to sqlType.
+	valid func(value string) bool
+}
+
+var sortKeys = map[SubscriptionSort]sortKey{
+	SortByID: {
+		expr:    "s.id",
+		sqlType: "bigint",
+		value:   func(s Subscription) string { return strconv.FormatInt(s.ID, 10) },
+		valid:   func(string) bool { return true },
+	},
+	SortByPrice: {
+		expr:    "s.price_rub",
+		sqlType: "integer",
+		value:   func(s Subscription) string { return strconv.Itoa(s.Price) },
+		valid: func(value string) bool {
+			_, err := strconv.ParseInt(value, 10, 32)
+			return err == nil
+		},
+	},
+	SortByStartDate: {
+		expr:    "s.start_date",
+		sqlType: "date",
+		value:   func(s Subscription) string { return s.StartDate.Format("2006-01-02") },
+		valid:   isCursorDate,
+	},
+	SortByEndDate: {
+		expr:    "COALESCE(s.end_date, 'infinity'::date)",
+		sqlType: "date",
+		value: func(s Subscription) string {
+			if s.EndDate == nil {
+				return "infinity"
+			}
+			return s.EndDate.Format("2006-01-02")
+		},
+		valid: func(value string) bool { return value == "infinity" || isCursorDate(value) },
+	},
+	SortByServiceName: {
+		expr:    "sv.name",
+		sqlType: "text",
+		value:   func(s Subscription) string { return s.ServiceName },
+		valid:   func(value string) bool { return value != "" },
+	},
+}
+
+func isCursorDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+// IsValid reports whether the subscription list can be sorted by s.
+func (s SubscriptionSort) IsValid() bool {
+	_, ok := sortKeys[s]
+	return ok
+}
+
+// ListCursor marks the position after the last subscription of a page. Clients get
+// it encoded and pass it back unchanged, so its fields can change between releases.
+type ListCursor struct {
+	// SortBy and Desc are the order the cursor was issued for.
+	SortBy SubscriptionSort `json:"s"`
+	Desc   bool             `json:"d,omitempty"`
+	// Value is the sort key of the last subscription of the page.
+	Value string `json:"v,omitempty"`
+	// ID is the id of the last subscription of the page, it breaks ties between
+	// equal sort keys.
+	ID int64 `json:"id"`
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c ListCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeListCursor parses a cursor returned by Encode. The cursor is only valid for
+// the sort order it was issued for.
+func DecodeListCursor(value string, sortBy SubscriptionSort, desc bool) (*ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor ListCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.SortBy != normalizeSort(sortBy) || cursor.Desc != desc || cursor.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	// The value goes into the query as is, so it must have the type of the sort key.
+	if cursor.SortBy != SortByID && !sortKeys[cursor.SortBy].valid(cursor.Value) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func normalizeSort(sortBy SubscriptionSort) SubscriptionSort {
+	if sortBy == "" {
+		return SortByID
+	}
+	return sortBy
+}
+
+// applySort orders the list by p.SortBy with the id as a tiebreaker and, when a
+// cursor is given, keeps only the rows after it.
+func applySort(builder squirrel.SelectBuilder, p ListSubscriptionsParams) (squirrel.SelectBuilder, error) {
+	sortBy := normalizeSort(p.SortBy)
+	key, ok := sortKeys[sortBy]
+	if !ok {
+		return builder, fmt.Errorf("unknown sort %q", sortBy)
+	}
+
+	direction, op := "ASC", ">"
+	if p.SortDesc {
+		direction, op = "DESC", "<"
+	}
+
+	if p.After != nil {
+		if sortBy == SortByID {
+			builder = builder.Where("s.id "+op+" ?", p.After.ID)
+		} else {
+			builder = builder.Where(
+				fmt.Sprintf("(%s, s.id) %s (CAST(? AS %s), ?)", key.expr, op, key.sqlType),
+				p.After.Value, p.After.ID,
+			)
+		}
+	}
+
+	if sortBy == SortByID {
+		return builder.OrderBy("s.id " + direction), nil
+	}
+	return builder.OrderBy(key.expr+" "+direction, "s.id "+direction), nil
+}
+
+func cursorAfter(s Subscription, p ListSubscriptionsParams) *ListCursor {
+	sortBy := normalizeSort(p.SortBy)
+	cursor := &ListCursor{SortBy: sortBy, Desc: p.SortDesc, ID: s.ID}
+	if sortBy != SortByID {
+		cursor.Value = sortKeys[sortBy].value(s)
+	}
+	return cursor
+}
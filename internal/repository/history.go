@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"EffectiveMobile/pkg/postgres"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type HistoryAction string
+
+const (
+	HistoryActionCreated  HistoryAction = "created"
+	HistoryActionUpdated  HistoryAction = "updated"
+	HistoryActionDeleted  HistoryAction = "deleted"
+	HistoryActionRestored HistoryAction = "restored"
+)
+
+// SubscriptionSnapshot is the state of a subscription stored in the change history.
+type SubscriptionSnapshot struct {
+	ServiceName string     `json:"service_name"`
+	PriceRub    int        `json:"price_rub"`
+	UserID      uuid.UUID  `json:"user_id"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	Version     int        `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type SubscriptionHistoryRecord struct {
+	ID             int64
+	SubscriptionID int64
+	Action         HistoryAction
+	Before         *SubscriptionSnapshot
+	After          *SubscriptionSnapshot
+	RequestID      string
+	ChangedAt      time.Time
+}
+
+func snapshotOf(s *Subscription) *SubscriptionSnapshot {
+	if s == nil {
+		return nil
+	}
+	return &SubscriptionSnapshot{
+		ServiceName: s.ServiceName,
+		PriceRub:    s.Price,
+		UserID:      s.UserID,
+		StartDate:   s.StartDate,
+		EndDate:     s.EndDate,
+		Version:     s.Version,
+		DeletedAt:   s.DeletedAt,
+	}
+}
+
+// addHistory appends a change record within tx, so it is only kept together with the
+// change it describes.
+func (r *SubscriptionRepository) addHistory(
+	ctx context.Context,
+	tx postgres.DBTX,
+	subscriptionID int64,
+	action HistoryAction,
+	before, after *Subscription,
+	requestID string,
+) error {
+	beforeJSON, err := marshalSnapshot(snapshotOf(before))
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalSnapshot(snapshotOf(after))
+	if err != nil {
+		return err
+	}
+
+	var requestIDValue *string
+	if requestID != "" {
+		requestIDValue = &requestID
+	}
+
+	query, args, err := squirrel.Insert("subscription_history").
+		Columns("subscription_id", "action", "before", "after", "request_id").
+		Values(subscriptionID, string(action), beforeJSON, afterJSON, requestIDValue).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// ListSubscriptionHistory returns the changes of a subscription, oldest first.
+func (r *SubscriptionRepository) ListSubscriptionHistory(ctx context.Context, subscriptionID int64) ([]SubscriptionHistoryRecord, error) {
+	if _, err := r.GetSubscription(ctx, subscriptionID, true); err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.Select("id", "subscription_id", "action", "before", "after", "request_id", "changed_at").
+		From("subscription_history").
+		Where(squirrel.Eq{"subscription_id": subscriptionID}).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var records []SubscriptionHistoryRecord
+	for rows.Next() {
+		var record SubscriptionHistoryRecord
+		var action string
+		var before, after []byte
+		var requestID sql.NullString
+
+		err := rows.Scan(&record.ID, &record.SubscriptionID, &action, &before, &after, &requestID, &record.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		record.Action = HistoryAction(action)
+		record.RequestID = requestID.String
+
+		if record.Before, err = unmarshalSnapshot(before); err != nil {
+			return nil, err
+		}
+		if record.After, err = unmarshalSnapshot(after); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return records, nil
+}
+
+// marshalSnapshot encodes the snapshot as a JSONB argument, nil becomes NULL.
+func marshalSnapshot(snapshot *SubscriptionSnapshot) (any, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalSnapshot(data []byte) (*SubscriptionSnapshot, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var snapshot SubscriptionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"EffectiveMobile/pkg/postgres"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+const (
+	EventSubscriptionCreated    = "subscription.created"
+	EventSubscriptionUpdated    = "subscription.updated"
+	EventSubscriptionDeleted    = "subscription.deleted"
+	EventSubscriptionRestored   = "subscription.restored"
+	EventSubscriptionEndingSoon = "subscription.ending_soon"
+)
+
+// EventTypes lists every event written to the outbox.
+var EventTypes = []string{
+	EventSubscriptionCreated,
+	EventSubscriptionUpdated,
+	EventSubscriptionDeleted,
+	EventSubscriptionRestored,
+	EventSubscriptionEndingSoon,
+}
+
+// Event is the JSON envelope handed to publishers and sent to webhook endpoints.
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// SubscriptionEventData is the data of subscription events: the subscription after
+// the change and, for updates, the subscription before it.
+type SubscriptionEventData struct {
+	SubscriptionID int64                  `json:"subscription_id"`
+	ServiceName    string                 `json:"service_name"`
+	Price          int                    `json:"price"`
+	UserID         uuid.UUID              `json:"user_id"`
+	StartDate      string                 `json:"start_date"`
+	EndDate        *string                `json:"end_date,omitempty"`
+	Version        int                    `json:"version"`
+	DeletedAt      *time.Time             `json:"deleted_at,omitempty"`
+	Previous       *SubscriptionEventData `json:"previous,omitempty"`
+}
+
+// OutboxEvent is a pending outbox row locked for publishing.
+type OutboxEvent struct {
+	ID    int64
+	Event Event
+	// Attempt is the number of the current attempt, starting from 1.
+	Attempt int
+}
+
+// OutboxOutcome is the result of publishing an outbox event. A failed event without
+// NextAttemptAt is given up.
+type OutboxOutcome struct {
+	Published     bool
+	Error         *string
+	NextAttemptAt *time.Time
+}
+
+func subscriptionEventData(s *Subscription) *SubscriptionEventData {
+	if s == nil {
+		return nil
+	}
+	data := &SubscriptionEventData{
+		SubscriptionID: s.ID,
+		ServiceName:    s.ServiceName,
+		Price:          s.Price,
+		UserID:         s.UserID,
+		StartDate:      s.StartDate.Format("01-2006"),
+		Version:        s.Version,
+		DeletedAt:      s.DeletedAt,
+	}
+	if s.EndDate != nil {
+		endDate := s.EndDate.Format("01-2006")
+		data.EndDate = &endDate
+	}
+	return data
+}
+
+// addEvent writes a subscription event to the outbox within tx, so it is published
+// if and only if the change it describes is committed. previous is only kept for
+// updates.
+func (r *SubscriptionRepository) addEvent(ctx context.Context, tx postgres.DBTX, eventID uuid.UUID, eventType string, previous, current *Subscription) error {
+	data := subscriptionEventData(current)
+	if eventType == EventSubscriptionUpdated {
+		data.Previous = subscriptionEventData(previous)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	query, args, err := squirrel.Insert("outbox_event").
+		Columns("event_id", "event_type", "subscription_id", "payload").
+		Values(eventID, eventType, current.ID, string(payload)).
+		Suffix("ON CONFLICT (event_id) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+type OutboxRepository struct {
+	provider Provider
+	logger   Logger
+}
+
+func NewOutboxRepository(provider Provider, logger Logger) *OutboxRepository {
+	return &OutboxRepository{
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+// ProcessEvents locks up to limit due events, oldest first, and passes each to handle.
+// The rows stay locked until every outcome is stored, so concurrent workers skip them
+// instead of publishing them twice. It returns the number of handled events.
+func (r *OutboxRepository) ProcessEvents(ctx context.Context, limit int, handle func(context.Context, OutboxEvent) OutboxOutcome) (int, error) {
+	query, args, err := squirrel.Select("id", "event_id", "event_type", "payload", "created_at", "attempts").
+		From("outbox_event").
+		Where(squirrel.Eq{"status": "pending"}).
+		Where("next_attempt_at <= now()").
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("could not build query: %w", err)
+	}
+
+	var events []OutboxEvent
+	err = r.provider.WithinTx(ctx, func(txCtx context.Context) error {
+		tx := r.provider.Conn(txCtx)
+
+		events, err = r.lockEvents(txCtx, tx, query, args)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			// Publishers get ctx rather than txCtx: their writes must not be able to
+			// abort the transaction holding the locks.
+			if err := r.storeOutcome(txCtx, tx, event, handle(ctx, event)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+func (r *OutboxRepository) lockEvents(ctx context.Context, tx postgres.DBTX, query string, args []any) ([]OutboxEvent, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var events []OutboxEvent
+	for rows.Next() {
+		var event OutboxEvent
+		var payload []byte
+		err := rows.Scan(&event.ID, &event.Event.ID, &event.Event.Type, &payload, &event.Event.OccurredAt, &event.Attempt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		event.Event.Data = payload
+		event.Attempt++
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepository) storeOutcome(ctx context.Context, tx postgres.DBTX, event OutboxEvent, outcome OutboxOutcome) error {
+	update := squirrel.Update("outbox_event").
+		Set("attempts", event.Attempt).
+		Set("last_error", outcome.Error).
+		Where(squirrel.Eq{"id": event.ID})
+	switch {
+	case outcome.Published:
+		update = update.Set("status", "delivered").Set("delivered_at", squirrel.Expr("now()"))
+	case outcome.NextAttemptAt != nil:
+		update = update.Set("next_attempt_at", *outcome.NextAttemptAt)
+	default:
+		update = update.Set("status", "failed")
+	}
+
+	query, args, err := update.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
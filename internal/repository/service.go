@@ -5,22 +5,28 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 
+	"EffectiveMobile/pkg/postgres"
+
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
-	ErrServiceNotFound   = errors.New("service not found")
-	ErrServiceNameExists = errors.New("service name already exists")
-	ErrServiceInUse      = errors.New("service is referenced by subscriptions")
-	ErrInvalidDateFormat = errors.New("invalid date format")
+	ErrServiceNotFound      = errors.New("service not found")
+	ErrServiceNameExists    = errors.New("service name already exists")
+	ErrServiceInUse         = errors.New("service is referenced by subscriptions")
+	ErrServiceAliasNotFound = errors.New("service alias not found")
+	ErrServiceMergeConflict = errors.New("services have subscriptions of one user starting in the same month")
 )
 
+// Provider runs queries on the pool or, within WithinTx, on its transaction.
 type Provider interface {
-	GetConn() *sql.DB
+	Conn(ctx context.Context) postgres.DBTX
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type Logger interface {
@@ -29,6 +35,48 @@ type Logger interface {
 	Error(msg string, args ...any)
 }
 
+type Service struct {
+	ID   int
+	Name string
+}
+
+// NormalizeServiceName returns the canonical form of a service name: surrounding
+// whitespace is removed and inner runs of whitespace collapse into one space.
+func NormalizeServiceName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// serviceKey is the form service names and aliases are compared in.
+func serviceKey(name string) string {
+	return strings.ToLower(NormalizeServiceName(name))
+}
+
+// serviceLookupQuery selects (key, service_id) of every service whose name or alias
+// has one of the given keys.
+func serviceLookupQuery(keys []string) squirrel.SelectBuilder {
+	aliases := squirrel.Select("lower(name)", "service_id").
+		From("service_alias").
+		Where(squirrel.Eq{"lower(name)": keys}).
+		Prefix("UNION ALL")
+
+	return squirrel.Select("lower(name)", "id").
+		From("service").
+		Where(squirrel.Eq{"lower(name)": keys}).
+		SuffixExpr(aliases)
+}
+
+// serviceNameIn matches rows whose service id column belongs to one of the named
+// services, given by name or alias in any case.
+func serviceNameIn(column string, names []string) squirrel.Sqlizer {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, serviceKey(name))
+	}
+
+	ids := squirrel.Select("l.id").FromSelect(serviceLookupQuery(keys), "l")
+	return squirrel.Expr(column+" IN (?)", ids)
+}
+
 type ServiceRepository struct {
 	provider Provider
 	logger   Logger
@@ -42,7 +90,7 @@ func NewServiceRepository(provider Provider, logger Logger) *ServiceRepository {
 }
 
 func (r *ServiceRepository) AddService(ctx context.Context, name string) (int, error) {
-	name = strings.TrimSpace(name)
+	name = NormalizeServiceName(name)
 	if name == "" {
 		return 0, errors.New("empty service name")
 	}
@@ -58,16 +106,88 @@ func (r *ServiceRepository) AddService(ctx context.Context, name string) (int, e
 	}
 
 	var id int
-	if err := r.provider.GetConn().QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			return 0, ErrServiceNameExists
+	err = r.provider.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.checkNotAlias(ctx, name, 0); err != nil {
+			return err
 		}
+
+		if err := r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrServiceNameExists
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
+// checkNotAlias returns ErrServiceNameExists if name is an alias of a service other
+// than exceptID.
+func (r *ServiceRepository) checkNotAlias(ctx context.Context, name string, exceptID int) error {
+	query, args, err := squirrel.Select("1").
+		Prefix("SELECT EXISTS (").
+		From("service_alias").
+		Where(squirrel.Eq{"lower(name)": serviceKey(name)}).
+		Where(squirrel.NotEq{"service_id": exceptID}).
+		Suffix(")").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	var exists bool
+	if err := r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if exists {
+		return ErrServiceNameExists
+	}
+	return nil
+}
+
+func (r *ServiceRepository) ListServices(ctx context.Context) ([]Service, error) {
+	query, args, err := squirrel.Select("id", "name").
+		From("service").
+		OrderBy("name").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var services []Service
+	for rows.Next() {
+		var service Service
+		if err := rows.Scan(&service.ID, &service.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		services = append(services, service)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return services, nil
+}
+
 func (r *ServiceRepository) GetServiceName(ctx context.Context, id int) (string, error) {
 	query, args, err := squirrel.Select("name").
 		From("service").
@@ -79,7 +199,7 @@ func (r *ServiceRepository) GetServiceName(ctx context.Context, id int) (string,
 	}
 
 	var name string
-	if err := r.provider.GetConn().QueryRowContext(ctx, query, args...).Scan(&name); err != nil {
+	if err := r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&name); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return "", ErrServiceNotFound
 		}
@@ -88,18 +208,19 @@ func (r *ServiceRepository) GetServiceName(ctx context.Context, id int) (string,
 	return name, nil
 }
 
+// GetServiceID returns the id of the service with the given name or alias, ignoring
+// case and extra whitespace.
 func (r *ServiceRepository) GetServiceID(ctx context.Context, name string) (int, error) {
-	query, args, err := squirrel.Select("id").
-		From("service").
-		Where(squirrel.Eq{"name": name}).
+	query, args, err := serviceLookupQuery([]string{serviceKey(name)}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
 		return 0, fmt.Errorf("could not build query: %w", err)
 	}
 
+	var key string
 	var id int
-	if err := r.provider.GetConn().QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+	if err := r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&key, &id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, ErrServiceNotFound
 		}
@@ -108,36 +229,228 @@ func (r *ServiceRepository) GetServiceID(ctx context.Context, name string) (int,
 	return id, nil
 }
 
+// GetOrCreateServiceID returns the id of the named service, creating it if needed.
+// Concurrent calls for the same new name all succeed with the same id: the insert
+// skips a conflicting row instead of failing, which would also abort a surrounding
+// transaction, and the row is then read by a fresh statement that sees it committed.
 func (r *ServiceRepository) GetOrCreateServiceID(ctx context.Context, name string) (int, error) {
-	id, err := r.GetServiceID(ctx, name)
-	if err == nil {
-		return id, nil
+	name = NormalizeServiceName(name)
+	for range maxServiceResolveAttempts {
+		id, err := r.GetServiceID(ctx, name)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, ErrServiceNotFound) {
+			return 0, err
+		}
+
+		id, err = r.insertServiceIfMissing(ctx, name)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, ErrServiceNameExists) {
+			return 0, err
+		}
+		// The name was taken by a concurrent insert: look it up again. A retry is only
+		// needed if that service has been deleted in between.
 	}
-	if !errors.Is(err, ErrServiceNotFound) {
-		return 0, err
+	return 0, fmt.Errorf("service %q was not resolved", name)
+}
+
+// maxServiceResolveAttempts bounds GetOrCreateServiceID when the service it races
+// with keeps being deleted.
+const maxServiceResolveAttempts = 3
+
+// insertServiceIfMissing adds the service and returns its id, or ErrServiceNameExists
+// if the name is already taken, without raising a unique violation.
+func (r *ServiceRepository) insertServiceIfMissing(ctx context.Context, name string) (int, error) {
+	query, args, err := squirrel.Insert("service").
+		Columns("name").
+		Values(name).
+		Suffix("ON CONFLICT ((lower(name))) DO NOTHING RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("could not build query: %w", err)
+	}
+
+	var id int
+	if err := r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrServiceNameExists
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
 	}
-	return r.AddService(ctx, name)
+	return id, nil
 }
 
-func (r *ServiceRepository) DeleteService(ctx context.Context, id int) error {
-	checkQuery, checkArgs, err := squirrel.Select("COUNT(*)").
-		From("subscription").
-		Where(squirrel.Eq{"service_id": id}).
+// GetOrCreateServiceIDs resolves many service names at once, creating the missing
+// services. The result maps every given name to its service id.
+func (r *ServiceRepository) GetOrCreateServiceIDs(ctx context.Context, names []string) (map[string]int, error) {
+	ids := make(map[string]int, len(names))
+	if len(names) == 0 {
+		return ids, nil
+	}
+
+	// Names that only differ in case or whitespace resolve to one service, created
+	// with the first of them.
+	keys := make([]string, 0, len(names))
+	firstNames := make(map[string]string, len(names))
+	for _, name := range names {
+		key := serviceKey(name)
+		if _, ok := firstNames[key]; ok {
+			continue
+		}
+		firstNames[key] = NormalizeServiceName(name)
+		keys = append(keys, key)
+	}
+
+	byKey, err := r.lookupServices(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := byKey[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		insertBuilder := squirrel.Insert("service").
+			Columns("name").
+			Suffix("ON CONFLICT ((lower(name))) DO NOTHING").
+			PlaceholderFormat(squirrel.Dollar)
+		for _, key := range missing {
+			insertBuilder = insertBuilder.Values(firstNames[key])
+		}
+
+		insertQuery, insertArgs, err := insertBuilder.ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("could not build query: %w", err)
+		}
+
+		if _, err := r.provider.Conn(ctx).ExecContext(ctx, insertQuery, insertArgs...); err != nil {
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		created, err := r.lookupServices(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for key, id := range created {
+			byKey[key] = id
+		}
+	}
+
+	for _, name := range names {
+		id, ok := byKey[serviceKey(name)]
+		if !ok {
+			return nil, fmt.Errorf("service %q was not resolved", name)
+		}
+		ids[name] = id
+	}
+
+	return ids, nil
+}
+
+// lookupServices maps the keys of existing service names and aliases to service ids.
+func (r *ServiceRepository) lookupServices(ctx context.Context, keys []string) (map[string]int, error) {
+	query, args, err := serviceLookupQuery(keys).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("could not build check query: %w", err)
+		return nil, fmt.Errorf("could not build query: %w", err)
 	}
 
-	var count int
-	if err := r.provider.GetConn().QueryRowContext(ctx, checkQuery, checkArgs...).Scan(&count); err != nil {
-		return err
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	ids := make(map[string]int, len(keys))
+	for rows.Next() {
+		var key string
+		var id int
+		if err := rows.Scan(&key, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids[key] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	if count > 0 {
-		return ErrServiceInUse
+	return ids, nil
+}
+
+// RenameService changes the name of a service. An alias of the service with the new
+// name is dropped, as it becomes the name itself.
+func (r *ServiceRepository) RenameService(ctx context.Context, id int, name string) error {
+	name = NormalizeServiceName(name)
+	if name == "" {
+		return errors.New("empty service name")
+	}
+
+	query, args, err := squirrel.Update("service").
+		Set("name", name).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
 	}
 
+	aliasQuery, aliasArgs, err := squirrel.Delete("service_alias").
+		Where(squirrel.Eq{"service_id": id, "lower(name)": serviceKey(name)}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	return r.provider.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.checkNotAlias(ctx, name, id); err != nil {
+			return err
+		}
+
+		result, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrServiceNameExists
+			}
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("result.RowsAffected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return ErrServiceNotFound
+		}
+
+		if _, err := r.provider.Conn(ctx).ExecContext(ctx, aliasQuery, aliasArgs...); err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteService deletes a service no subscription refers to, deleted subscriptions
+// included. The foreign key decides, so a subscription created concurrently cannot
+// slip between a check and the DELETE.
+func (r *ServiceRepository) DeleteService(ctx context.Context, id int) error {
 	query, args, err := squirrel.Delete("service").
 		Where(squirrel.Eq{"id": id}).
 		PlaceholderFormat(squirrel.Dollar).
@@ -146,9 +459,13 @@ func (r *ServiceRepository) DeleteService(ctx context.Context, id int) error {
 		return fmt.Errorf("could not build query: %w", err)
 	}
 
-	result, err := r.provider.GetConn().ExecContext(ctx, query, args...)
+	result, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...)
 	if err != nil {
-		return err
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return ErrServiceInUse
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
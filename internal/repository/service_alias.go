@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// AddServiceAlias adds another name under which the service is found. The alias must
+// not match the name or alias of any service.
+func (r *ServiceRepository) AddServiceAlias(ctx context.Context, serviceID int, alias string) error {
+	alias = NormalizeServiceName(alias)
+	if alias == "" {
+		return errors.New("empty service alias")
+	}
+
+	query, args, err := squirrel.Insert("service_alias").
+		Columns("service_id", "name").
+		Values(serviceID, alias).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	return r.provider.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := r.lockService(ctx, serviceID); err != nil {
+			return err
+		}
+
+		if _, err := r.GetServiceID(ctx, alias); err == nil {
+			return ErrServiceNameExists
+		} else if !errors.Is(err, ErrServiceNotFound) {
+			return err
+		}
+
+		if _, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrServiceNameExists
+			}
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *ServiceRepository) ListServiceAliases(ctx context.Context, serviceID int) ([]string, error) {
+	query, args, err := squirrel.Select("name").
+		From("service_alias").
+		Where(squirrel.Eq{"service_id": serviceID}).
+		OrderBy("name").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	aliases := []string{}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return aliases, nil
+}
+
+func (r *ServiceRepository) DeleteServiceAlias(ctx context.Context, serviceID int, alias string) error {
+	query, args, err := squirrel.Delete("service_alias").
+		Where(squirrel.Eq{"service_id": serviceID, "lower(name)": serviceKey(alias)}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	result, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrServiceAliasNotFound
+	}
+
+	return nil
+}
+
+// MergeServices moves every subscription and alias of the source service to the
+// target one and deletes the source, keeping its name as an alias of the target. Each
+// moved subscription gets a new version, a history record and an update event, as
+// with any other change of it. It returns the number of moved subscriptions.
+func (r *ServiceRepository) MergeServices(ctx context.Context, sourceID, targetID int, requestID string) (int64, error) {
+	var moved int64
+	err := r.provider.WithinTx(ctx, func(ctx context.Context) error {
+		// Locked in id order, so concurrent merges of the same pair do not deadlock.
+		first, second := min(sourceID, targetID), max(sourceID, targetID)
+		firstName, err := r.lockService(ctx, first)
+		if err != nil {
+			return err
+		}
+		secondName, err := r.lockService(ctx, second)
+		if err != nil {
+			return err
+		}
+		sourceName, targetName := firstName, secondName
+		if sourceID == second {
+			sourceName, targetName = secondName, firstName
+		}
+
+		moved, err = r.repointSubscriptions(ctx, sourceID, targetID, targetName, requestID)
+		if err != nil {
+			return err
+		}
+
+		steps := []squirrel.Sqlizer{
+			squirrel.Update("service_alias").
+				Set("service_id", targetID).
+				Where(squirrel.Eq{"service_id": sourceID}).
+				PlaceholderFormat(squirrel.Dollar),
+			squirrel.Delete("service").
+				Where(squirrel.Eq{"id": sourceID}).
+				PlaceholderFormat(squirrel.Dollar),
+			squirrel.Insert("service_alias").
+				Columns("service_id", "name").
+				Values(targetID, sourceName).
+				PlaceholderFormat(squirrel.Dollar),
+		}
+		for _, step := range steps {
+			query, args, err := step.ToSql()
+			if err != nil {
+				return fmt.Errorf("could not build query: %w", err)
+			}
+
+			if _, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("failed to execute query: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+// lockService locks the service row for the rest of the transaction and returns its
+// name.
+func (r *ServiceRepository) lockService(ctx context.Context, id int) (string, error) {
+	query, args, err := squirrel.Select("name").
+		From("service").
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("could not build query: %w", err)
+	}
+
+	var name string
+	if err := r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrServiceNotFound
+		}
+		return "", fmt.Errorf("failed to execute query: %w", err)
+	}
+	return name, nil
+}
+
+// repointSubscriptions moves the subscriptions of the source service, deleted ones
+// included, to the target service and records the change of each of them.
+func (r *ServiceRepository) repointSubscriptions(ctx context.Context, sourceID, targetID int, targetName, requestID string) (int64, error) {
+	tx := r.provider.Conn(ctx)
+	subscriptions := NewSubscriptionRepository(r.provider, r.logger)
+
+	moved, err := r.lockServiceSubscriptions(ctx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+
+	query, args, err := squirrel.Update("subscription").
+		Set("service_id", targetID).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"service_id": sourceID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return 0, ErrServiceMergeConflict
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	for _, before := range moved {
+		after := before
+		after.ServiceName = targetName
+		after.Version = before.Version + 1
+
+		if err := subscriptions.addHistory(ctx, tx, before.ID, HistoryActionUpdated, &before, &after, requestID); err != nil {
+			return 0, err
+		}
+		if err := subscriptions.addEvent(ctx, tx, uuid.New(), EventSubscriptionUpdated, &before, &after); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(moved)), nil
+}
+
+// lockServiceSubscriptions reads the subscriptions of the service, deleted ones
+// included, and locks their rows for the rest of the transaction.
+func (r *ServiceRepository) lockServiceSubscriptions(ctx context.Context, serviceID int) ([]Subscription, error) {
+	query, args, err := baseSubscriptionQuery().
+		Where(squirrel.Eq{"s.service_id": serviceID}).
+		OrderBy("s.id").
+		Suffix("FOR UPDATE OF s").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var subscriptions []Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return subscriptions, nil
+}
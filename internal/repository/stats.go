@@ -16,6 +16,9 @@ type GetTotalCostParams struct {
 	ServiceName *string
 	StartDate   *time.Time
 	EndDate     *time.Time
+	// IncludeDeleted also counts soft-deleted subscriptions, until the month they were
+	// deleted in.
+	IncludeDeleted bool
 }
 
 type SubscriptionCost struct {
@@ -25,11 +28,13 @@ type SubscriptionCost struct {
 	PriceRub    int
 	UserID      uuid.UUID
 	ServiceName string
+	// Prices are the price periods ordered by EffectiveFrom. Empty means PriceRub
+	// applies to every month.
+	Prices []PricePeriod
 }
 
 type TotalCostStats struct {
 	TotalCost          int
-	Subscriptions      []SubscriptionCost
 	StartDate          *time.Time
 	EndDate            *time.Time
 	UserID             *uuid.UUID
@@ -37,6 +42,63 @@ type TotalCostStats struct {
 	SubscriptionsCount int
 }
 
+type GroupByField string
+
+const (
+	GroupByService GroupByField = "service"
+	GroupByUser    GroupByField = "user"
+)
+
+type CostGroup struct {
+	ServiceName        *string
+	UserID             *uuid.UUID
+	TotalCost          int
+	SubscriptionsCount int
+}
+
+type GroupedCostStats struct {
+	TotalCost          int
+	SubscriptionsCount int
+	Groups             []CostGroup
+	GroupBy            []GroupByField
+	StartDate          *time.Time
+	EndDate            *time.Time
+	UserID             *uuid.UUID
+	ServiceName        *string
+}
+
+type MonthlyCost struct {
+	Month              time.Time
+	TotalCost          int
+	SubscriptionsCount int
+}
+
+type MonthlyCostStats struct {
+	TotalCost   int
+	Months      []MonthlyCost
+	StartDate   *time.Time
+	EndDate     *time.Time
+	UserID      *uuid.UUID
+	ServiceName *string
+}
+
+type ForecastMonth struct {
+	Month              time.Time
+	TotalCost          int
+	SubscriptionsCount int
+	Groups             []CostGroup
+}
+
+type ForecastStats struct {
+	TotalCost   int
+	Months      []ForecastMonth
+	GroupBy     []GroupByField
+	StartDate   time.Time
+	EndDate     time.Time
+	UserID      *uuid.UUID
+	ServiceName *string
+}
+
 type StatsRepository struct {
 	provider Provider
 	logger   Logger
@@ -49,52 +111,132 @@ func NewStatsRepository(provider Provider, logger Logger) *StatsRepository {
 	}
 }
 
+// GetTotalCost sums the cost of all matching subscriptions in PostgreSQL, so only
+// the aggregate leaves the database regardless of how many rows match.
 func (r *StatsRepository) GetTotalCost(ctx context.Context, p GetTotalCostParams) (TotalCostStats, error) {
-	baseQuery := squirrel.Select().
-		From("subscription s").
-		Join("service sv ON s.service_id = sv.id").
-		PlaceholderFormat(squirrel.Dollar)
+	query, args, err := costAggregateQuery(p).
+		Column("COALESCE(SUM(t.cost), 0)").
+		Column("COUNT(*)").
+		ToSql()
+	if err != nil {
+		return TotalCostStats{}, fmt.Errorf("could not build query: %w", err)
+	}
 
-	if p.UserID != nil {
-		baseQuery = baseQuery.Where(squirrel.Eq{"s.user_id": *p.UserID})
+	stats := TotalCostStats{
+		UserID:      p.UserID,
+		ServiceName: p.ServiceName,
+		StartDate:   p.StartDate,
+		EndDate:     p.EndDate,
 	}
 
-	if p.ServiceName != nil {
-		baseQuery = baseQuery.Where(squirrel.Eq{"sv.name": *p.ServiceName})
+	err = r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&stats.TotalCost, &stats.SubscriptionsCount)
+	if err != nil {
+		return TotalCostStats{}, fmt.Errorf("failed to execute query: %w", err)
 	}
 
-	if p.StartDate != nil && p.EndDate != nil {
-		baseQuery = baseQuery.Where(squirrel.LtOrEq{"s.start_date": *p.EndDate})
-		baseQuery = baseQuery.Where(squirrel.Or{
-			squirrel.Eq{"s.end_date": nil},
-			squirrel.GtOrEq{"s.end_date": *p.StartDate},
-		})
-	} else if p.StartDate != nil {
-		baseQuery = baseQuery.Where(squirrel.Or{
-			squirrel.Eq{"s.end_date": nil},
-			squirrel.GtOrEq{"s.end_date": *p.StartDate},
-		})
-	} else if p.EndDate != nil {
-		baseQuery = baseQuery.Where(squirrel.LtOrEq{"s.start_date": *p.EndDate})
+	return stats, nil
+}
+
+// GetGroupedCost aggregates the cost per group directly in PostgreSQL.
+func (r *StatsRepository) GetGroupedCost(ctx context.Context, p GetTotalCostParams, groupBy []GroupByField) ([]CostGroup, error) {
+	outer := costAggregateQuery(p)
+
+	var hasService, hasUser bool
+	for _, field := range groupBy {
+		switch field {
+		case GroupByService:
+			hasService = true
+		case GroupByUser:
+			hasUser = true
+		default:
+			return nil, fmt.Errorf("unknown group by field: %s", field)
+		}
+	}
+
+	if hasService {
+		outer = outer.Column("t.service_name").GroupBy("t.service_name")
+	} else {
+		outer = outer.Column("NULL::text")
+	}
+	if hasUser {
+		outer = outer.Column("t.user_id").GroupBy("t.user_id")
+	} else {
+		outer = outer.Column("NULL::uuid")
+	}
+
+	query, args, err := outer.
+		Column("COALESCE(SUM(t.cost), 0)").
+		Column("COUNT(*)").
+		OrderBy("3 DESC", "1", "2").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var groups []CostGroup
+	for rows.Next() {
+		var serviceName sql.NullString
+		var userID uuid.NullUUID
+		var group CostGroup
+
+		if err := rows.Scan(&serviceName, &userID, &group.TotalCost, &group.SubscriptionsCount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if serviceName.Valid {
+			group.ServiceName = &serviceName.String
+		}
+		if userID.Valid {
+			group.UserID = &userID.UUID
+		}
+
+		groups = append(groups, group)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
+	return groups, nil
+}
+
+// ListSubscriptionCosts loads every matching subscription. It is meant for per-month
+// breakdowns; totals should go through GetTotalCost.
+func (r *StatsRepository) ListSubscriptionCosts(ctx context.Context, p GetTotalCostParams) ([]SubscriptionCost, error) {
+	baseQuery := squirrel.Select().
+		From("subscription s").
+		Join("service sv ON s.service_id = sv.id").
+		PlaceholderFormat(squirrel.Dollar)
+
+	baseQuery = applyTotalCostFilters(baseQuery, p)
+
 	query, args, err := baseQuery.
 		Columns(
 			"s.id",
 			"s.start_date",
-			"s.end_date",
+			chargedEndSQL(p),
 			"s.price_rub",
 			"s.user_id",
 			"sv.name",
 		).
 		ToSql()
 	if err != nil {
-		return TotalCostStats{}, fmt.Errorf("could not build query: %w", err)
+		return nil, fmt.Errorf("could not build query: %w", err)
 	}
 
-	rows, err := r.provider.GetConn().QueryContext(ctx, query, args...)
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
-		return TotalCostStats{}, fmt.Errorf("failed to execute query: %w", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -113,7 +255,7 @@ func (r *StatsRepository) GetTotalCost(ctx context.Context, p GetTotalCostParams
 
 		err := rows.Scan(&id, &startDate, &endDate, &priceRub, &userID, &serviceName)
 		if err != nil {
-			return TotalCostStats{}, fmt.Errorf("failed to scan row: %w", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
 		var endDatePtr *time.Time
@@ -132,17 +274,172 @@ func (r *StatsRepository) GetTotalCost(ctx context.Context, p GetTotalCostParams
 	}
 
 	if err = rows.Err(); err != nil {
-		return TotalCostStats{}, fmt.Errorf("error iterating rows: %w", err)
+		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	stats := TotalCostStats{
-		Subscriptions:      subscriptions,
-		UserID:             p.UserID,
-		ServiceName:        p.ServiceName,
-		StartDate:          p.StartDate,
-		EndDate:            p.EndDate,
-		SubscriptionsCount: len(subscriptions),
+	prices, err := r.listPricePeriods(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Prices = prices[subscriptions[i].ID]
 	}
 
-	return stats, nil
+	return subscriptions, nil
+}
+
+// listPricePeriods loads the price periods of the subscriptions matching p, keyed by
+// subscription id.
+func (r *StatsRepository) listPricePeriods(ctx context.Context, p GetTotalCostParams) (map[int64][]PricePeriod, error) {
+	baseQuery := squirrel.Select("sp.subscription_id", "sp.effective_from", "sp.price_rub").
+		From("subscription_price sp").
+		Join("subscription s ON sp.subscription_id = s.id").
+		Join("service sv ON s.service_id = sv.id").
+		OrderBy("sp.subscription_id", "sp.effective_from").
+		PlaceholderFormat(squirrel.Dollar)
+
+	query, args, err := applyTotalCostFilters(baseQuery, p).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	prices := make(map[int64][]PricePeriod)
+	for rows.Next() {
+		var subscriptionID int64
+		var period PricePeriod
+		if err := rows.Scan(&subscriptionID, &period.EffectiveFrom, &period.PriceRub); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		prices[subscriptionID] = append(prices[subscriptionID], period)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return prices, nil
+}
+
+// pricePeriodsSQL turns the price entries of every subscription into closed month
+// ranges. The first entry also covers the months before it, so a subscription whose
+// start date was moved back is charged at its earliest known price.
+const pricePeriodsSQL = `SELECT sp.subscription_id, sp.price_rub,
+	CASE WHEN LAG(sp.effective_from) OVER w IS NULL THEN '-infinity'::date ELSE sp.effective_from END AS valid_from,
+	COALESCE((LEAD(sp.effective_from) OVER w - INTERVAL '1 month')::date, 'infinity'::date) AS valid_to
+FROM subscription_price sp
+WINDOW w AS (PARTITION BY sp.subscription_id ORDER BY sp.effective_from)`
+
+// costAggregateQuery selects the cost of every matching subscription within the
+// period (t.cost), ready to be aggregated. Each month is charged at the price in
+// effect for it; subscriptions without price entries fall back to price_rub.
+func costAggregateQuery(p GetTotalCostParams) squirrel.SelectBuilder {
+	lower, upper := intersectionBoundsSQL(p)
+
+	bounded := squirrel.Select("s.id", "s.price_rub", "s.user_id", "sv.name AS service_name").
+		Column(squirrel.Alias(lower, "lo")).
+		Column(squirrel.Alias(upper, "hi")).
+		From("subscription s").
+		Join("service sv ON s.service_id = sv.id")
+	bounded = applyTotalCostFilters(bounded, p)
+
+	pricedCost := "SELECT SUM(pp.price_rub * " +
+		chargedMonthsSQL("GREATEST(b.lo, pp.valid_from)", "LEAST(b.hi, pp.valid_to)") +
+		") FROM (" + pricePeriodsSQL + ") pp WHERE pp.subscription_id = b.id"
+
+	priced := squirrel.Select("b.user_id", "b.service_name").
+		Column("COALESCE(("+pricedCost+"), b.price_rub * "+chargedMonthsSQL("b.lo", "b.hi")+") AS cost").
+		FromSelect(bounded, "b")
+
+	return squirrel.Select().
+		FromSelect(priced, "t").
+		PlaceholderFormat(squirrel.Dollar)
+}
+
+// chargedEndSQL is the last charged month of a subscription, NULL when it is open-ended.
+// A deleted subscription is charged until the month it was deleted in.
+func chargedEndSQL(p GetTotalCostParams) string {
+	if p.IncludeDeleted {
+		return "LEAST(s.end_date, date_trunc('month', s.deleted_at)::date)"
+	}
+	return "s.end_date"
+}
+
+func applyTotalCostFilters(builder squirrel.SelectBuilder, p GetTotalCostParams) squirrel.SelectBuilder {
+	if p.IncludeDeleted {
+		// Subscriptions deleted before they started were never charged.
+		builder = builder.Where(squirrel.Or{
+			squirrel.Eq{"s.deleted_at": nil},
+			squirrel.Expr("date_trunc('month', s.deleted_at)::date >= s.start_date"),
+		})
+	} else {
+		builder = builder.Where(squirrel.Eq{"s.deleted_at": nil})
+	}
+
+	if p.UserID != nil {
+		builder = builder.Where(squirrel.Eq{"s.user_id": *p.UserID})
+	}
+
+	if p.ServiceName != nil {
+		builder = builder.Where(serviceNameIn("s.service_id", []string{*p.ServiceName}))
+	}
+
+	if p.StartDate != nil && p.EndDate != nil {
+		builder = builder.Where(squirrel.LtOrEq{"s.start_date": *p.EndDate})
+		builder = builder.Where(squirrel.Or{
+			squirrel.Eq{chargedEndSQL(p): nil},
+			squirrel.GtOrEq{chargedEndSQL(p): *p.StartDate},
+		})
+	} else if p.StartDate != nil {
+		builder = builder.Where(squirrel.Or{
+			squirrel.Eq{chargedEndSQL(p): nil},
+			squirrel.GtOrEq{chargedEndSQL(p): *p.StartDate},
+		})
+	} else if p.EndDate != nil {
+		builder = builder.Where(squirrel.LtOrEq{"s.start_date": *p.EndDate})
+	}
+
+	return builder
+}
+
+// intersectionBoundsSQL returns the first and the last charged month of a subscription
+// within the period. The bounds match StatsService.intersectionBounds: open-ended
+// subscriptions are charged until the end of the period, or until the current month
+// when the period has no end.
+func intersectionBoundsSQL(p GetTotalCostParams) (squirrel.Sqlizer, squirrel.Sqlizer) {
+	var lower, upper squirrel.Sqlizer
+
+	if p.StartDate != nil {
+		lower = squirrel.Expr("GREATEST(s.start_date, ?::date)", monthStart(*p.StartDate))
+	} else {
+		lower = squirrel.Expr("s.start_date")
+	}
+
+	if p.EndDate != nil {
+		// LEAST ignores NULL, so an open-ended subscription runs until the period end.
+		upper = squirrel.Expr("LEAST("+chargedEndSQL(p)+", ?::date)", monthStart(*p.EndDate))
+	} else {
+		upper = squirrel.Expr("COALESCE("+chargedEndSQL(p)+", ?::date)", monthStart(time.Now()))
+	}
+
+	return lower, upper
+}
+
+// chargedMonthsSQL counts the months from lo to hi inclusive, or 0 when hi is before lo.
+func chargedMonthsSQL(lo, hi string) string {
+	age := fmt.Sprintf("age(%s, %s)", hi, lo)
+	return fmt.Sprintf("GREATEST(0, (date_part('year', %[1]s) * 12 + date_part('month', %[1]s) + 1)::int)", age)
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
 }
@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"time"
 
+	"EffectiveMobile/pkg/postgres"
+
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
@@ -18,6 +20,8 @@ var (
 	ErrSubscriptionAlreadyExists = errors.New("subscription already exists")
 	ErrSubscriptionNotFound      = errors.New("subscription not found")
 	ErrSubscriptionNotCreated    = errors.New("subscription not created")
+	ErrVersionMismatch           = errors.New("subscription version mismatch")
+	ErrSubscriptionNotDeleted    = errors.New("subscription is not deleted")
 )
 
 type CreateSubscriptionParams struct {
@@ -26,6 +30,13 @@ type CreateSubscriptionParams struct {
 	PriceRub  int
 	StartDate time.Time
 	EndDate   *time.Time
+	// RequestID is stored in the change history.
+	RequestID string
+}
+
+type CreateSubscriptionResult struct {
+	ID  int64
+	Err error
 }
 
 type UpdateSubscriptionParams struct {
@@ -34,13 +45,54 @@ type UpdateSubscriptionParams struct {
 	PriceRub  *int
 	StartDate *time.Time
 	EndDate   *time.Time
+	// ClearEndDate sets end_date to NULL, EndDate is ignored then.
+	ClearEndDate bool
+	// PriceEffectiveFrom is the first month PriceRub applies to. Nil applies a changed
+	// price from the current month, moved into the lifetime of the subscription; the
+	// price history is kept when PriceRub equals the current price.
+	PriceEffectiveFrom *time.Time
+	// ResetPrices drops the price history and applies PriceRub to the whole lifetime
+	// of the subscription. PriceEffectiveFrom is ignored then.
+	ResetPrices bool
+	// Version is the version the caller expects the row to have. Nil skips the check.
+	Version *int
+	// RequestID is stored in the change history.
+	RequestID string
 }
 
 type ListSubscriptionsParams struct {
-	Limit       int
-	Offset      int
-	UserID      *uuid.UUID
-	ServiceName *string
+	Limit        int
+	Offset       int
+	UserIDs      []uuid.UUID
+	ServiceNames []string
+	PriceMin     *int
+	PriceMax     *int
+	// ActiveAt keeps subscriptions active in the given month.
+	ActiveAt *time.Time
+	// StartFrom, StartTo, EndFrom and EndTo are inclusive month bounds of start_date
+	// and end_date. End bounds never match subscriptions without end_date.
+	StartFrom  *time.Time
+	StartTo    *time.Time
+	EndFrom    *time.Time
+	EndTo      *time.Time
+	HasEndDate *bool
+	// SortBy defaults to the id. Equal keys are ordered by id in the same direction.
+	SortBy   SubscriptionSort
+	SortDesc bool
+	// IncludeDeleted also returns soft-deleted subscriptions.
+	IncludeDeleted bool
+	// After starts the page right after the cursor position; Offset is ignored then.
+	After *ListCursor
+	// SkipTotal skips the COUNT(*) query, SubscriptionPage.Total stays nil.
+	SkipTotal bool
+}
+
+type SubscriptionPage struct {
+	Subscriptions []Subscription
+	// Total is the number of subscriptions matching the filters, nil with SkipTotal.
+	Total *int
+	// NextCursor points at the next page, nil on the last page.
+	NextCursor *ListCursor
 }
 
 type Subscription struct {
@@ -50,6 +102,17 @@ type Subscription struct {
 	UserID      uuid.UUID
 	StartDate   time.Time
 	EndDate     *time.Time
+	Version     int
+	DeletedAt   *time.Time
+	// Prices is only filled by GetSubscription.
+	Prices []PricePeriod
+}
+
+// PricePeriod is the price of a subscription starting from EffectiveFrom until the
+// next period begins.
+type PricePeriod struct {
+	EffectiveFrom time.Time
+	PriceRub      int
 }
 
 type SubscriptionRepository struct {
@@ -65,6 +128,79 @@ func NewSubscriptionRepository(provider Provider, logger Logger) *SubscriptionRe
 }
 
 func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, p CreateSubscriptionParams) (int64, error) {
+	var id int64
+	err := r.withTx(ctx, func(ctx context.Context, tx postgres.DBTX) error {
+		var err error
+		id, err = r.createSubscription(ctx, tx, p)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CreateSubscriptions inserts many subscriptions and reports the outcome of each one
+// at the same index. When atomic is set, all of them are inserted in one transaction
+// that is rolled back if any item fails; the items that were fine then get
+// ErrSubscriptionNotCreated. Otherwise every item is committed on its own.
+func (r *SubscriptionRepository) CreateSubscriptions(ctx context.Context, items []CreateSubscriptionParams, atomic bool) ([]CreateSubscriptionResult, error) {
+	results := make([]CreateSubscriptionResult, len(items))
+
+	if !atomic {
+		for i, p := range items {
+			results[i].ID, results[i].Err = r.CreateSubscription(ctx, p)
+		}
+		return results, nil
+	}
+
+	failed := false
+	err := r.withTx(ctx, func(ctx context.Context, tx postgres.DBTX) error {
+		for i, p := range items {
+			// A savepoint per item keeps the transaction usable after a failed insert,
+			// so that every item still gets its own result.
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+				return fmt.Errorf("failed to create savepoint: %w", err)
+			}
+
+			id, err := r.createSubscription(ctx, tx, p)
+			if err != nil {
+				if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); rbErr != nil {
+					return fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+				}
+				results[i].Err = err
+				failed = true
+			} else {
+				results[i].ID = id
+			}
+
+			if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item"); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
+			}
+		}
+
+		if failed {
+			return ErrSubscriptionNotCreated
+		}
+		return nil
+	})
+	if err != nil && !(failed && errors.Is(err, ErrSubscriptionNotCreated)) {
+		return nil, err
+	}
+
+	if failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = CreateSubscriptionResult{Err: ErrSubscriptionNotCreated}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (r *SubscriptionRepository) createSubscription(ctx context.Context, tx postgres.DBTX, p CreateSubscriptionParams) (int64, error) {
 	query, args, err := squirrel.Insert("subscription").
 		Columns("user_id", "service_id", "price_rub", "start_date", "end_date").
 		Values(p.UserID, p.ServiceID, p.PriceRub, p.StartDate, p.EndDate).
@@ -76,7 +212,7 @@ func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, p Creat
 	}
 
 	var id int64
-	err = r.provider.GetConn().QueryRowContext(ctx, query, args...).Scan(&id)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
@@ -85,26 +221,40 @@ func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, p Creat
 		return 0, err
 	}
 
+	if err := r.resetPrices(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	after, err := r.getSubscriptionForUpdate(ctx, tx, id, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.addHistory(ctx, tx, id, HistoryActionCreated, nil, &after, p.RequestID); err != nil {
+		return 0, err
+	}
+
+	if err := r.addEvent(ctx, tx, uuid.New(), EventSubscriptionCreated, nil, &after); err != nil {
+		return 0, err
+	}
+
 	return id, nil
 }
 
-func (r *SubscriptionRepository) GetSubscription(ctx context.Context, id int64) (Subscription, error) {
-	query, args, err := baseSubscriptionQuery().
-		Where(squirrel.Eq{"s.id": id}).
-		ToSql()
+func (r *SubscriptionRepository) GetSubscription(ctx context.Context, id int64, includeDeleted bool) (Subscription, error) {
+	queryBuilder := baseSubscriptionQuery().
+		Where(squirrel.Eq{"s.id": id})
+
+	if !includeDeleted {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"s.deleted_at": nil})
+	}
+
+	query, args, err := queryBuilder.ToSql()
 	if err != nil {
 		return Subscription{}, fmt.Errorf("could not build query: %w", err)
 	}
 
-	var subscription Subscription
-	err = r.provider.GetConn().QueryRowContext(ctx, query, args...).Scan(
-		&subscription.ID,
-		&subscription.ServiceName,
-		&subscription.Price,
-		&subscription.UserID,
-		&subscription.StartDate,
-		&subscription.EndDate,
-	)
+	subscription, err := scanSubscription(r.provider.Conn(ctx).QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return Subscription{}, ErrSubscriptionNotFound
@@ -112,17 +262,30 @@ func (r *SubscriptionRepository) GetSubscription(ctx context.Context, id int64)
 		return Subscription{}, err
 	}
 
+	subscription.Prices, err = r.listPrices(ctx, id)
+	if err != nil {
+		return Subscription{}, err
+	}
+
 	return subscription, nil
 }
 
-func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, p UpdateSubscriptionParams) error {
+// GetSubscriptionForUpdate reads the subscription, without its prices, and locks its
+// row until the transaction of ctx ends.
+func (r *SubscriptionRepository) GetSubscriptionForUpdate(ctx context.Context, id int64) (Subscription, error) {
+	return r.getSubscriptionForUpdate(ctx, r.provider.Conn(ctx), id, false)
+}
+
+// UpdateSubscription applies p and returns the new version of the subscription. With
+// p.Version the row is only updated while it still has that version.
+func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, p UpdateSubscriptionParams) (int, error) {
 	queryBuilder := squirrel.Update("subscription")
 
 	if p.ServiceID != nil {
 		queryBuilder = queryBuilder.Set("service_id", *p.ServiceID)
 	}
 
-	if p.PriceRub != nil {
+	if p.PriceRub != nil && p.ResetPrices {
 		queryBuilder = queryBuilder.Set("price_rub", *p.PriceRub)
 	}
 
@@ -130,71 +293,378 @@ func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, p Updat
 		queryBuilder = queryBuilder.Set("start_date", *p.StartDate)
 	}
 
-	if p.EndDate != nil {
-		if p.EndDate.IsZero() {
-			queryBuilder = queryBuilder.Set("end_date", nil)
-		} else {
-			queryBuilder = queryBuilder.Set("end_date", *p.EndDate)
+	if p.ClearEndDate {
+		queryBuilder = queryBuilder.Set("end_date", nil)
+	} else if p.EndDate != nil {
+		queryBuilder = queryBuilder.Set("end_date", *p.EndDate)
+	}
+
+	queryBuilder = queryBuilder.
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": p.ID, "deleted_at": nil})
+
+	if p.Version != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"version": *p.Version})
+	}
+
+	query, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("could not build query: %w", err)
+	}
+
+	var version int
+	err = r.withTx(ctx, func(ctx context.Context, tx postgres.DBTX) error {
+		before, err := r.getSubscriptionForUpdate(ctx, tx, p.ID, false)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrSubscriptionAlreadyExists
+			}
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		if err := r.checkUpdated(ctx, tx, result, p.ID); err != nil {
+			return err
+		}
+
+		if p.PriceRub != nil {
+			switch {
+			case p.ResetPrices:
+				err = r.resetPrices(ctx, tx, p.ID)
+			case p.PriceEffectiveFrom != nil:
+				err = r.setPriceFrom(ctx, tx, p.ID, *p.PriceEffectiveFrom, *p.PriceRub)
+			case *p.PriceRub != before.Price:
+				err = r.setPriceFrom(ctx, tx, p.ID, defaultPriceEffectiveFrom(before, p, time.Now()), *p.PriceRub)
+			}
+			if err != nil {
+				return err
+			}
 		}
+
+		after, err := r.getSubscriptionForUpdate(ctx, tx, p.ID, false)
+		if err != nil {
+			return err
+		}
+		version = after.Version
+
+		if err := r.addHistory(ctx, tx, p.ID, HistoryActionUpdated, &before, &after, p.RequestID); err != nil {
+			return err
+		}
+
+		return r.addEvent(ctx, tx, uuid.New(), EventSubscriptionUpdated, &before, &after)
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	queryBuilder = queryBuilder.Where(squirrel.Eq{"id": p.ID})
+	return version, nil
+}
+
+// DeleteSubscription marks the subscription as deleted. The row is kept so that it
+// can be restored and still shows up with include_deleted.
+func (r *SubscriptionRepository) DeleteSubscription(ctx context.Context, id int64, version *int, requestID string) error {
+	queryBuilder := squirrel.Update("subscription").
+		Set("deleted_at", squirrel.Expr("now()")).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id, "deleted_at": nil})
+
+	if version != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"version": *version})
+	}
 
 	query, args, err := queryBuilder.PlaceholderFormat(squirrel.Dollar).ToSql()
 	if err != nil {
 		return fmt.Errorf("could not build query: %w", err)
 	}
 
-    result, err := r.provider.GetConn().ExecContext(ctx, query, args...)
-    if err != nil {
-        var pgErr *pgconn.PgError
-        if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-            return ErrSubscriptionAlreadyExists
-        }
-        return fmt.Errorf("failed to execute query: %w", err)
-    }
+	return r.withTx(ctx, func(ctx context.Context, tx postgres.DBTX) error {
+		before, err := r.getSubscriptionForUpdate(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		if err := r.checkUpdated(ctx, tx, result, id); err != nil {
+			return err
+		}
+
+		after, err := r.getSubscriptionForUpdate(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		if err := r.addHistory(ctx, tx, id, HistoryActionDeleted, &before, &after, requestID); err != nil {
+			return err
+		}
+
+		return r.addEvent(ctx, tx, uuid.New(), EventSubscriptionDeleted, &before, &after)
+	})
+}
+
+// RestoreSubscription clears the deletion mark of a soft-deleted subscription.
+func (r *SubscriptionRepository) RestoreSubscription(ctx context.Context, id int64, requestID string) error {
+	query, args, err := squirrel.Update("subscription").
+		Set("deleted_at", nil).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
 	if err != nil {
-		return fmt.Errorf("result.RowsAffected: %w", err)
+		return fmt.Errorf("could not build query: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return ErrSubscriptionNotFound
+	return r.withTx(ctx, func(ctx context.Context, tx postgres.DBTX) error {
+		before, err := r.getSubscriptionForUpdate(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		if before.DeletedAt == nil {
+			return ErrSubscriptionNotDeleted
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrSubscriptionAlreadyExists
+			}
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		after, err := r.getSubscriptionForUpdate(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		if err := r.addHistory(ctx, tx, id, HistoryActionRestored, &before, &after, requestID); err != nil {
+			return err
+		}
+
+		return r.addEvent(ctx, tx, uuid.New(), EventSubscriptionRestored, &before, &after)
+	})
+}
+
+// resetPrices replaces all price periods of the subscription with a single one that
+// starts with the subscription and uses its current price.
+func (r *SubscriptionRepository) resetPrices(ctx context.Context, tx postgres.DBTX, id int64) error {
+	deleteQuery, deleteArgs, err := squirrel.Delete("subscription_price").
+		Where(squirrel.Eq{"subscription_id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteQuery, deleteArgs...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	insertQuery, insertArgs, err := squirrel.Insert("subscription_price").
+		Columns("subscription_id", "effective_from", "price_rub").
+		Select(squirrel.Select("id", "start_date", "price_rub").
+			From("subscription").
+			Where(squirrel.Eq{"id": id})).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, insertQuery, insertArgs...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
 	}
 
 	return nil
 }
 
-func (r *SubscriptionRepository) DeleteSubscription(ctx context.Context, id int64) error {
-	query, args, err := squirrel.Delete("subscription").
-		Where(squirrel.Eq{"id": id}).
+// defaultPriceEffectiveFrom is the month a price change without PriceEffectiveFrom
+// applies from: the month of now, moved into the lifetime the subscription has after
+// the update.
+func defaultPriceEffectiveFrom(before Subscription, p UpdateSubscriptionParams, now time.Time) time.Time {
+	startDate, endDate := before.StartDate, before.EndDate
+	if p.StartDate != nil {
+		startDate = *p.StartDate
+	}
+	if p.ClearEndDate {
+		endDate = nil
+	} else if p.EndDate != nil {
+		endDate = p.EndDate
+	}
+
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if endDate != nil && month.After(*endDate) {
+		month = *endDate
+	}
+	if month.Before(startDate) {
+		month = startDate
+	}
+	return month
+}
+
+// setPriceFrom sets the price of the subscription starting from the given month and
+// keeps price_rub equal to the price of the latest period.
+func (r *SubscriptionRepository) setPriceFrom(ctx context.Context, tx postgres.DBTX, id int64, effectiveFrom time.Time, price int) error {
+	upsertQuery, upsertArgs, err := squirrel.Insert("subscription_price").
+		Columns("subscription_id", "effective_from", "price_rub").
+		Values(id, effectiveFrom, price).
+		Suffix("ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price_rub = EXCLUDED.price_rub").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("could not build query: %w", err)
 	}
 
-	result, err := r.provider.GetConn().ExecContext(ctx, query, args...)
+	if _, err := tx.ExecContext(ctx, upsertQuery, upsertArgs...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	syncQuery, syncArgs, err := squirrel.Update("subscription").
+		Set("price_rub", squirrel.Expr(
+			"(SELECT sp.price_rub FROM subscription_price sp WHERE sp.subscription_id = subscription.id ORDER BY sp.effective_from DESC LIMIT 1)",
+		)).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
 	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, syncQuery, syncArgs...); err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
 
+	return nil
+}
+
+func (r *SubscriptionRepository) listPrices(ctx context.Context, id int64) ([]PricePeriod, error) {
+	query, args, err := squirrel.Select("effective_from", "price_rub").
+		From("subscription_price").
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("effective_from").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var prices []PricePeriod
+	for rows.Next() {
+		var period PricePeriod
+		if err := rows.Scan(&period.EffectiveFrom, &period.PriceRub); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		prices = append(prices, period)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return prices, nil
+}
+
+// withTx runs fn in a transaction that is committed when fn succeeds and rolled
+// back otherwise. Within a transaction of the caller, fn joins it.
+func (r *SubscriptionRepository) withTx(ctx context.Context, fn func(ctx context.Context, tx postgres.DBTX) error) error {
+	return r.provider.WithinTx(ctx, func(ctx context.Context) error {
+		return fn(ctx, r.provider.Conn(ctx))
+	})
+}
+
+// checkUpdated tells why a conditional UPDATE of the subscription matched no row: the
+// subscription is gone, or it has another version than the caller expected.
+func (r *SubscriptionRepository) checkUpdated(ctx context.Context, tx postgres.DBTX, result sql.Result, id int64) error {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("result.RowsAffected: %w", err)
 	}
+	if rowsAffected > 0 {
+		return nil
+	}
 
-	if rowsAffected == 0 {
-		return ErrSubscriptionNotFound
+	query, args, err := squirrel.Select("1").
+		From("subscription").
+		Where(squirrel.Eq{"id": id, "deleted_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
 	}
 
-	return nil
+	var exists int
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSubscriptionNotFound
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	return ErrVersionMismatch
+}
+
+// getSubscriptionForUpdate reads the subscription inside tx and locks its row until
+// the transaction ends.
+func (r *SubscriptionRepository) getSubscriptionForUpdate(ctx context.Context, tx postgres.DBTX, id int64, includeDeleted bool) (Subscription, error) {
+	queryBuilder := baseSubscriptionQuery().
+		Where(squirrel.Eq{"s.id": id}).
+		Suffix("FOR UPDATE OF s")
+
+	if !includeDeleted {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"s.deleted_at": nil})
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return Subscription{}, fmt.Errorf("could not build query: %w", err)
+	}
+
+	subscription, err := scanSubscription(tx.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Subscription{}, ErrSubscriptionNotFound
+		}
+		return Subscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func scanSubscription(row interface{ Scan(dest ...any) error }) (Subscription, error) {
+	var subscription Subscription
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.ServiceName,
+		&subscription.Price,
+		&subscription.UserID,
+		&subscription.StartDate,
+		&subscription.EndDate,
+		&subscription.Version,
+		&subscription.DeletedAt,
+	)
+	return subscription, err
 }
 
 func baseSubscriptionQuery() squirrel.SelectBuilder {
 	return squirrel.Select(
-		"s.id", "sv.name", "s.price_rub", "s.user_id", "s.start_date", "s.end_date",
+		"s.id", "sv.name", "s.price_rub", "s.user_id", "s.start_date", "s.end_date", "s.version", "s.deleted_at",
 	).
 		From("subscription s").
 		Join("service sv ON s.service_id = sv.id").
@@ -202,51 +672,182 @@ func baseSubscriptionQuery() squirrel.SelectBuilder {
 }
 
 func (r *SubscriptionRepository) applySubscriptionFilters(builder squirrel.SelectBuilder, p ListSubscriptionsParams) squirrel.SelectBuilder {
-	if p.UserID != nil {
-		builder = builder.Where(squirrel.Eq{"s.user_id": *p.UserID})
+	if len(p.UserIDs) > 0 {
+		builder = builder.Where(squirrel.Eq{"s.user_id": p.UserIDs})
+	}
+	if len(p.ServiceNames) > 0 {
+		builder = builder.Where(serviceNameIn("s.service_id", p.ServiceNames))
+	}
+	if p.PriceMin != nil {
+		builder = builder.Where(squirrel.GtOrEq{"s.price_rub": *p.PriceMin})
+	}
+	if p.PriceMax != nil {
+		builder = builder.Where(squirrel.LtOrEq{"s.price_rub": *p.PriceMax})
+	}
+	if p.ActiveAt != nil {
+		builder = builder.Where(squirrel.LtOrEq{"s.start_date": *p.ActiveAt}).
+			Where(squirrel.Or{
+				squirrel.Eq{"s.end_date": nil},
+				squirrel.GtOrEq{"s.end_date": *p.ActiveAt},
+			})
+	}
+	if p.StartFrom != nil {
+		builder = builder.Where(squirrel.GtOrEq{"s.start_date": *p.StartFrom})
+	}
+	if p.StartTo != nil {
+		builder = builder.Where(squirrel.LtOrEq{"s.start_date": *p.StartTo})
+	}
+	if p.EndFrom != nil {
+		builder = builder.Where(squirrel.GtOrEq{"s.end_date": *p.EndFrom})
+	}
+	if p.EndTo != nil {
+		builder = builder.Where(squirrel.LtOrEq{"s.end_date": *p.EndTo})
 	}
-	if p.ServiceName != nil {
-		builder = builder.Where(squirrel.Eq{"sv.name": *p.ServiceName})
+	if p.HasEndDate != nil {
+		if *p.HasEndDate {
+			builder = builder.Where(squirrel.NotEq{"s.end_date": nil})
+		} else {
+			builder = builder.Where(squirrel.Eq{"s.end_date": nil})
+		}
+	}
+	if !p.IncludeDeleted {
+		builder = builder.Where(squirrel.Eq{"s.deleted_at": nil})
 	}
 	return builder
 }
 
-func (r *SubscriptionRepository) ListSubscriptions(ctx context.Context, p ListSubscriptionsParams) ([]Subscription, int, error) {
-	countBuilder := squirrel.Select("COUNT(*)").
-		From("subscription s").
-		Join("service sv ON s.service_id = sv.id").
-		PlaceholderFormat(squirrel.Dollar)
-	countBuilder = r.applySubscriptionFilters(countBuilder, p)
+func (r *SubscriptionRepository) ListSubscriptions(ctx context.Context, p ListSubscriptionsParams) (*SubscriptionPage, error) {
+	page := &SubscriptionPage{}
 
-	countQuery, countArgs, err := countBuilder.ToSql()
-	if err != nil {
-		return nil, 0, fmt.Errorf("could not build count query: %w", err)
-	}
+	if !p.SkipTotal {
+		countBuilder := squirrel.Select("COUNT(*)").
+			From("subscription s").
+			Join("service sv ON s.service_id = sv.id").
+			PlaceholderFormat(squirrel.Dollar)
+		countBuilder = r.applySubscriptionFilters(countBuilder, p)
+
+		countQuery, countArgs, err := countBuilder.ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("could not build count query: %w", err)
+		}
 
-	var total int
-	if err := r.provider.GetConn().QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("failed to get count: %w", err)
+		var total int
+		if err := r.provider.Conn(ctx).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, fmt.Errorf("failed to get count: %w", err)
+		}
+		page.Total = &total
 	}
 
 	dataBuilder := baseSubscriptionQuery()
 	dataBuilder = r.applySubscriptionFilters(dataBuilder, p)
 
+	dataBuilder, err := applySort(dataBuilder, p)
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	// One extra row tells whether there is a next page.
 	if p.Limit > 0 {
-		dataBuilder = dataBuilder.Limit(uint64(p.Limit))
+		dataBuilder = dataBuilder.Limit(uint64(p.Limit) + 1)
 	}
-	if p.Offset >= 0 {
+	if p.After == nil && p.Offset >= 0 {
 		dataBuilder = dataBuilder.Offset(uint64(p.Offset))
 	}
-	dataBuilder = dataBuilder.OrderBy("s.id")
 
 	query, args, err := dataBuilder.ToSql()
 	if err != nil {
-		return nil, 0, fmt.Errorf("could not build query: %w", err)
+		return nil, fmt.Errorf("could not build query: %w", err)
 	}
 
-	rows, err := r.provider.GetConn().QueryContext(ctx, query, args...)
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to execute query: %w", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		page.Subscriptions = append(page.Subscriptions, subscription)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if p.Limit > 0 && len(page.Subscriptions) > p.Limit {
+		page.Subscriptions = page.Subscriptions[:p.Limit]
+		page.NextCursor = cursorAfter(page.Subscriptions[p.Limit-1], p)
+	}
+
+	return page, nil
+}
+
+// StreamSubscriptions calls fn for every subscription matching p in the order of
+// p.SortBy without loading them all into memory. Limit, Offset and After are ignored.
+// Iteration stops at the first error returned by fn.
+func (r *SubscriptionRepository) StreamSubscriptions(ctx context.Context, p ListSubscriptionsParams, fn func(Subscription) error) error {
+	p.After = nil
+	builder, err := applySort(r.applySubscriptionFilters(baseSubscriptionQuery(), p), p)
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := fn(subscription); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// ListEndingSoon returns the active subscriptions whose end_date is within [from, until]
+// and that were not marked with MarkEndingSoonNotified for that end_date yet.
+func (r *SubscriptionRepository) ListEndingSoon(ctx context.Context, from, until time.Time) ([]Subscription, error) {
+	query, args, err := baseSubscriptionQuery().
+		Where(squirrel.Eq{"s.deleted_at": nil}).
+		Where(squirrel.GtOrEq{"s.end_date": from}).
+		Where(squirrel.LtOrEq{"s.end_date": until}).
+		Where("NOT EXISTS (SELECT 1 FROM subscription_ending_notice n WHERE n.subscription_id = s.id AND n.end_date = s.end_date)").
+		OrderBy("s.id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -256,20 +857,66 @@ func (r *SubscriptionRepository) ListSubscriptions(ctx context.Context, p ListSu
 
 	var subscriptions []Subscription
 	for rows.Next() {
-		var subscription Subscription
-		err = rows.Scan(
-			&subscription.ID,
-			&subscription.ServiceName,
-			&subscription.Price,
-			&subscription.UserID,
-			&subscription.StartDate,
-			&subscription.EndDate,
-		)
+		subscription, err := scanSubscription(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		subscriptions = append(subscriptions, subscription)
 	}
 
-	return subscriptions, total, nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// MarkEndingSoonNotified records that the ending of the subscription at endDate was
+// announced and queues the subscription.ending_soon event. It reports false when the
+// ending was already announced; a new end_date is announced again. The event id is
+// derived from the subscription and end_date, so receivers can drop duplicates.
+func (r *SubscriptionRepository) MarkEndingSoonNotified(ctx context.Context, id int64, endDate time.Time) (bool, error) {
+	query, args, err := squirrel.Insert("subscription_ending_notice").
+		Columns("subscription_id", "end_date").
+		Values(id, endDate).
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("could not build query: %w", err)
+	}
+
+	marked := false
+	err = r.withTx(ctx, func(ctx context.Context, tx postgres.DBTX) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("result.RowsAffected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return nil
+		}
+
+		subscription, err := r.getSubscriptionForUpdate(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		eventID := uuid.NewSHA1(uuid.NameSpaceURL, fmt.Appendf(nil, "%s/%d/%s", EventSubscriptionEndingSoon, id, endDate.Format(time.DateOnly)))
+		if err := r.addEvent(ctx, tx, eventID, EventSubscriptionEndingSoon, nil, &subscription); err != nil {
+			return err
+		}
+
+		marked = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return marked, nil
 }
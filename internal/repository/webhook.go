@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+var ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+
+type WebhookEndpoint struct {
+	ID     int64
+	URL    string
+	Secret string
+	// Events the endpoint receives, empty means all of them.
+	Events    []string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is an outbox entry claimed for delivery to one endpoint.
+type WebhookDelivery struct {
+	ID         int64
+	EndpointID int64
+	EventID    uuid.UUID
+	EventType  string
+	Payload    []byte
+	// Attempt is the number of the current attempt, starting from 1.
+	Attempt int
+	URL     string
+	Secret  string
+}
+
+// WebhookDeliveryAttempt is the outcome of one delivery attempt. A failed attempt
+// without NextAttemptAt gives up on the delivery.
+type WebhookDeliveryAttempt struct {
+	Delivery      WebhookDelivery
+	StatusCode    *int
+	Error         *string
+	Duration      time.Duration
+	Delivered     bool
+	NextAttemptAt *time.Time
+}
+
+type WebhookDeliveryLogRecord struct {
+	ID         int64
+	OutboxID   int64
+	EndpointID int64
+	EventID    uuid.UUID
+	EventType  string
+	Attempt    int
+	StatusCode *int
+	Error      *string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+type WebhookRepository struct {
+	provider Provider
+	logger   Logger
+}
+
+func NewWebhookRepository(provider Provider, logger Logger) *WebhookRepository {
+	return &WebhookRepository{
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint WebhookEndpoint) (int64, error) {
+	events := endpoint.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	query, args, err := squirrel.Insert("webhook_endpoint").
+		Columns("url", "secret", "events").
+		Values(endpoint.URL, endpoint.Secret, events).
+		Suffix("RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("could not build query: %w", err)
+	}
+
+	var id int64
+	if err := r.provider.Conn(ctx).QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error) {
+	query, args, err := baseWebhookEndpointQuery().
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return WebhookEndpoint{}, fmt.Errorf("could not build query: %w", err)
+	}
+
+	endpoint, err := scanWebhookEndpoint(r.provider.Conn(ctx).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return WebhookEndpoint{}, ErrWebhookEndpointNotFound
+		}
+		return WebhookEndpoint{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]WebhookEndpoint, error) {
+	query, args, err := baseWebhookEndpointQuery().
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var endpoints []WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id int64) error {
+	query, args, err := squirrel.Delete("webhook_endpoint").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	result, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWebhookEndpointNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveryLog returns the latest delivery attempts to an endpoint, newest first.
+func (r *WebhookRepository) ListDeliveryLog(ctx context.Context, endpointID int64, limit int) ([]WebhookDeliveryLogRecord, error) {
+	query, args, err := squirrel.Select(
+		"id", "outbox_id", "endpoint_id", "event_id", "event_type", "attempt", "status_code", "error", "duration_ms", "created_at",
+	).
+		From("webhook_delivery_log").
+		Where(squirrel.Eq{"endpoint_id": endpointID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var records []WebhookDeliveryLogRecord
+	for rows.Next() {
+		var record WebhookDeliveryLogRecord
+		var durationMs int64
+		err := rows.Scan(
+			&record.ID,
+			&record.OutboxID,
+			&record.EndpointID,
+			&record.EventID,
+			&record.EventType,
+			&record.Attempt,
+			&record.StatusCode,
+			&record.Error,
+			&durationMs,
+			&record.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		record.Duration = time.Duration(durationMs) * time.Millisecond
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return records, nil
+}
+
+// EnqueueEvent adds a pending delivery of the event for every endpoint subscribed to
+// its type. An event that was already queued for an endpoint is not queued again.
+func (r *WebhookRepository) EnqueueEvent(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	endpoints := squirrel.Select("e.id").
+		Column("?", event.ID).
+		Column("?", event.Type).
+		Column("?::jsonb", string(payload)).
+		From("webhook_endpoint e").
+		Where(squirrel.Or{
+			squirrel.Expr("cardinality(e.events) = 0"),
+			squirrel.Expr("? = ANY(e.events)", event.Type),
+		})
+
+	query, args, err := squirrel.Insert("webhook_outbox").
+		Columns("endpoint_id", "event_id", "event_type", "payload").
+		Select(endpoints).
+		Suffix("ON CONFLICT (endpoint_id, event_id) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := r.provider.Conn(ctx).ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries picks up to limit pending deliveries that are due and leases them
+// for the given time: a delivery that is not recorded before the lease expires, e.g.
+// because the process stopped, is claimed again. Concurrent workers skip each other's
+// rows.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	const query = `UPDATE webhook_outbox o
+SET attempts = o.attempts + 1,
+	next_attempt_at = now() + $2 * INTERVAL '1 millisecond'
+FROM webhook_endpoint e
+WHERE e.id = o.endpoint_id
+	AND o.id IN (
+		SELECT id FROM webhook_outbox
+		WHERE status = 'pending' AND next_attempt_at <= now()
+		ORDER BY next_attempt_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING o.id, o.endpoint_id, o.event_id, o.event_type, o.payload, o.attempts, e.url, e.secret`
+
+	rows, err := r.provider.Conn(ctx).QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Warn("rows.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		err := rows.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Payload, &d.Attempt, &d.URL, &d.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordDeliveryAttempt writes the attempt to the delivery log and moves the outbox
+// entry to delivered, back to pending until NextAttemptAt, or to failed.
+func (r *WebhookRepository) RecordDeliveryAttempt(ctx context.Context, a WebhookDeliveryAttempt) error {
+	logQuery, logArgs, err := squirrel.Insert("webhook_delivery_log").
+		Columns("outbox_id", "endpoint_id", "event_id", "event_type", "attempt", "status_code", "error", "duration_ms").
+		Values(
+			a.Delivery.ID,
+			a.Delivery.EndpointID,
+			a.Delivery.EventID,
+			a.Delivery.EventType,
+			a.Delivery.Attempt,
+			a.StatusCode,
+			a.Error,
+			a.Duration.Milliseconds(),
+		).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	update := squirrel.Update("webhook_outbox").
+		Set("last_error", a.Error).
+		Where(squirrel.Eq{"id": a.Delivery.ID})
+	switch {
+	case a.Delivered:
+		update = update.Set("status", "delivered").Set("delivered_at", squirrel.Expr("now()"))
+	case a.NextAttemptAt != nil:
+		update = update.Set("next_attempt_at", *a.NextAttemptAt)
+	default:
+		update = update.Set("status", "failed")
+	}
+
+	updateQuery, updateArgs, err := update.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	return r.provider.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.provider.Conn(ctx)
+
+		if _, err := tx.ExecContext(ctx, logQuery, logArgs...); err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, updateQuery, updateArgs...); err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func baseWebhookEndpointQuery() squirrel.SelectBuilder {
+	return squirrel.Select("id", "url", "secret", "array_to_json(events)", "created_at").
+		From("webhook_endpoint").
+		PlaceholderFormat(squirrel.Dollar)
+}
+
+func scanWebhookEndpoint(row interface{ Scan(dest ...any) error }) (WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+	var events []byte
+	if err := row.Scan(&endpoint.ID, &endpoint.URL, &endpoint.Secret, &events, &endpoint.CreatedAt); err != nil {
+		return WebhookEndpoint{}, err
+	}
+	if err := json.Unmarshal(events, &endpoint.Events); err != nil {
+		return WebhookEndpoint{}, fmt.Errorf("failed to unmarshal events: %w", err)
+	}
+	return endpoint, nil
+}
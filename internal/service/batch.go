@@ -0,0 +1,125 @@
+package service
+
+import (
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+type CreateSubscriptionInput struct {
+	ServiceName string
+	Price       int
+	UserID      uuid.UUID
+	StartDate   string
+	EndDate     string
+}
+
+// BatchResult is the outcome of one item of a batch: the new subscription id or the
+// reason it was not created.
+type BatchResult struct {
+	ID  int64
+	Err error
+}
+
+// CreateSubscriptions creates many subscriptions at once. When atomic is set, either
+// every item is created or none: a single invalid item leaves the others with
+// repository.ErrSubscriptionNotCreated, and service names of all items are resolved in
+// a single pass. Otherwise every item is created in its own transaction together with
+// its new service, so a failed item leaves no service behind.
+func (s *SubscriptionService) CreateSubscriptions(ctx context.Context, items []CreateSubscriptionInput, atomic bool) ([]BatchResult, error) {
+	const op = "service.subscription.CreateSubscriptions"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	results := make([]BatchResult, len(items))
+	params := make([]repository.CreateSubscriptionParams, len(items))
+	names := make([]string, 0, len(items))
+	requestID := middleware.GetReqID(ctx)
+
+	invalid := false
+	for i, item := range items {
+		name := repository.NormalizeServiceName(item.ServiceName)
+		p, err := s.createParams(name, item.Price, item.UserID, item.StartDate, item.EndDate)
+		if err != nil {
+			results[i].Err = err
+			invalid = true
+			continue
+		}
+		p.RequestID = requestID
+		params[i] = p
+		names = append(names, name)
+	}
+
+	if invalid && atomic {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = repository.ErrSubscriptionNotCreated
+			}
+		}
+		return results, nil
+	}
+
+	if len(names) == 0 {
+		return results, nil
+	}
+
+	if !atomic {
+		for i, item := range items {
+			if results[i].Err != nil {
+				continue
+			}
+			results[i].ID, results[i].Err = s.createWithService(ctx, params[i], repository.NormalizeServiceName(item.ServiceName))
+		}
+		return results, nil
+	}
+
+	var created []repository.CreateSubscriptionResult
+	indexes := make([]int, 0, len(names))
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		serviceIDs, err := s.serviceRepo.GetOrCreateServiceIDs(ctx, names)
+		if err != nil {
+			span.RecordError(err)
+			log.Error("get or create services failed", slog.String("err", err.Error()))
+			return err
+		}
+
+		pending := make([]repository.CreateSubscriptionParams, 0, len(names))
+		for i, item := range items {
+			if results[i].Err != nil {
+				continue
+			}
+			params[i].ServiceID = serviceIDs[repository.NormalizeServiceName(item.ServiceName)]
+			pending = append(pending, params[i])
+			indexes = append(indexes, i)
+		}
+
+		created, err = s.subscriptionRepo.CreateSubscriptions(ctx, pending, true)
+		if err != nil {
+			span.RecordError(err)
+			log.Error("create subscriptions failed", slog.String("err", err.Error()))
+			return err
+		}
+
+		// The created items and new services are rolled back with the failed ones.
+		if slices.ContainsFunc(created, func(res repository.CreateSubscriptionResult) bool { return res.Err != nil }) {
+			return repository.ErrSubscriptionNotCreated
+		}
+		return nil
+	})
+	if err != nil && !(created != nil && errors.Is(err, repository.ErrSubscriptionNotCreated)) {
+		return nil, err
+	}
+
+	for j, res := range created {
+		results[indexes[j]] = BatchResult{ID: res.ID, Err: res.Err}
+	}
+
+	return results, nil
+}
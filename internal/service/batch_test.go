@@ -0,0 +1,113 @@
+package service
+
+import (
+	"EffectiveMobile/internal/repository"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *SubscriptionServiceSuite) TestCreateSubscriptions_PerItem() {
+	subscriptionService, txCtx := s.inTx()
+	userID := uuid.New()
+	items := []CreateSubscriptionInput{
+		{ServiceName: "Netflix", Price: 500, UserID: userID, StartDate: "01-2024"},
+		{ServiceName: "Spotify", Price: 200, UserID: userID, StartDate: "bad"},
+		{ServiceName: " Netflix ", Price: 300, UserID: userID, StartDate: "02-2024"},
+	}
+
+	// Every item resolves its service in its own transaction.
+	s.serviceRepo.EXPECT().
+		GetOrCreateServiceID(txCtx, "Netflix").
+		Return(1, nil).
+		Times(2)
+
+	gomock.InOrder(
+		s.subscriptionRepo.EXPECT().
+			CreateSubscription(txCtx, gomock.Any()).
+			DoAndReturn(func(_ any, p repository.CreateSubscriptionParams) (int64, error) {
+				s.Equal(1, p.ServiceID)
+				s.Equal(500, p.PriceRub)
+				return 10, nil
+			}),
+		s.subscriptionRepo.EXPECT().
+			CreateSubscription(txCtx, gomock.Any()).
+			Return(int64(0), repository.ErrSubscriptionAlreadyExists),
+	)
+
+	results, err := subscriptionService.CreateSubscriptions(s.ctx, items, false)
+
+	s.NoError(err)
+	s.Require().Len(results, 3)
+	s.Equal(int64(10), results[0].ID)
+	s.ErrorIs(results[1].Err, ErrValidation)
+	s.ErrorIs(results[2].Err, repository.ErrSubscriptionAlreadyExists)
+}
+
+func (s *SubscriptionServiceSuite) TestCreateSubscriptions_PerItemServiceFails() {
+	subscriptionService, txCtx := s.inTx()
+	userID := uuid.New()
+	items := []CreateSubscriptionInput{
+		{ServiceName: "Netflix", Price: 500, UserID: userID, StartDate: "01-2024"},
+		{ServiceName: "Okko", Price: 300, UserID: userID, StartDate: "01-2024"},
+	}
+
+	s.serviceRepo.EXPECT().
+		GetOrCreateServiceID(txCtx, "Netflix").
+		Return(0, errors.New("db down"))
+	s.serviceRepo.EXPECT().
+		GetOrCreateServiceID(txCtx, "Okko").
+		Return(2, nil)
+	s.subscriptionRepo.EXPECT().
+		CreateSubscription(txCtx, gomock.Any()).
+		Return(int64(11), nil)
+
+	results, err := subscriptionService.CreateSubscriptions(s.ctx, items, false)
+
+	s.NoError(err)
+	s.Require().Len(results, 2)
+	s.Error(results[0].Err)
+	s.Equal(int64(11), results[1].ID)
+}
+
+func (s *SubscriptionServiceSuite) TestCreateSubscriptions_AtomicWithInvalidItem() {
+	userID := uuid.New()
+	items := []CreateSubscriptionInput{
+		{ServiceName: "Netflix", Price: 500, UserID: userID, StartDate: "01-2024"},
+		{ServiceName: "Spotify", Price: 200, UserID: userID, StartDate: "05-2024", EndDate: "01-2024"},
+	}
+
+	results, err := s.subscriptionService.CreateSubscriptions(s.ctx, items, true)
+
+	s.NoError(err)
+	s.Require().Len(results, 2)
+	s.ErrorIs(results[0].Err, repository.ErrSubscriptionNotCreated)
+	s.ErrorIs(results[1].Err, ErrValidation)
+}
+
+func (s *SubscriptionServiceSuite) TestCreateSubscriptions_AtomicRollsBackOnFailedItem() {
+	subscriptionService, txCtx := s.inTx()
+	userID := uuid.New()
+	items := []CreateSubscriptionInput{
+		{ServiceName: "Netflix", Price: 500, UserID: userID, StartDate: "01-2024"},
+		{ServiceName: "Okko", Price: 300, UserID: userID, StartDate: "01-2024"},
+	}
+
+	s.serviceRepo.EXPECT().
+		GetOrCreateServiceIDs(txCtx, []string{"Netflix", "Okko"}).
+		Return(map[string]int{"Netflix": 1, "Okko": 2}, nil)
+	s.subscriptionRepo.EXPECT().
+		CreateSubscriptions(txCtx, gomock.Any(), true).
+		Return([]repository.CreateSubscriptionResult{
+			{Err: repository.ErrSubscriptionNotCreated},
+			{Err: repository.ErrSubscriptionAlreadyExists},
+		}, nil)
+
+	results, err := subscriptionService.CreateSubscriptions(s.ctx, items, true)
+
+	s.NoError(err)
+	s.Require().Len(results, 2)
+	s.ErrorIs(results[0].Err, repository.ErrSubscriptionNotCreated)
+	s.ErrorIs(results[1].Err, repository.ErrSubscriptionAlreadyExists)
+}
@@ -0,0 +1,157 @@
+package service
+
+//go:generate mockgen -destination=budget_mock.go -source=budget.go -package=service
+
+import (
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BudgetRepository interface {
+	GetBudget(ctx context.Context, userID uuid.UUID) (*repository.Budget, error)
+	CreateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error
+	UpdateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error
+	DeleteBudget(ctx context.Context, userID uuid.UUID) error
+}
+
+// MonthlyCostCalculator is implemented by StatsService, so budgets are checked against
+// the same per-month cost as the stats endpoints.
+type MonthlyCostCalculator interface {
+	GetMonthlyCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.MonthlyCostStats, error)
+}
+
+type BudgetService struct {
+	budgetRepo BudgetRepository
+	costs      MonthlyCostCalculator
+	log        *slog.Logger
+}
+
+func NewBudgetService(budgetRepo BudgetRepository, costs MonthlyCostCalculator, log *slog.Logger) *BudgetService {
+	return &BudgetService{
+		budgetRepo: budgetRepo,
+		costs:      costs,
+		log:        log,
+	}
+}
+
+func (s *BudgetService) GetBudget(ctx context.Context, userID uuid.UUID) (*repository.Budget, error) {
+	const op = "service.budget.GetBudget"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	budget, err := s.budgetRepo.GetBudget(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get budget failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (s *BudgetService) CreateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	const op = "service.budget.CreateBudget"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if monthlyLimit < 0 {
+		return fmt.Errorf("%w: monthly limit cannot be negative", ErrValidation)
+	}
+
+	if err := s.budgetRepo.CreateBudget(ctx, userID, monthlyLimit); err != nil {
+		span.RecordError(err)
+		log.Error("create budget failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *BudgetService) UpdateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	const op = "service.budget.UpdateBudget"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if monthlyLimit < 0 {
+		return fmt.Errorf("%w: monthly limit cannot be negative", ErrValidation)
+	}
+
+	if err := s.budgetRepo.UpdateBudget(ctx, userID, monthlyLimit); err != nil {
+		span.RecordError(err)
+		log.Error("update budget failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *BudgetService) DeleteBudget(ctx context.Context, userID uuid.UUID) error {
+	const op = "service.budget.DeleteBudget"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.budgetRepo.DeleteBudget(ctx, userID); err != nil {
+		span.RecordError(err)
+		log.Error("delete budget failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// GetBudgetStatus compares the monthly limit with the cost of every month of the
+// period. Without a period the months run from the user's first subscription to the
+// current month, like in StatsService.GetTotalCost.
+func (s *BudgetService) GetBudgetStatus(ctx context.Context, userID uuid.UUID, startDate, endDate *time.Time) (*repository.BudgetStatus, error) {
+	const op = "service.budget.GetBudgetStatus"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	budget, err := s.budgetRepo.GetBudget(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get budget failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	monthly, err := s.costs.GetMonthlyCost(ctx, &userID, nil, startDate, endDate, false)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get monthly cost failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	status := &repository.BudgetStatus{
+		Budget:      *budget,
+		TotalCost:   monthly.TotalCost,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		MonthsCount: len(monthly.Months),
+	}
+	if monthly.StartDate != nil {
+		status.StartDate = monthly.StartDate
+		status.EndDate = monthly.EndDate
+	}
+
+	for _, m := range monthly.Months {
+		if m.TotalCost > budget.MonthlyLimit {
+			status.OverBudgetMonths = append(status.OverBudgetMonths, repository.BudgetMonth{
+				MonthlyCost: m,
+				Overspend:   m.TotalCost - budget.MonthlyLimit,
+			})
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: budget.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=budget_mock.go -source=budget.go -package=service
+//
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	repository "EffectiveMobile/internal/repository"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBudgetRepository is a mock of BudgetRepository interface.
+type MockBudgetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBudgetRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockBudgetRepositoryMockRecorder is the mock recorder for MockBudgetRepository.
+type MockBudgetRepositoryMockRecorder struct {
+	mock *MockBudgetRepository
+}
+
+// NewMockBudgetRepository creates a new mock instance.
+func NewMockBudgetRepository(ctrl *gomock.Controller) *MockBudgetRepository {
+	mock := &MockBudgetRepository{ctrl: ctrl}
+	mock.recorder = &MockBudgetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBudgetRepository) EXPECT() *MockBudgetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateBudget mocks base method.
+func (m *MockBudgetRepository) CreateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBudget", ctx, userID, monthlyLimit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBudget indicates an expected call of CreateBudget.
+func (mr *MockBudgetRepositoryMockRecorder) CreateBudget(ctx, userID, monthlyLimit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBudget", reflect.TypeOf((*MockBudgetRepository)(nil).CreateBudget), ctx, userID, monthlyLimit)
+}
+
+// DeleteBudget mocks base method.
+func (m *MockBudgetRepository) DeleteBudget(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBudget", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBudget indicates an expected call of DeleteBudget.
+func (mr *MockBudgetRepositoryMockRecorder) DeleteBudget(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBudget", reflect.TypeOf((*MockBudgetRepository)(nil).DeleteBudget), ctx, userID)
+}
+
+// GetBudget mocks base method.
+func (m *MockBudgetRepository) GetBudget(ctx context.Context, userID uuid.UUID) (*repository.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudget", ctx, userID)
+	ret0, _ := ret[0].(*repository.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudget indicates an expected call of GetBudget.
+func (mr *MockBudgetRepositoryMockRecorder) GetBudget(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudget", reflect.TypeOf((*MockBudgetRepository)(nil).GetBudget), ctx, userID)
+}
+
+// UpdateBudget mocks base method.
+func (m *MockBudgetRepository) UpdateBudget(ctx context.Context, userID uuid.UUID, monthlyLimit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBudget", ctx, userID, monthlyLimit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBudget indicates an expected call of UpdateBudget.
+func (mr *MockBudgetRepositoryMockRecorder) UpdateBudget(ctx, userID, monthlyLimit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBudget", reflect.TypeOf((*MockBudgetRepository)(nil).UpdateBudget), ctx, userID, monthlyLimit)
+}
+
+// MockMonthlyCostCalculator is a mock of MonthlyCostCalculator interface.
+type MockMonthlyCostCalculator struct {
+	ctrl     *gomock.Controller
+	recorder *MockMonthlyCostCalculatorMockRecorder
+	isgomock struct{}
+}
+
+// MockMonthlyCostCalculatorMockRecorder is the mock recorder for MockMonthlyCostCalculator.
+type MockMonthlyCostCalculatorMockRecorder struct {
+	mock *MockMonthlyCostCalculator
+}
+
+// NewMockMonthlyCostCalculator creates a new mock instance.
+func NewMockMonthlyCostCalculator(ctrl *gomock.Controller) *MockMonthlyCostCalculator {
+	mock := &MockMonthlyCostCalculator{ctrl: ctrl}
+	mock.recorder = &MockMonthlyCostCalculatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMonthlyCostCalculator) EXPECT() *MockMonthlyCostCalculatorMockRecorder {
+	return m.recorder
+}
+
+// GetMonthlyCost mocks base method.
+func (m *MockMonthlyCostCalculator) GetMonthlyCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.MonthlyCostStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMonthlyCost", ctx, userID, serviceName, startDate, endDate, includeDeleted)
+	ret0, _ := ret[0].(*repository.MonthlyCostStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMonthlyCost indicates an expected call of GetMonthlyCost.
+func (mr *MockMonthlyCostCalculatorMockRecorder) GetMonthlyCost(ctx, userID, serviceName, startDate, endDate, includeDeleted any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMonthlyCost", reflect.TypeOf((*MockMonthlyCostCalculator)(nil).GetMonthlyCost), ctx, userID, serviceName, startDate, endDate, includeDeleted)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type BudgetServiceSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	budgetRepo    *MockBudgetRepository
+	costs         *MockMonthlyCostCalculator
+	budgetService *BudgetService
+	logger        *slog.Logger
+	ctx           context.Context
+}
+
+func TestBudgetService(t *testing.T) {
+	suite.Run(t, &BudgetServiceSuite{})
+}
+
+func (s *BudgetServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.budgetRepo = NewMockBudgetRepository(s.ctrl)
+	s.costs = NewMockMonthlyCostCalculator(s.ctrl)
+
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	s.ctx = context.Background()
+
+	s.budgetService = NewBudgetService(s.budgetRepo, s.costs, s.logger)
+}
+
+func (s *BudgetServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *BudgetServiceSuite) TestCreateBudget_NegativeLimit() {
+	err := s.budgetService.CreateBudget(s.ctx, uuid.New(), -1)
+
+	s.ErrorIs(err, ErrValidation)
+}
+
+func (s *BudgetServiceSuite) TestUpdateBudget_NotFound() {
+	userID := uuid.New()
+
+	s.budgetRepo.EXPECT().
+		UpdateBudget(s.ctx, userID, 1000).
+		Return(repository.ErrBudgetNotFound)
+
+	err := s.budgetService.UpdateBudget(s.ctx, userID, 1000)
+
+	s.ErrorIs(err, repository.ErrBudgetNotFound)
+}
+
+func (s *BudgetServiceSuite) TestGetBudgetStatus_OverBudgetMonths() {
+	userID := uuid.New()
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	s.budgetRepo.EXPECT().
+		GetBudget(s.ctx, userID).
+		Return(&repository.Budget{UserID: userID, MonthlyLimit: 1000}, nil)
+
+	s.costs.EXPECT().
+		GetMonthlyCost(s.ctx, &userID, nil, &startDate, &endDate, false).
+		Return(&repository.MonthlyCostStats{
+			TotalCost: 3300,
+			Months: []repository.MonthlyCost{
+				{Month: startDate, TotalCost: 800, SubscriptionsCount: 1},
+				{Month: startDate.AddDate(0, 1, 0), TotalCost: 1000, SubscriptionsCount: 2},
+				{Month: endDate, TotalCost: 1500, SubscriptionsCount: 3},
+			},
+			StartDate: &startDate,
+			EndDate:   &endDate,
+		}, nil)
+
+	status, err := s.budgetService.GetBudgetStatus(s.ctx, userID, &startDate, &endDate)
+	s.Require().NoError(err)
+
+	s.Equal(1000, status.Budget.MonthlyLimit)
+	s.Equal(3300, status.TotalCost)
+	s.Equal(3, status.MonthsCount)
+	s.Equal([]repository.BudgetMonth{
+		{MonthlyCost: repository.MonthlyCost{Month: endDate, TotalCost: 1500, SubscriptionsCount: 3}, Overspend: 500},
+	}, status.OverBudgetMonths)
+}
+
+func (s *BudgetServiceSuite) TestGetBudgetStatus_NoSubscriptions() {
+	userID := uuid.New()
+
+	s.budgetRepo.EXPECT().
+		GetBudget(s.ctx, userID).
+		Return(&repository.Budget{UserID: userID, MonthlyLimit: 0}, nil)
+	s.costs.EXPECT().
+		GetMonthlyCost(s.ctx, &userID, nil, nil, nil, false).
+		Return(&repository.MonthlyCostStats{}, nil)
+
+	status, err := s.budgetService.GetBudgetStatus(s.ctx, userID, nil, nil)
+	s.Require().NoError(err)
+
+	s.Zero(status.MonthsCount)
+	s.Empty(status.OverBudgetMonths)
+	s.Nil(status.StartDate)
+}
+
+func (s *BudgetServiceSuite) TestGetBudgetStatus_BudgetNotFound() {
+	userID := uuid.New()
+
+	s.budgetRepo.EXPECT().
+		GetBudget(s.ctx, userID).
+		Return(nil, repository.ErrBudgetNotFound)
+
+	status, err := s.budgetService.GetBudgetStatus(s.ctx, userID, nil, nil)
+
+	s.ErrorIs(err, repository.ErrBudgetNotFound)
+	s.Nil(status)
+}
+
+func (s *BudgetServiceSuite) TestGetBudgetStatus_CostError() {
+	userID := uuid.New()
+
+	s.budgetRepo.EXPECT().
+		GetBudget(s.ctx, userID).
+		Return(&repository.Budget{UserID: userID, MonthlyLimit: 1000}, nil)
+	s.costs.EXPECT().
+		GetMonthlyCost(s.ctx, &userID, nil, nil, nil, false).
+		Return(nil, errors.New("database error"))
+
+	status, err := s.budgetService.GetBudgetStatus(s.ctx, userID, nil, nil)
+
+	s.Error(err)
+	s.Nil(status)
+}
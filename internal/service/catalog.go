@@ -0,0 +1,205 @@
+package service
+
+import (
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type CatalogService struct {
+	serviceRepo ServicesRepository
+	log         *slog.Logger
+}
+
+func NewCatalogService(serviceRepo ServicesRepository, log *slog.Logger) *CatalogService {
+	return &CatalogService{
+		serviceRepo: serviceRepo,
+		log:         log,
+	}
+}
+
+func (s *CatalogService) ListServices(ctx context.Context) ([]repository.Service, error) {
+	const op = "service.catalog.ListServices"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	services, err := s.serviceRepo.ListServices(ctx)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("list services failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return services, nil
+}
+
+func (s *CatalogService) GetService(ctx context.Context, id int) (*repository.Service, error) {
+	const op = "service.catalog.GetService"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	name, err := s.serviceRepo.GetServiceName(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get service failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return &repository.Service{ID: id, Name: name}, nil
+}
+
+func (s *CatalogService) CreateService(ctx context.Context, name string) (int, error) {
+	const op = "service.catalog.CreateService"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	name = repository.NormalizeServiceName(name)
+	if name == "" {
+		return 0, fmt.Errorf("%w: service name is required", ErrValidation)
+	}
+
+	id, err := s.serviceRepo.AddService(ctx, name)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("add service failed", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *CatalogService) RenameService(ctx context.Context, id int, name string) error {
+	const op = "service.catalog.RenameService"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if id <= 0 {
+		return fmt.Errorf("%w: invalid service id", ErrValidation)
+	}
+
+	name = repository.NormalizeServiceName(name)
+	if name == "" {
+		return fmt.Errorf("%w: service name cannot be empty", ErrValidation)
+	}
+
+	if err := s.serviceRepo.RenameService(ctx, id, name); err != nil {
+		span.RecordError(err)
+		log.Error("rename service failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *CatalogService) DeleteService(ctx context.Context, id int) error {
+	const op = "service.catalog.DeleteService"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.serviceRepo.DeleteService(ctx, id); err != nil {
+		span.RecordError(err)
+		log.Error("delete service failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *CatalogService) ListAliases(ctx context.Context, id int) ([]string, error) {
+	const op = "service.catalog.ListAliases"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if _, err := s.serviceRepo.GetServiceName(ctx, id); err != nil {
+		span.RecordError(err)
+		log.Error("get service failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	aliases, err := s.serviceRepo.ListServiceAliases(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("list aliases failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+// AddAlias makes the service also resolve by alias when subscriptions are created,
+// updated or filtered.
+func (s *CatalogService) AddAlias(ctx context.Context, id int, alias string) error {
+	const op = "service.catalog.AddAlias"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if id <= 0 {
+		return fmt.Errorf("%w: invalid service id", ErrValidation)
+	}
+
+	alias = repository.NormalizeServiceName(alias)
+	if alias == "" {
+		return fmt.Errorf("%w: alias cannot be empty", ErrValidation)
+	}
+
+	if err := s.serviceRepo.AddServiceAlias(ctx, id, alias); err != nil {
+		span.RecordError(err)
+		log.Error("add alias failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *CatalogService) DeleteAlias(ctx context.Context, id int, alias string) error {
+	const op = "service.catalog.DeleteAlias"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.serviceRepo.DeleteServiceAlias(ctx, id, alias); err != nil {
+		span.RecordError(err)
+		log.Error("delete alias failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// MergeServices moves all subscriptions of the source service to the target one and
+// deletes the source. Its name becomes an alias of the target, so subscriptions are
+// still found by it.
+func (s *CatalogService) MergeServices(ctx context.Context, sourceID, targetID int) (int64, error) {
+	const op = "service.catalog.MergeServices"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if sourceID <= 0 || targetID <= 0 {
+		return 0, fmt.Errorf("%w: invalid service id", ErrValidation)
+	}
+
+	if sourceID == targetID {
+		return 0, fmt.Errorf("%w: a service cannot be merged into itself", ErrValidation)
+	}
+
+	moved, err := s.serviceRepo.MergeServices(ctx, sourceID, targetID, middleware.GetReqID(ctx))
+	if err != nil {
+		span.RecordError(err)
+		log.Error("merge services failed", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	return moved, nil
+}
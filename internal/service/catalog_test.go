@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type CatalogServiceSuite struct {
+	suite.Suite
+
+	ctrl           *gomock.Controller
+	serviceRepo    *MockServicesRepository
+	catalogService *CatalogService
+	logger         *slog.Logger
+	ctx            context.Context
+}
+
+func TestCatalogService(t *testing.T) {
+	suite.Run(t, &CatalogServiceSuite{})
+}
+
+func (s *CatalogServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.serviceRepo = NewMockServicesRepository(s.ctrl)
+
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	s.ctx = context.Background()
+
+	s.catalogService = NewCatalogService(s.serviceRepo, s.logger)
+}
+
+func (s *CatalogServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *CatalogServiceSuite) TestListServices_Success() {
+	expected := []repository.Service{
+		{ID: 1, Name: "Netflix"},
+		{ID: 2, Name: "Spotify"},
+	}
+
+	s.serviceRepo.EXPECT().
+		ListServices(s.ctx).
+		Return(expected, nil)
+
+	result, err := s.catalogService.ListServices(s.ctx)
+
+	s.NoError(err)
+	s.Equal(expected, result)
+}
+
+func (s *CatalogServiceSuite) TestGetService_NotFound() {
+	s.serviceRepo.EXPECT().
+		GetServiceName(s.ctx, 7).
+		Return("", repository.ErrServiceNotFound)
+
+	result, err := s.catalogService.GetService(s.ctx, 7)
+
+	s.Nil(result)
+	s.ErrorIs(err, repository.ErrServiceNotFound)
+}
+
+func (s *CatalogServiceSuite) TestCreateService_TrimsName() {
+	s.serviceRepo.EXPECT().
+		AddService(s.ctx, "Netflix").
+		Return(3, nil)
+
+	id, err := s.catalogService.CreateService(s.ctx, "  Netflix ")
+
+	s.NoError(err)
+	s.Equal(3, id)
+}
+
+func (s *CatalogServiceSuite) TestCreateService_EmptyName() {
+	id, err := s.catalogService.CreateService(s.ctx, "   ")
+
+	s.ErrorIs(err, ErrValidation)
+	s.Zero(id)
+}
+
+func (s *CatalogServiceSuite) TestRenameService_Conflict() {
+	s.serviceRepo.EXPECT().
+		RenameService(s.ctx, 1, "Netflix").
+		Return(repository.ErrServiceNameExists)
+
+	err := s.catalogService.RenameService(s.ctx, 1, "Netflix")
+
+	s.ErrorIs(err, repository.ErrServiceNameExists)
+}
+
+func (s *CatalogServiceSuite) TestRenameService_InvalidID() {
+	err := s.catalogService.RenameService(s.ctx, 0, "Netflix")
+
+	s.ErrorIs(err, ErrValidation)
+}
+
+func (s *CatalogServiceSuite) TestDeleteService_InUse() {
+	s.serviceRepo.EXPECT().
+		DeleteService(s.ctx, 1).
+		Return(repository.ErrServiceInUse)
+
+	err := s.catalogService.DeleteService(s.ctx, 1)
+
+	s.ErrorIs(err, repository.ErrServiceInUse)
+}
+
+func (s *CatalogServiceSuite) TestDeleteService_RepositoryError() {
+	repoErr := errors.New("database error")
+
+	s.serviceRepo.EXPECT().
+		DeleteService(s.ctx, 1).
+		Return(repoErr)
+
+	err := s.catalogService.DeleteService(s.ctx, 1)
+
+	s.Equal(repoErr, err)
+}
+
+func (s *CatalogServiceSuite) TestCreateService_NormalizesInnerWhitespace() {
+	s.serviceRepo.EXPECT().
+		AddService(s.ctx, "YouTube Premium").
+		Return(5, nil)
+
+	id, err := s.catalogService.CreateService(s.ctx, " YouTube \t  Premium ")
+
+	s.NoError(err)
+	s.Equal(5, id)
+}
+
+func (s *CatalogServiceSuite) TestAddAlias_NormalizesName() {
+	s.serviceRepo.EXPECT().
+		AddServiceAlias(s.ctx, 5, "YT Premium").
+		Return(nil)
+
+	err := s.catalogService.AddAlias(s.ctx, 5, "  YT   Premium")
+
+	s.NoError(err)
+}
+
+func (s *CatalogServiceSuite) TestAddAlias_EmptyName() {
+	err := s.catalogService.AddAlias(s.ctx, 5, "  ")
+
+	s.ErrorIs(err, ErrValidation)
+}
+
+func (s *CatalogServiceSuite) TestListAliases_ServiceNotFound() {
+	s.serviceRepo.EXPECT().
+		GetServiceName(s.ctx, 7).
+		Return("", repository.ErrServiceNotFound)
+
+	aliases, err := s.catalogService.ListAliases(s.ctx, 7)
+
+	s.Nil(aliases)
+	s.ErrorIs(err, repository.ErrServiceNotFound)
+}
+
+func (s *CatalogServiceSuite) TestMergeServices_Success() {
+	s.serviceRepo.EXPECT().
+		MergeServices(s.ctx, 2, 1, "").
+		Return(int64(3), nil)
+
+	moved, err := s.catalogService.MergeServices(s.ctx, 2, 1)
+
+	s.NoError(err)
+	s.Equal(int64(3), moved)
+}
+
+func (s *CatalogServiceSuite) TestMergeServices_IntoItself() {
+	moved, err := s.catalogService.MergeServices(s.ctx, 2, 2)
+
+	s.ErrorIs(err, ErrValidation)
+	s.Zero(moved)
+}
+
+func (s *CatalogServiceSuite) TestMergeServices_Conflict() {
+	s.serviceRepo.EXPECT().
+		MergeServices(s.ctx, 2, 1, "").
+		Return(int64(0), repository.ErrServiceMergeConflict)
+
+	_, err := s.catalogService.MergeServices(s.ctx, 2, 1)
+
+	s.ErrorIs(err, repository.ErrServiceMergeConflict)
+}
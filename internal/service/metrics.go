@@ -0,0 +1,67 @@
+package service
+
+import (
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/metrics"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// BusinessMetrics exposes subscription gauges. They are computed from the database on
+// every scrape, so they are never stale.
+type BusinessMetrics struct {
+	statsRepo StatsRepository
+	log       *slog.Logger
+}
+
+func NewBusinessMetrics(statsRepo StatsRepository, log *slog.Logger) *BusinessMetrics {
+	return &BusinessMetrics{
+		statsRepo: statsRepo,
+		log:       log,
+	}
+}
+
+// Collect reports the subscriptions active in the current month, in total and per
+// service, and the monthly recurring revenue: what they are charged for the month.
+func (m *BusinessMetrics) Collect(ctx context.Context) ([]metrics.Family, error) {
+	const op = "service.metrics.Collect"
+	log := m.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	month := monthStart(time.Now())
+	groups, err := m.statsRepo.GetGroupedCost(ctx, repository.GetTotalCostParams{
+		StartDate: &month,
+		EndDate:   &month,
+	}, []repository.GroupByField{repository.GroupByService})
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get grouped cost failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	byService := metrics.Family{
+		Name: "subscriptions_active_by_service",
+		Help: "Number of subscriptions active in the current month per service.",
+		Type: metrics.Gauge,
+	}
+	var active, revenue int
+	for _, group := range groups {
+		active += group.SubscriptionsCount
+		revenue += group.TotalCost
+
+		byService.Samples = append(byService.Samples, metrics.Sample{
+			Name:   byService.Name,
+			Labels: []metrics.Label{{Name: "service", Value: *group.ServiceName}},
+			Value:  float64(group.SubscriptionsCount),
+		})
+	}
+
+	return []metrics.Family{
+		metrics.NewGauge("subscriptions_active", "Number of subscriptions active in the current month.", float64(active)),
+		byService,
+		metrics.NewGauge("subscriptions_monthly_recurring_revenue_rub", "Cost of the active subscriptions for the current month, in rubles.", float64(revenue)),
+	}, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/metrics"
+
+	"go.uber.org/mock/gomock"
+)
+
+func (s *StatsServiceSuite) TestBusinessMetrics_Collect() {
+	netflix, okko := "Netflix", "Okko"
+	month := monthStart(time.Now())
+
+	s.statsRepo.EXPECT().
+		GetGroupedCost(s.ctx, repository.GetTotalCostParams{
+			StartDate: &month,
+			EndDate:   &month,
+		}, []repository.GroupByField{repository.GroupByService}).
+		Return([]repository.CostGroup{
+			{ServiceName: &netflix, TotalCost: 1200, SubscriptionsCount: 3},
+			{ServiceName: &okko, TotalCost: 300, SubscriptionsCount: 1},
+		}, nil)
+
+	families, err := NewBusinessMetrics(s.statsRepo, s.logger).Collect(s.ctx)
+
+	s.NoError(err)
+	s.Require().Len(families, 3)
+	s.Equal(metrics.NewGauge("subscriptions_active", families[0].Help, 4), families[0])
+	s.Equal([]metrics.Sample{
+		{Name: "subscriptions_active_by_service", Labels: []metrics.Label{{Name: "service", Value: "Netflix"}}, Value: 3},
+		{Name: "subscriptions_active_by_service", Labels: []metrics.Label{{Name: "service", Value: "Okko"}}, Value: 1},
+	}, families[1].Samples)
+	s.Equal(metrics.NewGauge("subscriptions_monthly_recurring_revenue_rub", families[2].Help, 1500), families[2])
+}
+
+func (s *StatsServiceSuite) TestBusinessMetrics_RepositoryError() {
+	s.statsRepo.EXPECT().
+		GetGroupedCost(s.ctx, gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("database is down"))
+
+	_, err := NewBusinessMetrics(s.statsRepo, s.logger).Collect(s.ctx)
+
+	s.Error(err)
+}
@@ -4,9 +4,11 @@ package service
 
 import (
 	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +16,8 @@ import (
 
 type StatsRepository interface {
 	GetTotalCost(ctx context.Context, p repository.GetTotalCostParams) (repository.TotalCostStats, error)
+	GetGroupedCost(ctx context.Context, p repository.GetTotalCostParams, groupBy []repository.GroupByField) ([]repository.CostGroup, error)
+	ListSubscriptionCosts(ctx context.Context, p repository.GetTotalCostParams) ([]repository.SubscriptionCost, error)
 }
 
 type StatsService struct {
@@ -28,25 +32,223 @@ func NewStatsService(statsRepo StatsRepository, log *slog.Logger) *StatsService
 	}
 }
 
-func (s *StatsService) GetTotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time) (*repository.TotalCostStats, error) {
+func (s *StatsService) GetTotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.TotalCostStats, error) {
 	const op = "service.stats.GetTotalCost"
 	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	stats, err := s.statsRepo.GetTotalCost(ctx, repository.GetTotalCostParams{
+		UserID:         userID,
+		ServiceName:    serviceName,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get total cost failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func (s *StatsService) GetGroupedCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, groupBy []repository.GroupByField, includeDeleted bool) (*repository.GroupedCostStats, error) {
+	const op = "service.stats.GetGroupedCost"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	groups, err := s.statsRepo.GetGroupedCost(ctx, repository.GetTotalCostParams{
+		UserID:         userID,
+		ServiceName:    serviceName,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		IncludeDeleted: includeDeleted,
+	}, groupBy)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get grouped cost failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	stats := &repository.GroupedCostStats{
+		Groups:      groups,
+		GroupBy:     groupBy,
 		UserID:      userID,
 		ServiceName: serviceName,
 		StartDate:   startDate,
 		EndDate:     endDate,
+	}
+	for _, g := range groups {
+		stats.TotalCost += g.TotalCost
+		stats.SubscriptionsCount += g.SubscriptionsCount
+	}
+
+	return stats, nil
+}
+
+func (s *StatsService) GetMonthlyCost(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate *time.Time, includeDeleted bool) (*repository.MonthlyCostStats, error) {
+	const op = "service.stats.GetMonthlyCost"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	subscriptions, err := s.statsRepo.ListSubscriptionCosts(ctx, repository.GetTotalCostParams{
+		UserID:         userID,
+		ServiceName:    serviceName,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		IncludeDeleted: includeDeleted,
 	})
 	if err != nil {
-		log.Error("get total cost failed", slog.String("err", err.Error()))
+		span.RecordError(err)
+		log.Error("get monthly cost failed", slog.String("err", err.Error()))
 		return nil, err
 	}
 
-	totalCost := s.calculateTotalCost(stats.Subscriptions, startDate, endDate)
-	stats.TotalCost = totalCost
+	months := s.calculateMonthlyCost(subscriptions, startDate, endDate)
 
-	return &stats, nil
+	monthly := &repository.MonthlyCostStats{
+		Months:      months,
+		UserID:      userID,
+		ServiceName: serviceName,
+	}
+	for _, m := range months {
+		monthly.TotalCost += m.TotalCost
+	}
+	if len(months) > 0 {
+		first, last := months[0].Month, months[len(months)-1].Month
+		monthly.StartDate = &first
+		monthly.EndDate = &last
+	}
+
+	return monthly, nil
+}
+
+// GetForecast projects the cost of the current month and the months-1 following ones,
+// split into groups per service and/or user. Open-ended subscriptions are charged
+// until the end of the forecast, the others until their end_date.
+func (s *StatsService) GetForecast(ctx context.Context, userID *uuid.UUID, serviceName *string, months int, groupBy []repository.GroupByField) (*repository.ForecastStats, error) {
+	const op = "service.stats.GetForecast"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	startDate := monthStart(time.Now())
+	endDate := startDate.AddDate(0, months-1, 0)
+
+	subscriptions, err := s.statsRepo.ListSubscriptionCosts(ctx, repository.GetTotalCostParams{
+		UserID:      userID,
+		ServiceName: serviceName,
+		StartDate:   &startDate,
+		EndDate:     &endDate,
+	})
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get forecast failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	forecast := &repository.ForecastStats{
+		GroupBy:     groupBy,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		UserID:      userID,
+		ServiceName: serviceName,
+	}
+	for _, m := range s.calculateMonthlyCost(subscriptions, &startDate, &endDate) {
+		forecast.Months = append(forecast.Months, repository.ForecastMonth{
+			Month:              m.Month,
+			TotalCost:          m.TotalCost,
+			SubscriptionsCount: m.SubscriptionsCount,
+		})
+		forecast.TotalCost += m.TotalCost
+	}
+
+	for _, group := range s.groupSubscriptionCosts(subscriptions, groupBy) {
+		for i, m := range s.calculateMonthlyCost(group.subscriptions, &startDate, &endDate) {
+			if m.SubscriptionsCount == 0 {
+				continue
+			}
+			cost := group.CostGroup
+			cost.TotalCost = m.TotalCost
+			cost.SubscriptionsCount = m.SubscriptionsCount
+			forecast.Months[i].Groups = append(forecast.Months[i].Groups, cost)
+		}
+	}
+
+	for i := range forecast.Months {
+		sortCostGroups(forecast.Months[i].Groups)
+	}
+
+	return forecast, nil
+}
+
+type subscriptionCostGroup struct {
+	repository.CostGroup
+	subscriptions []repository.SubscriptionCost
+}
+
+// groupSubscriptionCosts splits subscriptions by the groupBy fields, keeping the order
+// in which the groups first appear.
+func (s *StatsService) groupSubscriptionCosts(subscriptions []repository.SubscriptionCost, groupBy []repository.GroupByField) []*subscriptionCostGroup {
+	type groupKey struct {
+		serviceName string
+		userID      uuid.UUID
+	}
+
+	var groups []*subscriptionCostGroup
+	index := make(map[groupKey]*subscriptionCostGroup)
+
+	for _, sub := range subscriptions {
+		var key groupKey
+		for _, field := range groupBy {
+			switch field {
+			case repository.GroupByService:
+				key.serviceName = sub.ServiceName
+			case repository.GroupByUser:
+				key.userID = sub.UserID
+			}
+		}
+
+		group, ok := index[key]
+		if !ok {
+			group = &subscriptionCostGroup{}
+			for _, field := range groupBy {
+				switch field {
+				case repository.GroupByService:
+					group.ServiceName = &sub.ServiceName
+				case repository.GroupByUser:
+					group.UserID = &sub.UserID
+				}
+			}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.subscriptions = append(group.subscriptions, sub)
+	}
+
+	return groups
+}
+
+// sortCostGroups orders groups like StatsRepository.GetGroupedCost: by cost descending,
+// then by service name and user.
+func sortCostGroups(groups []repository.CostGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		if a.ServiceName != nil && b.ServiceName != nil && *a.ServiceName != *b.ServiceName {
+			return *a.ServiceName < *b.ServiceName
+		}
+		if a.UserID != nil && b.UserID != nil {
+			return a.UserID.String() < b.UserID.String()
+		}
+		return false
+	})
 }
 
 func (s *StatsService) ParseMonth(monthStr string) (time.Time, error) {
@@ -72,31 +274,83 @@ func (s *StatsService) FormatUUID(uuid *uuid.UUID) *string {
 	return &str
 }
 
-func (s *StatsService) calculateTotalCost(subscriptions []repository.SubscriptionCost, periodStart, periodEnd *time.Time) int {
-	totalCost := 0
+// calculateMonthlyCost spreads the cost of every subscription over the calendar
+// months it is charged for. It is the in-memory counterpart of the SQL aggregate in
+// StatsRepository.GetTotalCost: the sum of all months must be equal to the total.
+func (s *StatsService) calculateMonthlyCost(subscriptions []repository.SubscriptionCost, periodStart, periodEnd *time.Time) []repository.MonthlyCost {
+	var rangeStart, rangeEnd time.Time
+	if periodStart != nil {
+		rangeStart = monthStart(*periodStart)
+	}
+	if periodEnd != nil {
+		rangeEnd = monthStart(*periodEnd)
+	} else {
+		rangeEnd = monthStart(time.Now())
+	}
 
 	for _, sub := range subscriptions {
-		months := s.calculateIntersectionMonths(sub.StartDate, sub.EndDate, periodStart, periodEnd)
-		totalCost += sub.PriceRub * months
+		start, end, ok := s.intersectionBounds(sub.StartDate, sub.EndDate, periodStart, periodEnd)
+		if !ok {
+			continue
+		}
+		if periodStart == nil && (rangeStart.IsZero() || start.Before(rangeStart)) {
+			rangeStart = start
+		}
+		if periodEnd == nil && end.After(rangeEnd) {
+			rangeEnd = end
+		}
+	}
+
+	if rangeStart.IsZero() || rangeStart.After(rangeEnd) {
+		return nil
+	}
+
+	months := make([]repository.MonthlyCost, s.monthsBetween(rangeStart, rangeEnd))
+	for i := range months {
+		months[i].Month = rangeStart.AddDate(0, i, 0)
+	}
+
+	for _, sub := range subscriptions {
+		start, end, ok := s.intersectionBounds(sub.StartDate, sub.EndDate, periodStart, periodEnd)
+		if !ok {
+			continue
+		}
+		for i := s.monthsBetween(rangeStart, start) - 1; i < len(months) && !months[i].Month.After(end); i++ {
+			months[i].TotalCost += s.priceAt(sub, months[i].Month)
+			months[i].SubscriptionsCount++
+		}
+	}
+
+	return months
+}
+
+// priceAt returns the price a subscription is charged for the given month: the price
+// of the latest period that started by then, or of the first period for earlier months.
+func (s *StatsService) priceAt(sub repository.SubscriptionCost, month time.Time) int {
+	if len(sub.Prices) == 0 {
+		return sub.PriceRub
 	}
 
-	return totalCost
+	price := sub.Prices[0].PriceRub
+	for _, period := range sub.Prices[1:] {
+		if period.EffectiveFrom.After(month) {
+			break
+		}
+		price = period.PriceRub
+	}
+
+	return price
 }
 
-func (s *StatsService) calculateIntersectionMonths(
+// intersectionBounds returns the first and the last month in which a subscription is
+// charged within the period. Open-ended subscriptions run until the end of the period
+// or, when the period is open too, until the current month.
+func (s *StatsService) intersectionBounds(
 	subscriptionStart time.Time,
 	subscriptionEnd *time.Time,
 	periodStart *time.Time,
 	periodEnd *time.Time,
-) int {
-	if periodStart == nil && periodEnd == nil {
-		if subscriptionEnd == nil {
-			now := time.Now()
-			return s.monthsBetween(subscriptionStart, now)
-		}
-		return s.monthsBetween(subscriptionStart, *subscriptionEnd)
-	}
-
+) (time.Time, time.Time, bool) {
 	var intersectionStart, intersectionEnd time.Time
 
 	if periodStart == nil {
@@ -128,11 +382,14 @@ func (s *StatsService) calculateIntersectionMonths(
 		}
 	}
 
+	intersectionStart = monthStart(intersectionStart)
+	intersectionEnd = monthStart(intersectionEnd)
+
 	if intersectionStart.After(intersectionEnd) {
-		return 0
+		return time.Time{}, time.Time{}, false
 	}
 
-	return s.monthsBetween(intersectionStart, intersectionEnd)
+	return intersectionStart, intersectionEnd, true
 }
 
 func (s *StatsService) monthsBetween(start, end time.Time) int {
@@ -147,3 +404,7 @@ func (s *StatsService) monthsBetween(start, end time.Time) int {
 
 	return months
 }
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -41,6 +41,21 @@ func (m *MockStatsRepository) EXPECT() *MockStatsRepositoryMockRecorder {
 	return m.recorder
 }
 
+// GetGroupedCost mocks base method.
+func (m *MockStatsRepository) GetGroupedCost(ctx context.Context, p repository.GetTotalCostParams, groupBy []repository.GroupByField) ([]repository.CostGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupedCost", ctx, p, groupBy)
+	ret0, _ := ret[0].([]repository.CostGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupedCost indicates an expected call of GetGroupedCost.
+func (mr *MockStatsRepositoryMockRecorder) GetGroupedCost(ctx, p, groupBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupedCost", reflect.TypeOf((*MockStatsRepository)(nil).GetGroupedCost), ctx, p, groupBy)
+}
+
 // GetTotalCost mocks base method.
 func (m *MockStatsRepository) GetTotalCost(ctx context.Context, p repository.GetTotalCostParams) (repository.TotalCostStats, error) {
 	m.ctrl.T.Helper()
@@ -55,3 +70,18 @@ func (mr *MockStatsRepositoryMockRecorder) GetTotalCost(ctx, p any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalCost", reflect.TypeOf((*MockStatsRepository)(nil).GetTotalCost), ctx, p)
 }
+
+// ListSubscriptionCosts mocks base method.
+func (m *MockStatsRepository) ListSubscriptionCosts(ctx context.Context, p repository.GetTotalCostParams) ([]repository.SubscriptionCost, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptionCosts", ctx, p)
+	ret0, _ := ret[0].([]repository.SubscriptionCost)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptionCosts indicates an expected call of ListSubscriptionCosts.
+func (mr *MockStatsRepositoryMockRecorder) ListSubscriptionCosts(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptionCosts", reflect.TypeOf((*MockStatsRepository)(nil).ListSubscriptionCosts), ctx, p)
+}
@@ -49,6 +49,36 @@ func (s *StatsServiceSuite) TestGetTotalCost_Success() {
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
 
+	repoStats := repository.TotalCostStats{
+		TotalCost:          3100,
+		UserID:             &userID,
+		ServiceName:        &serviceName,
+		StartDate:          &startDate,
+		EndDate:            &endDate,
+		SubscriptionsCount: 2,
+	}
+
+	s.statsRepo.EXPECT().
+		GetTotalCost(s.ctx, repository.GetTotalCostParams{
+			UserID:      &userID,
+			ServiceName: &serviceName,
+			StartDate:   &startDate,
+			EndDate:     &endDate,
+		}).
+		Return(repoStats, nil)
+
+	result, err := s.statsService.GetTotalCost(s.ctx, &userID, &serviceName, &startDate, &endDate, false)
+
+	s.NoError(err)
+	s.Equal(&repoStats, result)
+}
+
+func (s *StatsServiceSuite) TestGetMonthlyCost_Success() {
+	userID := uuid.New()
+	serviceName := "Netflix"
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
 	subscriptions := []repository.SubscriptionCost{
 		{
 			ID:          1,
@@ -68,41 +98,23 @@ func (s *StatsServiceSuite) TestGetTotalCost_Success() {
 		},
 	}
 
-	repoStats := repository.TotalCostStats{
-		Subscriptions:      subscriptions,
-		UserID:             &userID,
-		ServiceName:        &serviceName,
-		StartDate:          &startDate,
-		EndDate:            &endDate,
-		SubscriptionsCount: 2,
-	}
-
-	expectedStats := repository.TotalCostStats{
-		TotalCost:          3100,
-		Subscriptions:      subscriptions,
-		UserID:             &userID,
-		ServiceName:        &serviceName,
-		StartDate:          &startDate,
-		EndDate:            &endDate,
-		SubscriptionsCount: 2,
-	}
-
 	s.statsRepo.EXPECT().
-		GetTotalCost(s.ctx, repository.GetTotalCostParams{
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{
 			UserID:      &userID,
 			ServiceName: &serviceName,
 			StartDate:   &startDate,
 			EndDate:     &endDate,
 		}).
-		Return(repoStats, nil)
+		Return(subscriptions, nil)
 
-	result, err := s.statsService.GetTotalCost(s.ctx, &userID, &serviceName, &startDate, &endDate)
+	result, err := s.statsService.GetMonthlyCost(s.ctx, &userID, &serviceName, &startDate, &endDate, false)
 
 	s.NoError(err)
-	s.Equal(&expectedStats, result)
+	s.Equal(3100, result.TotalCost)
+	s.Len(result.Months, 6)
 }
 
-func (s *StatsServiceSuite) TestGetTotalCost_WithPartialAndOutsidePeriod() {
+func (s *StatsServiceSuite) TestGetMonthlyCost_WithPartialAndOutsidePeriod() {
 	userID := uuid.New()
 	serviceName := "Netflix"
 	startDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
@@ -135,38 +147,19 @@ func (s *StatsServiceSuite) TestGetTotalCost_WithPartialAndOutsidePeriod() {
 		},
 	}
 
-	repoStats := repository.TotalCostStats{
-		Subscriptions:      subscriptions,
-		UserID:             &userID,
-		ServiceName:        &serviceName,
-		StartDate:          &startDate,
-		EndDate:            &endDate,
-		SubscriptionsCount: 3,
-	}
-
-	expectedStats := repository.TotalCostStats{
-		TotalCost:          700,
-		Subscriptions:      subscriptions,
-		UserID:             &userID,
-		ServiceName:        &serviceName,
-		StartDate:          &startDate,
-		EndDate:            &endDate,
-		SubscriptionsCount: 3,
-	}
-
 	s.statsRepo.EXPECT().
-		GetTotalCost(s.ctx, repository.GetTotalCostParams{
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{
 			UserID:      &userID,
 			ServiceName: &serviceName,
 			StartDate:   &startDate,
 			EndDate:     &endDate,
 		}).
-		Return(repoStats, nil)
+		Return(subscriptions, nil)
 
-	result, err := s.statsService.GetTotalCost(s.ctx, &userID, &serviceName, &startDate, &endDate)
+	result, err := s.statsService.GetMonthlyCost(s.ctx, &userID, &serviceName, &startDate, &endDate, false)
 
 	s.NoError(err)
-	s.Equal(&expectedStats, result)
+	s.Equal(700, result.TotalCost)
 }
 
 func (s *StatsServiceSuite) TestGetTotalCost_RepositoryError() {
@@ -185,13 +178,24 @@ func (s *StatsServiceSuite) TestGetTotalCost_RepositoryError() {
 		}).
 		Return(repository.TotalCostStats{}, repoError)
 
-	result, err := s.statsService.GetTotalCost(s.ctx, &userID, &serviceName, &startDate, &endDate)
+	result, err := s.statsService.GetTotalCost(s.ctx, &userID, &serviceName, &startDate, &endDate, false)
 
 	s.Error(err)
 	s.Nil(result)
 	s.Contains(err.Error(), "database error")
 }
 
+func (s *StatsServiceSuite) TestGetTotalCost_IncludeDeleted() {
+	s.statsRepo.EXPECT().
+		GetTotalCost(s.ctx, repository.GetTotalCostParams{IncludeDeleted: true}).
+		Return(repository.TotalCostStats{TotalCost: 100}, nil)
+
+	result, err := s.statsService.GetTotalCost(s.ctx, nil, nil, nil, nil, true)
+
+	s.NoError(err)
+	s.Equal(100, result.TotalCost)
+}
+
 func (s *StatsServiceSuite) TestParseMonth_Success() {
 	dateStr := "01-2024"
 	expectedDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -252,7 +256,7 @@ func (s *StatsServiceSuite) TestFormatUUID_NilUUID() {
 	s.Nil(result)
 }
 
-func (s *StatsServiceSuite) TestGetTotalCost_WithFuturePeriod() {
+func (s *StatsServiceSuite) TestGetMonthlyCost_WithFuturePeriod() {
 	userID := uuid.New()
 	serviceName := "Netflix"
 	now := time.Now()
@@ -273,38 +277,301 @@ func (s *StatsServiceSuite) TestGetTotalCost_WithFuturePeriod() {
 		},
 	}
 
-	repoStats := repository.TotalCostStats{
-		Subscriptions:      subscriptions,
-		UserID:             &userID,
-		ServiceName:        &serviceName,
-		StartDate:          &startDate,
-		EndDate:            &endDate,
-		SubscriptionsCount: 1,
-	}
-
-	expectedStats := repository.TotalCostStats{
-		TotalCost:          6000,
-		Subscriptions:      subscriptions,
-		UserID:             &userID,
-		ServiceName:        &serviceName,
-		StartDate:          &startDate,
-		EndDate:            &endDate,
-		SubscriptionsCount: 1,
-	}
-
 	s.statsRepo.EXPECT().
-		GetTotalCost(s.ctx, repository.GetTotalCostParams{
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{
 			UserID:      &userID,
 			ServiceName: &serviceName,
 			StartDate:   &startDate,
 			EndDate:     &endDate,
 		}).
-		Return(repoStats, nil)
+		Return(subscriptions, nil)
 
-	result, err := s.statsService.GetTotalCost(s.ctx, &userID, &serviceName, &startDate, &endDate)
+	result, err := s.statsService.GetMonthlyCost(s.ctx, &userID, &serviceName, &startDate, &endDate, false)
 
 	s.NoError(err)
-	s.Equal(&expectedStats, result)
+	s.Equal(6000, result.TotalCost)
+	s.Len(result.Months, 6)
+}
+
+func (s *StatsServiceSuite) TestGetMonthlyCost_Breakdown() {
+	userID := uuid.New()
+	startDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	subscriptions := []repository.SubscriptionCost{
+		{
+			ID:        1,
+			StartDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   timePtr(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)),
+			PriceRub:  100,
+			UserID:    userID,
+		},
+		{
+			ID:        2,
+			StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   timePtr(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)),
+			PriceRub:  200,
+			UserID:    userID,
+		},
+		{
+			ID:        3,
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   timePtr(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)),
+			PriceRub:  300,
+			UserID:    userID,
+		},
+	}
+
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{
+			UserID:    &userID,
+			StartDate: &startDate,
+			EndDate:   &endDate,
+		}).
+		Return(subscriptions, nil)
+
+	result, err := s.statsService.GetMonthlyCost(s.ctx, &userID, nil, &startDate, &endDate, false)
+	s.Require().NoError(err)
+
+	s.Equal([]repository.MonthlyCost{
+		{Month: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), TotalCost: 300, SubscriptionsCount: 2},
+		{Month: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), TotalCost: 300, SubscriptionsCount: 2},
+		{Month: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), TotalCost: 100, SubscriptionsCount: 1},
+		{Month: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), TotalCost: 0, SubscriptionsCount: 0},
+	}, result.Months)
+	s.Equal(&startDate, result.StartDate)
+	s.Equal(&endDate, result.EndDate)
+	s.Equal(700, result.TotalCost)
+}
+
+func (s *StatsServiceSuite) TestGetMonthlyCost_OpenPeriod() {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	firstMonth := currentMonth.AddDate(0, -2, 0)
+
+	subscriptions := []repository.SubscriptionCost{
+		{ID: 1, StartDate: firstMonth, PriceRub: 100},
+		{ID: 2, StartDate: currentMonth, EndDate: timePtr(currentMonth.AddDate(0, 1, 0)), PriceRub: 50},
+	}
+
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{}).
+		Return(subscriptions, nil)
+
+	result, err := s.statsService.GetMonthlyCost(s.ctx, nil, nil, nil, nil, false)
+	s.Require().NoError(err)
+
+	s.Require().Len(result.Months, 4)
+	s.Equal(firstMonth, result.Months[0].Month)
+	s.Equal(150, result.Months[2].TotalCost)
+	s.Equal(50, result.Months[3].TotalCost)
+	s.Equal(1, result.Months[3].SubscriptionsCount)
+	s.Equal(400, result.TotalCost)
+}
+
+func (s *StatsServiceSuite) TestGetMonthlyCost_PricePeriods() {
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	subscriptions := []repository.SubscriptionCost{
+		{
+			ID:        1,
+			StartDate: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
+			PriceRub:  700,
+			Prices: []repository.PricePeriod{
+				{EffectiveFrom: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), PriceRub: 500},
+				{EffectiveFrom: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), PriceRub: 600},
+				{EffectiveFrom: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), PriceRub: 700},
+			},
+		},
+	}
+
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{StartDate: &startDate, EndDate: &endDate}).
+		Return(subscriptions, nil)
+
+	result, err := s.statsService.GetMonthlyCost(s.ctx, nil, nil, &startDate, &endDate, false)
+	s.Require().NoError(err)
+
+	s.Require().Len(result.Months, 6)
+	s.Equal(500, result.Months[0].TotalCost)
+	s.Equal(500, result.Months[1].TotalCost)
+	s.Equal(600, result.Months[2].TotalCost)
+	s.Equal(600, result.Months[3].TotalCost)
+	s.Equal(700, result.Months[4].TotalCost)
+	s.Equal(700, result.Months[5].TotalCost)
+	s.Equal(3600, result.TotalCost)
+}
+
+func (s *StatsServiceSuite) TestPriceAt_BeforeFirstPeriod() {
+	sub := repository.SubscriptionCost{
+		PriceRub: 300,
+		Prices: []repository.PricePeriod{
+			{EffectiveFrom: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), PriceRub: 200},
+			{EffectiveFrom: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), PriceRub: 300},
+		},
+	}
+
+	s.Equal(200, s.statsService.priceAt(sub, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	s.Equal(200, s.statsService.priceAt(sub, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)))
+	s.Equal(300, s.statsService.priceAt(sub, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)))
+	s.Equal(300, s.statsService.priceAt(repository.SubscriptionCost{PriceRub: 300}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func (s *StatsServiceSuite) TestGetMonthlyCost_NoSubscriptions() {
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{}).
+		Return(nil, nil)
+
+	result, err := s.statsService.GetMonthlyCost(s.ctx, nil, nil, nil, nil, false)
+	s.Require().NoError(err)
+
+	s.Empty(result.Months)
+	s.Zero(result.TotalCost)
+	s.Nil(result.StartDate)
+}
+
+func (s *StatsServiceSuite) TestGetGroupedCost_Success() {
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	netflix, spotify := "Netflix", "Spotify"
+	groupBy := []repository.GroupByField{repository.GroupByService}
+
+	groups := []repository.CostGroup{
+		{ServiceName: &netflix, TotalCost: 6000, SubscriptionsCount: 2},
+		{ServiceName: &spotify, TotalCost: 1200, SubscriptionsCount: 1},
+	}
+
+	s.statsRepo.EXPECT().
+		GetGroupedCost(s.ctx, repository.GetTotalCostParams{
+			StartDate: &startDate,
+			EndDate:   &endDate,
+		}, groupBy).
+		Return(groups, nil)
+
+	result, err := s.statsService.GetGroupedCost(s.ctx, nil, nil, &startDate, &endDate, groupBy, false)
+
+	s.Require().NoError(err)
+	s.Equal(7200, result.TotalCost)
+	s.Equal(3, result.SubscriptionsCount)
+	s.Equal(groups, result.Groups)
+	s.Equal(groupBy, result.GroupBy)
+}
+
+func (s *StatsServiceSuite) TestGetGroupedCost_RepositoryError() {
+	groupBy := []repository.GroupByField{repository.GroupByUser}
+
+	s.statsRepo.EXPECT().
+		GetGroupedCost(s.ctx, repository.GetTotalCostParams{}, groupBy).
+		Return(nil, errors.New("database error"))
+
+	result, err := s.statsService.GetGroupedCost(s.ctx, nil, nil, nil, nil, groupBy, false)
+
+	s.Error(err)
+	s.Nil(result)
+}
+
+func (s *StatsServiceSuite) TestGetForecast_GroupByService() {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := currentMonth.AddDate(0, 2, 0)
+	firstUser, secondUser := uuid.New(), uuid.New()
+
+	subscriptions := []repository.SubscriptionCost{
+		{ID: 1, StartDate: currentMonth.AddDate(-1, 0, 0), PriceRub: 500, UserID: firstUser, ServiceName: "Netflix"},
+		{ID: 2, StartDate: currentMonth, EndDate: timePtr(currentMonth), PriceRub: 300, UserID: secondUser, ServiceName: "Spotify"},
+		{ID: 3, StartDate: currentMonth.AddDate(0, 1, 0), PriceRub: 200, UserID: secondUser, ServiceName: "Netflix"},
+	}
+
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{
+			StartDate: &currentMonth,
+			EndDate:   &endDate,
+		}).
+		Return(subscriptions, nil)
+
+	result, err := s.statsService.GetForecast(s.ctx, nil, nil, 3, []repository.GroupByField{repository.GroupByService})
+	s.Require().NoError(err)
+
+	s.Equal(currentMonth, result.StartDate)
+	s.Equal(endDate, result.EndDate)
+	s.Equal(800+700+700, result.TotalCost)
+	s.Require().Len(result.Months, 3)
+
+	netflix, spotify := "Netflix", "Spotify"
+	s.Equal([]repository.CostGroup{
+		{ServiceName: &netflix, TotalCost: 500, SubscriptionsCount: 1},
+		{ServiceName: &spotify, TotalCost: 300, SubscriptionsCount: 1},
+	}, result.Months[0].Groups)
+	s.Equal([]repository.CostGroup{
+		{ServiceName: &netflix, TotalCost: 700, SubscriptionsCount: 2},
+	}, result.Months[1].Groups)
+	s.Equal(700, result.Months[2].TotalCost)
+	s.Equal(2, result.Months[2].SubscriptionsCount)
+}
+
+func (s *StatsServiceSuite) TestGetForecast_GroupByUserWithPriceChange() {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := currentMonth.AddDate(0, 1, 0)
+	userID := uuid.New()
+
+	subscriptions := []repository.SubscriptionCost{
+		{
+			ID:          1,
+			StartDate:   currentMonth,
+			PriceRub:    600,
+			UserID:      userID,
+			ServiceName: "Netflix",
+			Prices: []repository.PricePeriod{
+				{EffectiveFrom: currentMonth, PriceRub: 500},
+				{EffectiveFrom: endDate, PriceRub: 600},
+			},
+		},
+	}
+
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, repository.GetTotalCostParams{
+			UserID:    &userID,
+			StartDate: &currentMonth,
+			EndDate:   &endDate,
+		}).
+		Return(subscriptions, nil)
+
+	result, err := s.statsService.GetForecast(s.ctx, &userID, nil, 2, []repository.GroupByField{repository.GroupByUser})
+	s.Require().NoError(err)
+
+	s.Equal(1100, result.TotalCost)
+	s.Require().Len(result.Months, 2)
+	s.Equal([]repository.CostGroup{{UserID: &userID, TotalCost: 500, SubscriptionsCount: 1}}, result.Months[0].Groups)
+	s.Equal([]repository.CostGroup{{UserID: &userID, TotalCost: 600, SubscriptionsCount: 1}}, result.Months[1].Groups)
+}
+
+func (s *StatsServiceSuite) TestGetForecast_NoSubscriptions() {
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, gomock.Any()).
+		Return(nil, nil)
+
+	result, err := s.statsService.GetForecast(s.ctx, nil, nil, 6, []repository.GroupByField{repository.GroupByService})
+	s.Require().NoError(err)
+
+	s.Zero(result.TotalCost)
+	s.Require().Len(result.Months, 6)
+	for _, m := range result.Months {
+		s.Zero(m.TotalCost)
+		s.Empty(m.Groups)
+	}
+}
+
+func (s *StatsServiceSuite) TestGetForecast_RepositoryError() {
+	s.statsRepo.EXPECT().
+		ListSubscriptionCosts(s.ctx, gomock.Any()).
+		Return(nil, errors.New("database error"))
+
+	result, err := s.statsService.GetForecast(s.ctx, nil, nil, 12, []repository.GroupByField{repository.GroupByService})
+
+	s.Error(err)
+	s.Nil(result)
 }
 
 func timePtr(t time.Time) *time.Time {
@@ -4,43 +4,67 @@ package service
 
 import (
 	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 )
 
 type ServicesRepository interface {
+	ListServices(ctx context.Context) ([]repository.Service, error)
 	AddService(ctx context.Context, name string) (int, error)
 	GetServiceName(ctx context.Context, id int) (string, error)
 	GetServiceID(ctx context.Context, name string) (int, error)
 	GetOrCreateServiceID(ctx context.Context, name string) (int, error)
+	GetOrCreateServiceIDs(ctx context.Context, names []string) (map[string]int, error)
+	RenameService(ctx context.Context, id int, name string) error
 	DeleteService(ctx context.Context, id int) error
+	AddServiceAlias(ctx context.Context, serviceID int, alias string) error
+	ListServiceAliases(ctx context.Context, serviceID int) ([]string, error)
+	DeleteServiceAlias(ctx context.Context, serviceID int, alias string) error
+	MergeServices(ctx context.Context, sourceID, targetID int, requestID string) (int64, error)
 }
 
 type SubscriptionRepository interface {
 	CreateSubscription(ctx context.Context, p repository.CreateSubscriptionParams) (int64, error)
-	GetSubscription(ctx context.Context, id int64) (repository.Subscription, error)
-	UpdateSubscription(ctx context.Context, p repository.UpdateSubscriptionParams) error
-	DeleteSubscription(ctx context.Context, id int64) error
-	ListSubscriptions(ctx context.Context, p repository.ListSubscriptionsParams) ([]repository.Subscription, int, error)
+	CreateSubscriptions(ctx context.Context, items []repository.CreateSubscriptionParams, atomic bool) ([]repository.CreateSubscriptionResult, error)
+	GetSubscription(ctx context.Context, id int64, includeDeleted bool) (repository.Subscription, error)
+	GetSubscriptionForUpdate(ctx context.Context, id int64) (repository.Subscription, error)
+	UpdateSubscription(ctx context.Context, p repository.UpdateSubscriptionParams) (int, error)
+	DeleteSubscription(ctx context.Context, id int64, version *int, requestID string) error
+	RestoreSubscription(ctx context.Context, id int64, requestID string) error
+	ListSubscriptionHistory(ctx context.Context, subscriptionID int64) ([]repository.SubscriptionHistoryRecord, error)
+	ListSubscriptions(ctx context.Context, p repository.ListSubscriptionsParams) (*repository.SubscriptionPage, error)
+	StreamSubscriptions(ctx context.Context, p repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error
+	ListEndingSoon(ctx context.Context, from, until time.Time) ([]repository.Subscription, error)
+	MarkEndingSoonNotified(ctx context.Context, id int64, endDate time.Time) (bool, error)
+}
+
+// Transactor runs fn atomically: the repositories called with the ctx passed to fn
+// share one transaction, which is rolled back when fn returns an error.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type SubscriptionService struct {
 	serviceRepo      ServicesRepository
 	subscriptionRepo SubscriptionRepository
+	transactor       Transactor
 	log              *slog.Logger
 }
 
 var ErrValidation = errors.New("validation error")
 
-func NewSubscriptionService(serviceRepo ServicesRepository, subscriptionRepo SubscriptionRepository, log *slog.Logger) *SubscriptionService {
+func NewSubscriptionService(serviceRepo ServicesRepository, subscriptionRepo SubscriptionRepository, transactor Transactor, log *slog.Logger) *SubscriptionService {
 	return &SubscriptionService{
 		serviceRepo:      serviceRepo,
 		subscriptionRepo: subscriptionRepo,
+		transactor:       transactor,
 		log:              log,
 	}
 }
@@ -48,59 +72,93 @@ func NewSubscriptionService(serviceRepo ServicesRepository, subscriptionRepo Sub
 func (s *SubscriptionService) CreateSubscription(ctx context.Context, serviceName string, price int, userID uuid.UUID, startDate, endDate string) (int64, error) {
 	const op = "service.subscription.CreateSubscription"
 	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
+	serviceName = repository.NormalizeServiceName(serviceName)
+	params, err := s.createParams(serviceName, price, userID, startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	params.RequestID = middleware.GetReqID(ctx)
+
+	id, err := s.createWithService(ctx, params, serviceName)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("create subscription failed", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// createWithService resolves the service of the subscription and creates it in one
+// transaction, so a new service is only kept together with the subscription.
+func (s *SubscriptionService) createWithService(ctx context.Context, params repository.CreateSubscriptionParams, serviceName string) (int64, error) {
+	var id int64
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		params.ServiceID, err = s.serviceRepo.GetOrCreateServiceID(ctx, serviceName)
+		if err != nil {
+			return fmt.Errorf("get or create service: %w", err)
+		}
+
+		id, err = s.subscriptionRepo.CreateSubscription(ctx, params)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// createParams validates a new subscription. ServiceID and RequestID are left for the
+// caller to fill.
+func (s *SubscriptionService) createParams(serviceName string, price int, userID uuid.UUID, startDate, endDate string) (repository.CreateSubscriptionParams, error) {
 	if serviceName == "" {
-		return 0, fmt.Errorf("%w: service name is required", ErrValidation)
+		return repository.CreateSubscriptionParams{}, fmt.Errorf("%w: service name is required", ErrValidation)
 	}
 
 	if price < 0 {
-		return 0, fmt.Errorf("%w: price must be non-negative", ErrValidation)
+		return repository.CreateSubscriptionParams{}, fmt.Errorf("%w: price must be non-negative", ErrValidation)
 	}
 
 	startDateParsed, err := s.ParseMonth(startDate)
 	if err != nil {
-		return 0, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+		return repository.CreateSubscriptionParams{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 	}
 
 	var endDatePtr *time.Time
 	if endDate != "" {
 		ed, err := s.ParseMonth(endDate)
 		if err != nil {
-			return 0, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+			return repository.CreateSubscriptionParams{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 		}
 		if ed.Before(startDateParsed) {
-			return 0, fmt.Errorf("%w: end date must be after start date", ErrValidation)
+			return repository.CreateSubscriptionParams{}, fmt.Errorf("%w: end date must be after start date", ErrValidation)
 		}
 		endDatePtr = &ed
 	}
 
-	serviceID, err := s.serviceRepo.GetOrCreateServiceID(ctx, serviceName)
-	if err != nil {
-		log.Error("get or create service failed", slog.String("err", err.Error()))
-		return 0, err
-	}
-
-	id, err := s.subscriptionRepo.CreateSubscription(ctx, repository.CreateSubscriptionParams{
+	return repository.CreateSubscriptionParams{
 		UserID:    userID,
-		ServiceID: serviceID,
 		PriceRub:  price,
 		StartDate: startDateParsed,
 		EndDate:   endDatePtr,
-	})
-	if err != nil {
-		log.Error("create subscription failed", slog.String("err", err.Error()))
-		return 0, err
-	}
-
-	return id, nil
+	}, nil
 }
 
-func (s *SubscriptionService) GetSubscription(ctx context.Context, id int64) (*repository.Subscription, error) {
+func (s *SubscriptionService) GetSubscription(ctx context.Context, id int64, includeDeleted bool) (*repository.Subscription, error) {
 	const op = "service.subscription.GetSubscription"
 	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	subscription, err := s.subscriptionRepo.GetSubscription(ctx, id)
+	subscription, err := s.subscriptionRepo.GetSubscription(ctx, id, includeDeleted)
 	if err != nil {
+		span.RecordError(err)
 		log.Error("get subscription failed", slog.String("err", err.Error()))
 		return nil, err
 	}
@@ -108,90 +166,228 @@ func (s *SubscriptionService) GetSubscription(ctx context.Context, id int64) (*r
 	return &subscription, nil
 }
 
-func (s *SubscriptionService) UpdateSubscription(ctx context.Context, id int64, serviceName *string, price *int, startDate, endDate *string) error {
+// SubscriptionPatch lists the changes of an update; nil fields are left unchanged.
+type SubscriptionPatch struct {
+	ServiceName *string
+	Price       *int
+	// PriceEffectiveFrom is the first month (MM-YYYY) Price applies to. Without it a
+	// changed price applies from the current month.
+	PriceEffectiveFrom *string
+	StartDate          *string
+	EndDate            *string
+	// ClearEndDate removes the end date, it cannot be combined with EndDate.
+	ClearEndDate bool
+	// ResetPriceHistory applies Price to the whole lifetime of the subscription and
+	// drops its price history. It cannot be combined with PriceEffectiveFrom.
+	ResetPriceHistory bool
+}
+
+func (p SubscriptionPatch) isEmpty() bool {
+	return p.ServiceName == nil && p.Price == nil && p.StartDate == nil && p.EndDate == nil && !p.ClearEndDate
+}
+
+// UpdateSubscription applies the patch and returns the new version of the
+// subscription.
+func (s *SubscriptionService) UpdateSubscription(ctx context.Context, id int64, patch SubscriptionPatch, version *int) (int, error) {
 	const op = "service.subscription.UpdateSubscription"
 	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	if id <= 0 {
-		return fmt.Errorf("%w: invalid subscription id", ErrValidation)
+		return 0, fmt.Errorf("%w: invalid subscription id", ErrValidation)
+	}
+
+	if patch.isEmpty() {
+		return 0, fmt.Errorf("%w: nothing to update", ErrValidation)
+	}
+
+	if patch.ServiceName != nil {
+		serviceName := repository.NormalizeServiceName(*patch.ServiceName)
+		patch.ServiceName = &serviceName
 	}
 
-	if serviceName != nil && *serviceName == "" {
-		return fmt.Errorf("%w: service name cannot be empty", ErrValidation)
+	if patch.ServiceName != nil && *patch.ServiceName == "" {
+		return 0, fmt.Errorf("%w: service name cannot be empty", ErrValidation)
 	}
 
-	if price != nil && *price < 0 {
-		return fmt.Errorf("%w: price must be non-negative", ErrValidation)
+	if patch.Price != nil && *patch.Price < 0 {
+		return 0, fmt.Errorf("%w: price must be non-negative", ErrValidation)
+	}
+
+	if patch.PriceEffectiveFrom != nil && patch.Price == nil {
+		return 0, fmt.Errorf("%w: price_effective_from requires price", ErrValidation)
+	}
+
+	if patch.ResetPriceHistory && patch.Price == nil {
+		return 0, fmt.Errorf("%w: reset_price_history requires price", ErrValidation)
+	}
+
+	if patch.ResetPriceHistory && patch.PriceEffectiveFrom != nil {
+		return 0, fmt.Errorf("%w: reset_price_history cannot be combined with price_effective_from", ErrValidation)
+	}
+
+	if patch.ClearEndDate && patch.EndDate != nil {
+		return 0, fmt.Errorf("%w: end date cannot be set and cleared at once", ErrValidation)
 	}
 
 	updateParams := repository.UpdateSubscriptionParams{
-		ID:       id,
-		PriceRub: price,
+		ID:           id,
+		PriceRub:     patch.Price,
+		ClearEndDate: patch.ClearEndDate,
+		ResetPrices:  patch.ResetPriceHistory,
+		Version:      version,
+		RequestID:    middleware.GetReqID(ctx),
 	}
 
-	if serviceName != nil {
-		serviceID, err := s.serviceRepo.GetOrCreateServiceID(ctx, *serviceName)
+	if patch.StartDate != nil {
+		startDateParsed, err := s.ParseMonth(*patch.StartDate)
 		if err != nil {
-			log.Error("get or create service failed", slog.String("err", err.Error()))
-			return err
+			return 0, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 		}
-		updateParams.ServiceID = &serviceID
+		updateParams.StartDate = &startDateParsed
 	}
 
-	if startDate != nil {
-		startDateParsed, err := s.ParseMonth(*startDate)
+	if patch.EndDate != nil {
+		endDateParsed, err := s.ParseMonth(*patch.EndDate)
 		if err != nil {
-			return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+			return 0, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 		}
-		updateParams.StartDate = &startDateParsed
+		updateParams.EndDate = &endDateParsed
+	}
+
+	if patch.PriceEffectiveFrom != nil {
+		effectiveFrom, err := s.ParseMonth(*patch.PriceEffectiveFrom)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+		}
+		updateParams.PriceEffectiveFrom = &effectiveFrom
 	}
 
-	if endDate != nil {
-		if *endDate == "" || *endDate == "null" {
-			updateParams.EndDate = &time.Time{}
-		} else {
-			endDateParsed, err := s.ParseMonth(*endDate)
+	var newVersion int
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		// The dates are checked against the locked row, so a concurrent update cannot
+		// slip between the check and the UPDATE.
+		if updateParams.StartDate != nil || updateParams.EndDate != nil || updateParams.PriceEffectiveFrom != nil {
+			current, err := s.subscriptionRepo.GetSubscriptionForUpdate(ctx, id)
 			if err != nil {
-				return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+				span.RecordError(err)
+				log.Error("get subscription failed", slog.String("err", err.Error()))
+				return err
 			}
-			if updateParams.StartDate != nil {
-				if endDateParsed.Before(*updateParams.StartDate) {
-					return fmt.Errorf("%w: end date must be after start date", ErrValidation)
-				}
-			} else {
-				// Validate against current start_date if not provided in request
-				current, getErr := s.subscriptionRepo.GetSubscription(ctx, id)
-				if getErr == nil {
-					if endDateParsed.Before(current.StartDate) {
-						return fmt.Errorf("%w: end date must be after start date", ErrValidation)
-					}
-				}
+
+			if err := validateUpdatedDates(current, updateParams); err != nil {
+				return err
 			}
-			updateParams.EndDate = &endDateParsed
 		}
-	}
 
-	err := s.subscriptionRepo.UpdateSubscription(ctx, updateParams)
+		if patch.ServiceName != nil {
+			serviceID, err := s.serviceRepo.GetOrCreateServiceID(ctx, *patch.ServiceName)
+			if err != nil {
+				span.RecordError(err)
+				log.Error("get or create service failed", slog.String("err", err.Error()))
+				return err
+			}
+			updateParams.ServiceID = &serviceID
+		}
+
+		var err error
+		newVersion, err = s.subscriptionRepo.UpdateSubscription(ctx, updateParams)
+		if err != nil {
+			span.RecordError(err)
+			log.Error("update subscription failed", slog.String("err", err.Error()))
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		log.Error("update subscription failed", slog.String("err", err.Error()))
-		return err
+		return 0, err
+	}
+
+	return newVersion, nil
+}
+
+// validateUpdatedDates checks the dates the subscription will have once p is applied
+// to current: the end date must not precede the start date, and a price change must
+// start within the lifetime.
+func validateUpdatedDates(current repository.Subscription, p repository.UpdateSubscriptionParams) error {
+	startDate, endDate := current.StartDate, current.EndDate
+	if p.StartDate != nil {
+		startDate = *p.StartDate
+	}
+	if p.ClearEndDate {
+		endDate = nil
+	} else if p.EndDate != nil {
+		endDate = p.EndDate
+	}
+
+	if endDate != nil && endDate.Before(startDate) {
+		return fmt.Errorf("%w: end date must be after start date", ErrValidation)
+	}
+
+	if p.PriceEffectiveFrom != nil {
+		if p.PriceEffectiveFrom.Before(startDate) {
+			return fmt.Errorf("%w: price_effective_from must not be before start date", ErrValidation)
+		}
+		if endDate != nil && p.PriceEffectiveFrom.After(*endDate) {
+			return fmt.Errorf("%w: price_effective_from must not be after end date", ErrValidation)
+		}
 	}
 
 	return nil
 }
 
-func (s *SubscriptionService) DeleteSubscription(ctx context.Context, id int64) error {
+func (s *SubscriptionService) DeleteSubscription(ctx context.Context, id int64, version *int) error {
 	const op = "service.subscription.DeleteSubscription"
 	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	err := s.subscriptionRepo.DeleteSubscription(ctx, id)
+	err := s.subscriptionRepo.DeleteSubscription(ctx, id, version, middleware.GetReqID(ctx))
 	if err != nil {
+		span.RecordError(err)
 		log.Error("delete subscription failed", slog.String("err", err.Error()))
 		return err
 	}
 	return nil
 }
 
+func (s *SubscriptionService) RestoreSubscription(ctx context.Context, id int64) error {
+	const op = "service.subscription.RestoreSubscription"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if id <= 0 {
+		return fmt.Errorf("%w: invalid subscription id", ErrValidation)
+	}
+
+	err := s.subscriptionRepo.RestoreSubscription(ctx, id, middleware.GetReqID(ctx))
+	if err != nil {
+		span.RecordError(err)
+		log.Error("restore subscription failed", slog.String("err", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (s *SubscriptionService) GetSubscriptionHistory(ctx context.Context, id int64) ([]repository.SubscriptionHistoryRecord, error) {
+	const op = "service.subscription.GetSubscriptionHistory"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	records, err := s.subscriptionRepo.ListSubscriptionHistory(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("list subscription history failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return records, nil
+}
+
 func (s *SubscriptionService) ParseMonth(monthStr string) (time.Time, error) {
 	t, err := time.Parse("01-2006", monthStr)
 	if err != nil {
@@ -200,6 +396,58 @@ func (s *SubscriptionService) ParseMonth(monthStr string) (time.Time, error) {
 	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
 }
 
-func (s *SubscriptionService) ListSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams) ([]repository.Subscription, int, error) {
-	return s.subscriptionRepo.ListSubscriptions(ctx, params)
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams) (*repository.SubscriptionPage, error) {
+	const op = "service.subscription.ListSubscriptions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	page, err := s.subscriptionRepo.ListSubscriptions(ctx, params)
+	span.RecordError(err)
+	return page, err
+}
+
+// ExportSubscriptions streams all subscriptions matching params to fn, ignoring pagination.
+func (s *SubscriptionService) ExportSubscriptions(ctx context.Context, params repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error {
+	const op = "service.subscription.ExportSubscriptions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	err := s.subscriptionRepo.StreamSubscriptions(ctx, params, fn)
+	span.RecordError(err)
+	return err
+}
+
+// NotifyEndingSoon queues a subscription.ending_soon event for every subscription
+// whose last month starts within the given time from now, once per end_date. It
+// returns the number of queued events.
+func (s *SubscriptionService) NotifyEndingSoon(ctx context.Context, within time.Duration) (int, error) {
+	const op = "service.subscription.NotifyEndingSoon"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	subscriptions, err := s.subscriptionRepo.ListEndingSoon(ctx, from, now.Add(within))
+	if err != nil {
+		span.RecordError(err)
+		log.Error("list ending subscriptions failed", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	notified := 0
+	for _, sub := range subscriptions {
+		marked, err := s.subscriptionRepo.MarkEndingSoonNotified(ctx, sub.ID, *sub.EndDate)
+		if err != nil {
+			span.RecordError(err)
+			log.Error("mark ending subscription failed", slog.Int64("subscription_id", sub.ID), slog.String("err", err.Error()))
+			return notified, err
+		}
+		if marked {
+			notified++
+		}
+	}
+
+	return notified, nil
 }
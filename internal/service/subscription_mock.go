@@ -13,6 +13,7 @@ import (
 	repository "EffectiveMobile/internal/repository"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -56,6 +57,20 @@ func (mr *MockServicesRepositoryMockRecorder) AddService(ctx, name any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddService", reflect.TypeOf((*MockServicesRepository)(nil).AddService), ctx, name)
 }
 
+// AddServiceAlias mocks base method.
+func (m *MockServicesRepository) AddServiceAlias(ctx context.Context, serviceID int, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddServiceAlias", ctx, serviceID, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddServiceAlias indicates an expected call of AddServiceAlias.
+func (mr *MockServicesRepositoryMockRecorder) AddServiceAlias(ctx, serviceID, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddServiceAlias", reflect.TypeOf((*MockServicesRepository)(nil).AddServiceAlias), ctx, serviceID, alias)
+}
+
 // DeleteService mocks base method.
 func (m *MockServicesRepository) DeleteService(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
@@ -70,6 +85,20 @@ func (mr *MockServicesRepositoryMockRecorder) DeleteService(ctx, id any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteService", reflect.TypeOf((*MockServicesRepository)(nil).DeleteService), ctx, id)
 }
 
+// DeleteServiceAlias mocks base method.
+func (m *MockServicesRepository) DeleteServiceAlias(ctx context.Context, serviceID int, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteServiceAlias", ctx, serviceID, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteServiceAlias indicates an expected call of DeleteServiceAlias.
+func (mr *MockServicesRepositoryMockRecorder) DeleteServiceAlias(ctx, serviceID, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServiceAlias", reflect.TypeOf((*MockServicesRepository)(nil).DeleteServiceAlias), ctx, serviceID, alias)
+}
+
 // GetOrCreateServiceID mocks base method.
 func (m *MockServicesRepository) GetOrCreateServiceID(ctx context.Context, name string) (int, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +114,21 @@ func (mr *MockServicesRepositoryMockRecorder) GetOrCreateServiceID(ctx, name any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrCreateServiceID", reflect.TypeOf((*MockServicesRepository)(nil).GetOrCreateServiceID), ctx, name)
 }
 
+// GetOrCreateServiceIDs mocks base method.
+func (m *MockServicesRepository) GetOrCreateServiceIDs(ctx context.Context, names []string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrCreateServiceIDs", ctx, names)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrCreateServiceIDs indicates an expected call of GetOrCreateServiceIDs.
+func (mr *MockServicesRepositoryMockRecorder) GetOrCreateServiceIDs(ctx, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrCreateServiceIDs", reflect.TypeOf((*MockServicesRepository)(nil).GetOrCreateServiceIDs), ctx, names)
+}
+
 // GetServiceID mocks base method.
 func (m *MockServicesRepository) GetServiceID(ctx context.Context, name string) (int, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +159,65 @@ func (mr *MockServicesRepositoryMockRecorder) GetServiceName(ctx, id any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceName", reflect.TypeOf((*MockServicesRepository)(nil).GetServiceName), ctx, id)
 }
 
+// ListServiceAliases mocks base method.
+func (m *MockServicesRepository) ListServiceAliases(ctx context.Context, serviceID int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceAliases", ctx, serviceID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAliases indicates an expected call of ListServiceAliases.
+func (mr *MockServicesRepositoryMockRecorder) ListServiceAliases(ctx, serviceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAliases", reflect.TypeOf((*MockServicesRepository)(nil).ListServiceAliases), ctx, serviceID)
+}
+
+// ListServices mocks base method.
+func (m *MockServicesRepository) ListServices(ctx context.Context) ([]repository.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServices", ctx)
+	ret0, _ := ret[0].([]repository.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServices indicates an expected call of ListServices.
+func (mr *MockServicesRepositoryMockRecorder) ListServices(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServices", reflect.TypeOf((*MockServicesRepository)(nil).ListServices), ctx)
+}
+
+// MergeServices mocks base method.
+func (m *MockServicesRepository) MergeServices(ctx context.Context, sourceID, targetID int, requestID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeServices", ctx, sourceID, targetID, requestID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeServices indicates an expected call of MergeServices.
+func (mr *MockServicesRepositoryMockRecorder) MergeServices(ctx, sourceID, targetID, requestID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeServices", reflect.TypeOf((*MockServicesRepository)(nil).MergeServices), ctx, sourceID, targetID, requestID)
+}
+
+// RenameService mocks base method.
+func (m *MockServicesRepository) RenameService(ctx context.Context, id int, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameService", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameService indicates an expected call of RenameService.
+func (mr *MockServicesRepositoryMockRecorder) RenameService(ctx, id, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameService", reflect.TypeOf((*MockServicesRepository)(nil).RenameService), ctx, id, name)
+}
+
 // MockSubscriptionRepository is a mock of SubscriptionRepository interface.
 type MockSubscriptionRepository struct {
 	ctrl     *gomock.Controller
@@ -154,43 +257,102 @@ func (mr *MockSubscriptionRepositoryMockRecorder) CreateSubscription(ctx, p any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).CreateSubscription), ctx, p)
 }
 
+// CreateSubscriptions mocks base method.
+func (m *MockSubscriptionRepository) CreateSubscriptions(ctx context.Context, items []repository.CreateSubscriptionParams, atomic bool) ([]repository.CreateSubscriptionResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscriptions", ctx, items, atomic)
+	ret0, _ := ret[0].([]repository.CreateSubscriptionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscriptions indicates an expected call of CreateSubscriptions.
+func (mr *MockSubscriptionRepositoryMockRecorder) CreateSubscriptions(ctx, items, atomic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscriptions", reflect.TypeOf((*MockSubscriptionRepository)(nil).CreateSubscriptions), ctx, items, atomic)
+}
+
 // DeleteSubscription mocks base method.
-func (m *MockSubscriptionRepository) DeleteSubscription(ctx context.Context, id int64) error {
+func (m *MockSubscriptionRepository) DeleteSubscription(ctx context.Context, id int64, version *int, requestID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id, version, requestID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteSubscription indicates an expected call of DeleteSubscription.
-func (mr *MockSubscriptionRepositoryMockRecorder) DeleteSubscription(ctx, id any) *gomock.Call {
+func (mr *MockSubscriptionRepositoryMockRecorder) DeleteSubscription(ctx, id, version, requestID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).DeleteSubscription), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).DeleteSubscription), ctx, id, version, requestID)
 }
 
 // GetSubscription mocks base method.
-func (m *MockSubscriptionRepository) GetSubscription(ctx context.Context, id int64) (repository.Subscription, error) {
+func (m *MockSubscriptionRepository) GetSubscription(ctx context.Context, id int64, includeDeleted bool) (repository.Subscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSubscription", ctx, id)
+	ret := m.ctrl.Call(m, "GetSubscription", ctx, id, includeDeleted)
 	ret0, _ := ret[0].(repository.Subscription)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSubscription indicates an expected call of GetSubscription.
-func (mr *MockSubscriptionRepositoryMockRecorder) GetSubscription(ctx, id any) *gomock.Call {
+func (mr *MockSubscriptionRepositoryMockRecorder) GetSubscription(ctx, id, includeDeleted any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).GetSubscription), ctx, id, includeDeleted)
+}
+
+// GetSubscriptionForUpdate mocks base method.
+func (m *MockSubscriptionRepository) GetSubscriptionForUpdate(ctx context.Context, id int64) (repository.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionForUpdate", ctx, id)
+	ret0, _ := ret[0].(repository.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionForUpdate indicates an expected call of GetSubscriptionForUpdate.
+func (mr *MockSubscriptionRepositoryMockRecorder) GetSubscriptionForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionForUpdate", reflect.TypeOf((*MockSubscriptionRepository)(nil).GetSubscriptionForUpdate), ctx, id)
+}
+
+// ListEndingSoon mocks base method.
+func (m *MockSubscriptionRepository) ListEndingSoon(ctx context.Context, from, until time.Time) ([]repository.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEndingSoon", ctx, from, until)
+	ret0, _ := ret[0].([]repository.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEndingSoon indicates an expected call of ListEndingSoon.
+func (mr *MockSubscriptionRepositoryMockRecorder) ListEndingSoon(ctx, from, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEndingSoon", reflect.TypeOf((*MockSubscriptionRepository)(nil).ListEndingSoon), ctx, from, until)
+}
+
+// ListSubscriptionHistory mocks base method.
+func (m *MockSubscriptionRepository) ListSubscriptionHistory(ctx context.Context, subscriptionID int64) ([]repository.SubscriptionHistoryRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptionHistory", ctx, subscriptionID)
+	ret0, _ := ret[0].([]repository.SubscriptionHistoryRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptionHistory indicates an expected call of ListSubscriptionHistory.
+func (mr *MockSubscriptionRepositoryMockRecorder) ListSubscriptionHistory(ctx, subscriptionID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).GetSubscription), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptionHistory", reflect.TypeOf((*MockSubscriptionRepository)(nil).ListSubscriptionHistory), ctx, subscriptionID)
 }
 
 // ListSubscriptions mocks base method.
-func (m *MockSubscriptionRepository) ListSubscriptions(ctx context.Context, p repository.ListSubscriptionsParams) ([]repository.Subscription, int, error) {
+func (m *MockSubscriptionRepository) ListSubscriptions(ctx context.Context, p repository.ListSubscriptionsParams) (*repository.SubscriptionPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListSubscriptions", ctx, p)
-	ret0, _ := ret[0].([]repository.Subscription)
-	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].(*repository.SubscriptionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ListSubscriptions indicates an expected call of ListSubscriptions.
@@ -199,16 +361,98 @@ func (mr *MockSubscriptionRepositoryMockRecorder) ListSubscriptions(ctx, p any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockSubscriptionRepository)(nil).ListSubscriptions), ctx, p)
 }
 
-// UpdateSubscription mocks base method.
-func (m *MockSubscriptionRepository) UpdateSubscription(ctx context.Context, p repository.UpdateSubscriptionParams) error {
+// MarkEndingSoonNotified mocks base method.
+func (m *MockSubscriptionRepository) MarkEndingSoonNotified(ctx context.Context, id int64, endDate time.Time) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, p)
+	ret := m.ctrl.Call(m, "MarkEndingSoonNotified", ctx, id, endDate)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkEndingSoonNotified indicates an expected call of MarkEndingSoonNotified.
+func (mr *MockSubscriptionRepositoryMockRecorder) MarkEndingSoonNotified(ctx, id, endDate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEndingSoonNotified", reflect.TypeOf((*MockSubscriptionRepository)(nil).MarkEndingSoonNotified), ctx, id, endDate)
+}
+
+// RestoreSubscription mocks base method.
+func (m *MockSubscriptionRepository) RestoreSubscription(ctx context.Context, id int64, requestID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSubscription", ctx, id, requestID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreSubscription indicates an expected call of RestoreSubscription.
+func (mr *MockSubscriptionRepositoryMockRecorder) RestoreSubscription(ctx, id, requestID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).RestoreSubscription), ctx, id, requestID)
+}
+
+// StreamSubscriptions mocks base method.
+func (m *MockSubscriptionRepository) StreamSubscriptions(ctx context.Context, p repository.ListSubscriptionsParams, fn func(repository.Subscription) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamSubscriptions", ctx, p, fn)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// StreamSubscriptions indicates an expected call of StreamSubscriptions.
+func (mr *MockSubscriptionRepositoryMockRecorder) StreamSubscriptions(ctx, p, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamSubscriptions", reflect.TypeOf((*MockSubscriptionRepository)(nil).StreamSubscriptions), ctx, p, fn)
+}
+
+// UpdateSubscription mocks base method.
+func (m *MockSubscriptionRepository) UpdateSubscription(ctx context.Context, p repository.UpdateSubscriptionParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateSubscription indicates an expected call of UpdateSubscription.
 func (mr *MockSubscriptionRepositoryMockRecorder) UpdateSubscription(ctx, p any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockSubscriptionRepository)(nil).UpdateSubscription), ctx, p)
 }
+
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+	isgomock struct{}
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTx mocks base method.
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockTransactorMockRecorder) WithinTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*MockTransactor)(nil).WithinTx), ctx, fn)
+}
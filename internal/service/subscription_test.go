@@ -10,6 +10,7 @@ import (
 
 	"EffectiveMobile/internal/repository"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
@@ -21,6 +22,7 @@ type SubscriptionServiceSuite struct {
 	ctrl                *gomock.Controller
 	serviceRepo         *MockServicesRepository
 	subscriptionRepo    *MockSubscriptionRepository
+	transactor          *MockTransactor
 	subscriptionService *SubscriptionService
 	logger              *slog.Logger
 	ctx                 context.Context
@@ -34,11 +36,18 @@ func (s *SubscriptionServiceSuite) SetupTest() {
 	s.ctrl = gomock.NewController(s.T())
 	s.serviceRepo = NewMockServicesRepository(s.ctrl)
 	s.subscriptionRepo = NewMockSubscriptionRepository(s.ctrl)
+	s.transactor = NewMockTransactor(s.ctrl)
+	s.transactor.EXPECT().
+		WithinTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
 
 	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	s.ctx = context.Background()
 
-	s.subscriptionService = NewSubscriptionService(s.serviceRepo, s.subscriptionRepo, s.logger)
+	s.subscriptionService = NewSubscriptionService(s.serviceRepo, s.subscriptionRepo, s.transactor, s.logger)
 }
 
 func (s *SubscriptionServiceSuite) TearDownTest() {
@@ -73,6 +82,22 @@ func (s *SubscriptionServiceSuite) TestCreateSubscription_Success() {
 	s.Equal(subscriptionID, result)
 }
 
+func (s *SubscriptionServiceSuite) TestCreateSubscription_NormalizesServiceName() {
+	userID := uuid.New()
+
+	s.serviceRepo.EXPECT().
+		GetOrCreateServiceID(s.ctx, "YouTube Premium").
+		Return(4, nil)
+
+	s.subscriptionRepo.EXPECT().
+		CreateSubscription(gomock.Any(), gomock.Any()).
+		Return(int64(1), nil)
+
+	_, err := s.subscriptionService.CreateSubscription(s.ctx, "  YouTube   Premium ", 300, userID, "01-2024", "")
+
+	s.NoError(err)
+}
+
 func (s *SubscriptionServiceSuite) TestCreateSubscription_WithEndDate() {
 	userID := uuid.New()
 	serviceName := "Netflix"
@@ -159,10 +184,10 @@ func (s *SubscriptionServiceSuite) TestGetSubscription_Success() {
 	}
 
 	s.subscriptionRepo.EXPECT().
-		GetSubscription(s.ctx, subscriptionID).
+		GetSubscription(s.ctx, subscriptionID, false).
 		Return(expectedSubscription, nil)
 
-	result, err := s.subscriptionService.GetSubscription(s.ctx, subscriptionID)
+	result, err := s.subscriptionService.GetSubscription(s.ctx, subscriptionID, false)
 
 	s.NoError(err)
 	s.Equal(&expectedSubscription, result)
@@ -173,10 +198,10 @@ func (s *SubscriptionServiceSuite) TestGetSubscription_NotFound() {
 	notFoundError := repository.ErrSubscriptionNotFound
 
 	s.subscriptionRepo.EXPECT().
-		GetSubscription(s.ctx, subscriptionID).
+		GetSubscription(s.ctx, subscriptionID, false).
 		Return(repository.Subscription{}, notFoundError)
 
-	result, err := s.subscriptionService.GetSubscription(s.ctx, subscriptionID)
+	result, err := s.subscriptionService.GetSubscription(s.ctx, subscriptionID, false)
 
 	s.Error(err)
 	s.Nil(result)
@@ -189,6 +214,9 @@ func (s *SubscriptionServiceSuite) TestUpdateSubscription_Success() {
 	startDate := "02-2024"
 	endDate := "04-2024"
 
+	s.subscriptionRepo.EXPECT().
+		GetSubscriptionForUpdate(s.ctx, subscriptionID).
+		Return(repository.Subscription{ID: subscriptionID, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, nil)
 	s.subscriptionRepo.EXPECT().
 		UpdateSubscription(s.ctx, repository.UpdateSubscriptionParams{
 			ID:        subscriptionID,
@@ -197,11 +225,12 @@ func (s *SubscriptionServiceSuite) TestUpdateSubscription_Success() {
 			StartDate: &[]time.Time{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}[0],
 			EndDate:   &[]time.Time{time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}[0],
 		}).
-		Return(nil)
+		Return(2, nil)
 
-	err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, nil, &price, &startDate, &endDate)
+	newVersion, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{Price: &price, StartDate: &startDate, EndDate: &endDate}, nil)
 
 	s.NoError(err)
+	s.Equal(2, newVersion)
 }
 
 func (s *SubscriptionServiceSuite) TestUpdateSubscription_NotFound() {
@@ -211,16 +240,10 @@ func (s *SubscriptionServiceSuite) TestUpdateSubscription_NotFound() {
 	notFoundError := repository.ErrSubscriptionNotFound
 
 	s.subscriptionRepo.EXPECT().
-		UpdateSubscription(s.ctx, repository.UpdateSubscriptionParams{
-			ID:        subscriptionID,
-			ServiceID: nil,
-			PriceRub:  &price,
-			StartDate: &[]time.Time{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}[0],
-			EndDate:   nil,
-		}).
-		Return(notFoundError)
+		GetSubscriptionForUpdate(s.ctx, subscriptionID).
+		Return(repository.Subscription{}, notFoundError)
 
-	err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, nil, &price, &startDate, nil)
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{Price: &price, StartDate: &startDate}, nil)
 
 	s.Error(err)
 	s.Equal(notFoundError, err)
@@ -244,9 +267,9 @@ func (s *SubscriptionServiceSuite) TestUpdateSubscription_WithServiceName() {
 			StartDate: nil,
 			EndDate:   nil,
 		}).
-		Return(nil)
+		Return(2, nil)
 
-	err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, &serviceName, &price, nil, nil)
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{ServiceName: &serviceName, Price: &price}, nil)
 
 	s.NoError(err)
 }
@@ -256,6 +279,9 @@ func (s *SubscriptionServiceSuite) TestUpdateSubscription_Conflict() {
 	startDate := "02-2024"
 	conflictError := repository.ErrSubscriptionAlreadyExists
 
+	s.subscriptionRepo.EXPECT().
+		GetSubscriptionForUpdate(s.ctx, subscriptionID).
+		Return(repository.Subscription{ID: subscriptionID, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, nil)
 	s.subscriptionRepo.EXPECT().
 		UpdateSubscription(s.ctx, repository.UpdateSubscriptionParams{
 			ID:        subscriptionID,
@@ -264,22 +290,159 @@ func (s *SubscriptionServiceSuite) TestUpdateSubscription_Conflict() {
 			StartDate: &[]time.Time{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}[0],
 			EndDate:   nil,
 		}).
-		Return(conflictError)
+		Return(0, conflictError)
 
-	err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, nil, nil, &startDate, nil)
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{StartDate: &startDate}, nil)
 
 	s.Error(err)
 	s.Equal(conflictError, err)
 }
 
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_PassesVersion() {
+	subscriptionID := int64(123)
+	price := 600
+	version := 4
+
+	s.subscriptionRepo.EXPECT().
+		UpdateSubscription(s.ctx, repository.UpdateSubscriptionParams{
+			ID:       subscriptionID,
+			PriceRub: &price,
+			Version:  &version,
+		}).
+		Return(0, repository.ErrVersionMismatch)
+
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{Price: &price}, &version)
+
+	s.ErrorIs(err, repository.ErrVersionMismatch)
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_PriceEffectiveFrom() {
+	subscriptionID := int64(123)
+	price := 600
+	effectiveFrom := "03-2025"
+	expectedEffectiveFrom := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	s.subscriptionRepo.EXPECT().
+		GetSubscriptionForUpdate(s.ctx, subscriptionID).
+		Return(repository.Subscription{ID: subscriptionID, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, nil)
+
+	s.subscriptionRepo.EXPECT().
+		UpdateSubscription(s.ctx, repository.UpdateSubscriptionParams{
+			ID:                 subscriptionID,
+			PriceRub:           &price,
+			PriceEffectiveFrom: &expectedEffectiveFrom,
+		}).
+		Return(2, nil)
+
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{Price: &price, PriceEffectiveFrom: &effectiveFrom}, nil)
+
+	s.NoError(err)
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_PriceEffectiveFromOutsideLifetime() {
+	subscriptionID := int64(123)
+	price := 600
+	endDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	s.subscriptionRepo.EXPECT().
+		GetSubscriptionForUpdate(s.ctx, subscriptionID).
+		Return(repository.Subscription{ID: subscriptionID, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &endDate}, nil).
+		Times(2)
+
+	for _, month := range []string{"12-2024", "07-2025"} {
+		_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{Price: &price, PriceEffectiveFrom: &month}, nil)
+		s.ErrorIs(err, ErrValidation, month)
+	}
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_DatesCheckedAgainstCurrent() {
+	subscriptionID := int64(123)
+	endDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	startAfterEnd := "12-2025"
+	endBeforeStart := "12-2024"
+
+	s.subscriptionRepo.EXPECT().
+		GetSubscriptionForUpdate(s.ctx, subscriptionID).
+		Return(repository.Subscription{ID: subscriptionID, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &endDate}, nil).
+		Times(3)
+
+	for name, patch := range map[string]SubscriptionPatch{
+		"start after end":     {StartDate: &startAfterEnd},
+		"end before start":    {EndDate: &endBeforeStart},
+		"start after new end": {StartDate: &startAfterEnd, EndDate: &endBeforeStart},
+	} {
+		_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, patch, nil)
+		s.ErrorIs(err, ErrValidation, name)
+	}
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_PriceEffectiveFromWithoutPrice() {
+	effectiveFrom := "03-2025"
+
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, 123, SubscriptionPatch{PriceEffectiveFrom: &effectiveFrom}, nil)
+
+	s.ErrorIs(err, ErrValidation)
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_ResetPriceHistory() {
+	subscriptionID := int64(123)
+	price := 600
+
+	s.subscriptionRepo.EXPECT().
+		UpdateSubscription(s.ctx, repository.UpdateSubscriptionParams{
+			ID:          subscriptionID,
+			PriceRub:    &price,
+			ResetPrices: true,
+		}).
+		Return(2, nil)
+
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{Price: &price, ResetPriceHistory: true}, nil)
+
+	s.NoError(err)
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_ClearEndDate() {
+	subscriptionID := int64(123)
+
+	s.subscriptionRepo.EXPECT().
+		UpdateSubscription(s.ctx, repository.UpdateSubscriptionParams{
+			ID:           subscriptionID,
+			ClearEndDate: true,
+		}).
+		Return(2, nil)
+
+	_, err := s.subscriptionService.UpdateSubscription(s.ctx, subscriptionID, SubscriptionPatch{ClearEndDate: true}, nil)
+
+	s.NoError(err)
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_InvalidPatch() {
+	endDate := "12-2024"
+	empty := ""
+	price := 600
+
+	for name, patch := range map[string]SubscriptionPatch{
+		"empty":               {},
+		"set and clear":       {EndDate: &endDate, ClearEndDate: true},
+		"empty end date":      {EndDate: &empty},
+		"empty start date":    {StartDate: &empty},
+		"empty service name":  {ServiceName: &empty},
+		"reset without price": {ResetPriceHistory: true},
+		"reset from a month":  {Price: &price, PriceEffectiveFrom: &endDate, ResetPriceHistory: true},
+	} {
+		_, err := s.subscriptionService.UpdateSubscription(s.ctx, 123, patch, nil)
+		s.ErrorIs(err, ErrValidation, name)
+	}
+}
+
 func (s *SubscriptionServiceSuite) TestDeleteSubscription_Success() {
 	subscriptionID := int64(123)
 
 	s.subscriptionRepo.EXPECT().
-		DeleteSubscription(s.ctx, subscriptionID).
+		DeleteSubscription(s.ctx, subscriptionID, nil, "").
 		Return(nil)
 
-	err := s.subscriptionService.DeleteSubscription(s.ctx, subscriptionID)
+	err := s.subscriptionService.DeleteSubscription(s.ctx, subscriptionID, nil)
 
 	s.NoError(err)
 }
@@ -289,15 +452,87 @@ func (s *SubscriptionServiceSuite) TestDeleteSubscription_NotFound() {
 	notFoundError := repository.ErrSubscriptionNotFound
 
 	s.subscriptionRepo.EXPECT().
-		DeleteSubscription(s.ctx, subscriptionID).
+		DeleteSubscription(s.ctx, subscriptionID, nil, "").
 		Return(notFoundError)
 
-	err := s.subscriptionService.DeleteSubscription(s.ctx, subscriptionID)
+	err := s.subscriptionService.DeleteSubscription(s.ctx, subscriptionID, nil)
 
 	s.Error(err)
 	s.Equal(notFoundError, err)
 }
 
+func (s *SubscriptionServiceSuite) TestRestoreSubscription_Success() {
+	subscriptionID := int64(123)
+
+	s.subscriptionRepo.EXPECT().
+		RestoreSubscription(s.ctx, subscriptionID, "").
+		Return(nil)
+
+	err := s.subscriptionService.RestoreSubscription(s.ctx, subscriptionID)
+
+	s.NoError(err)
+}
+
+func (s *SubscriptionServiceSuite) TestRestoreSubscription_NotDeleted() {
+	subscriptionID := int64(123)
+
+	s.subscriptionRepo.EXPECT().
+		RestoreSubscription(s.ctx, subscriptionID, "").
+		Return(repository.ErrSubscriptionNotDeleted)
+
+	err := s.subscriptionService.RestoreSubscription(s.ctx, subscriptionID)
+
+	s.ErrorIs(err, repository.ErrSubscriptionNotDeleted)
+}
+
+func (s *SubscriptionServiceSuite) TestRestoreSubscription_InvalidID() {
+	err := s.subscriptionService.RestoreSubscription(s.ctx, 0)
+
+	s.ErrorIs(err, ErrValidation)
+}
+
+func (s *SubscriptionServiceSuite) TestDeleteSubscription_PassesRequestID() {
+	subscriptionID := int64(123)
+	ctx := context.WithValue(s.ctx, middleware.RequestIDKey, "req-42")
+
+	s.subscriptionRepo.EXPECT().
+		DeleteSubscription(ctx, subscriptionID, nil, "req-42").
+		Return(nil)
+
+	err := s.subscriptionService.DeleteSubscription(ctx, subscriptionID, nil)
+
+	s.NoError(err)
+}
+
+func (s *SubscriptionServiceSuite) TestGetSubscriptionHistory_Success() {
+	subscriptionID := int64(123)
+	records := []repository.SubscriptionHistoryRecord{
+		{ID: 1, SubscriptionID: subscriptionID, Action: repository.HistoryActionCreated},
+		{ID: 2, SubscriptionID: subscriptionID, Action: repository.HistoryActionUpdated},
+	}
+
+	s.subscriptionRepo.EXPECT().
+		ListSubscriptionHistory(s.ctx, subscriptionID).
+		Return(records, nil)
+
+	result, err := s.subscriptionService.GetSubscriptionHistory(s.ctx, subscriptionID)
+
+	s.NoError(err)
+	s.Equal(records, result)
+}
+
+func (s *SubscriptionServiceSuite) TestGetSubscriptionHistory_NotFound() {
+	subscriptionID := int64(123)
+
+	s.subscriptionRepo.EXPECT().
+		ListSubscriptionHistory(s.ctx, subscriptionID).
+		Return(nil, repository.ErrSubscriptionNotFound)
+
+	_, err := s.subscriptionService.GetSubscriptionHistory(s.ctx, subscriptionID)
+
+	s.ErrorIs(err, repository.ErrSubscriptionNotFound)
+}
+
 func (s *SubscriptionServiceSuite) TestListSubscriptions_Success() {
 	userID := uuid.New()
 	limit := 10
@@ -317,21 +552,21 @@ func (s *SubscriptionServiceSuite) TestListSubscriptions_Success() {
 
 	s.subscriptionRepo.EXPECT().
 		ListSubscriptions(s.ctx, repository.ListSubscriptionsParams{
-			UserID: &userID,
-			Limit:  limit,
-			Offset: offset,
+			UserIDs: []uuid.UUID{userID},
+			Limit:   limit,
+			Offset:  offset,
 		}).
-		Return(expectedSubscriptions, expectedTotal, nil)
+		Return(&repository.SubscriptionPage{Subscriptions: expectedSubscriptions, Total: &expectedTotal}, nil)
 
-	subscriptions, total, err := s.subscriptionService.ListSubscriptions(s.ctx, repository.ListSubscriptionsParams{
-		UserID: &userID,
-		Limit:  limit,
-		Offset: offset,
+	page, err := s.subscriptionService.ListSubscriptions(s.ctx, repository.ListSubscriptionsParams{
+		UserIDs: []uuid.UUID{userID},
+		Limit:   limit,
+		Offset:  offset,
 	})
 
 	s.NoError(err)
-	s.Equal(expectedSubscriptions, subscriptions)
-	s.Equal(expectedTotal, total)
+	s.Equal(expectedSubscriptions, page.Subscriptions)
+	s.Equal(&expectedTotal, page.Total)
 }
 
 func (s *SubscriptionServiceSuite) TestListSubscriptions_Error() {
@@ -342,20 +577,88 @@ func (s *SubscriptionServiceSuite) TestListSubscriptions_Error() {
 
 	s.subscriptionRepo.EXPECT().
 		ListSubscriptions(s.ctx, repository.ListSubscriptionsParams{
-			UserID: &userID,
-			Limit:  limit,
-			Offset: offset,
+			UserIDs: []uuid.UUID{userID},
+			Limit:   limit,
+			Offset:  offset,
 		}).
-		Return(nil, 0, repoError)
+		Return(nil, repoError)
 
-	subscriptions, total, err := s.subscriptionService.ListSubscriptions(s.ctx, repository.ListSubscriptionsParams{
-		UserID: &userID,
-		Limit:  limit,
-		Offset: offset,
+	page, err := s.subscriptionService.ListSubscriptions(s.ctx, repository.ListSubscriptionsParams{
+		UserIDs: []uuid.UUID{userID},
+		Limit:   limit,
+		Offset:  offset,
 	})
 
 	s.Error(err)
-	s.Nil(subscriptions)
-	s.Equal(0, total)
+	s.Nil(page)
 	s.Equal(repoError, err)
 }
+
+func (s *SubscriptionServiceSuite) TestNotifyEndingSoon_CountsNewlyMarked() {
+	endDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	subscriptions := []repository.Subscription{
+		{ID: 1, EndDate: &endDate},
+		{ID: 2, EndDate: &endDate},
+	}
+
+	s.subscriptionRepo.EXPECT().
+		ListEndingSoon(s.ctx, gomock.Any(), gomock.Any()).
+		Return(subscriptions, nil)
+	s.subscriptionRepo.EXPECT().MarkEndingSoonNotified(s.ctx, int64(1), endDate).Return(true, nil)
+	// Marked concurrently by another instance.
+	s.subscriptionRepo.EXPECT().MarkEndingSoonNotified(s.ctx, int64(2), endDate).Return(false, nil)
+
+	count, err := s.subscriptionService.NotifyEndingSoon(s.ctx, 7*24*time.Hour)
+
+	s.NoError(err)
+	s.Equal(1, count)
+}
+
+type txCtxKey struct{}
+
+// inTx returns a service whose transactions hand fn a marked context, so that the
+// repository calls expected with the returned context must run inside WithinTx.
+func (s *SubscriptionServiceSuite) inTx() (*SubscriptionService, context.Context) {
+	transactor := NewMockTransactor(s.ctrl)
+	txCtx := context.WithValue(s.ctx, txCtxKey{}, true)
+
+	transactor.EXPECT().
+		WithinTx(s.ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(context.Context) error) error {
+			return fn(txCtx)
+		}).
+		MinTimes(1)
+
+	return NewSubscriptionService(s.serviceRepo, s.subscriptionRepo, transactor, s.logger), txCtx
+}
+
+func (s *SubscriptionServiceSuite) TestCreateSubscription_ServiceAndSubscriptionInOneTransaction() {
+	subscriptionService, txCtx := s.inTx()
+
+	s.serviceRepo.EXPECT().
+		GetOrCreateServiceID(txCtx, "Netflix").
+		Return(1, nil)
+	s.subscriptionRepo.EXPECT().
+		CreateSubscription(txCtx, gomock.Any()).
+		Return(int64(0), repository.ErrSubscriptionAlreadyExists)
+
+	_, err := subscriptionService.CreateSubscription(s.ctx, "Netflix", 500, uuid.New(), "01-2024", "")
+
+	s.ErrorIs(err, repository.ErrSubscriptionAlreadyExists)
+}
+
+func (s *SubscriptionServiceSuite) TestUpdateSubscription_ServiceAndSubscriptionInOneTransaction() {
+	subscriptionService, txCtx := s.inTx()
+	serviceName := "Kinopoisk"
+
+	s.serviceRepo.EXPECT().
+		GetOrCreateServiceID(txCtx, serviceName).
+		Return(2, nil)
+	s.subscriptionRepo.EXPECT().
+		UpdateSubscription(txCtx, gomock.Any()).
+		Return(0, repository.ErrVersionMismatch)
+
+	_, err := subscriptionService.UpdateSubscription(s.ctx, 123, SubscriptionPatch{ServiceName: &serviceName}, nil)
+
+	s.ErrorIs(err, repository.ErrVersionMismatch)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func (s *StatsServiceSuite) TestGetTotalCost_RecordsSpan() {
+	exporter := tracing.NewInMemoryExporter()
+	tracing.SetTracer(tracing.NewTracer(exporter, nil))
+	defer tracing.SetTracer(nil)
+
+	ctx, parent := tracing.Start(s.ctx, "handler")
+
+	s.statsRepo.EXPECT().
+		GetTotalCost(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ repository.GetTotalCostParams) (repository.TotalCostStats, error) {
+			_, query := tracing.Start(ctx, "SELECT")
+			query.End()
+			return repository.TotalCostStats{}, errors.New("connection reset")
+		})
+
+	_, err := s.statsService.GetTotalCost(ctx, nil, nil, nil, nil, false)
+	parent.End()
+
+	s.Error(err)
+	spans := exporter.Spans()
+	s.Require().Len(spans, 3)
+	query, service, handler := spans[0], spans[1], spans[2]
+
+	s.Equal("service.stats.GetTotalCost", service.Name)
+	s.Equal(handler.SpanID, service.ParentSpanID)
+	s.Equal(service.SpanID, query.ParentSpanID)
+	s.Equal("connection reset", service.Error)
+}
@@ -0,0 +1,166 @@
+package service
+
+//go:generate mockgen -destination=webhook_mock.go -source=webhook.go -package=service
+
+import (
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultDeliveryLogLimit = 100
+	minWebhookSecretLength  = 16
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint repository.WebhookEndpoint) (int64, error)
+	GetEndpoint(ctx context.Context, id int64) (repository.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]repository.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int64) error
+	ListDeliveryLog(ctx context.Context, endpointID int64, limit int) ([]repository.WebhookDeliveryLogRecord, error)
+}
+
+// WebhookService manages webhook endpoints. Events are queued for them by
+// webhook.Publisher and delivered by webhook.Dispatcher.
+type WebhookService struct {
+	webhookRepo WebhookRepository
+	log         *slog.Logger
+}
+
+func NewWebhookService(webhookRepo WebhookRepository, log *slog.Logger) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		log:         log,
+	}
+}
+
+// CreateEndpoint registers an endpoint. Without a secret a random one is generated;
+// the endpoint is returned with its secret, which is needed to verify signatures.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, rawURL, secret string, events []string) (*repository.WebhookEndpoint, error) {
+	const op = "service.webhook.CreateEndpoint"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	endpointURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https url", ErrValidation)
+	}
+
+	for _, event := range events {
+		if !slices.Contains(repository.EventTypes, event) {
+			return nil, fmt.Errorf("%w: unknown event type: %s", ErrValidation, event)
+		}
+	}
+
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			span.RecordError(err)
+			log.Error("generate secret failed", slog.String("err", err.Error()))
+			return nil, err
+		}
+	} else if len(secret) < minWebhookSecretLength {
+		return nil, fmt.Errorf("%w: secret must be at least %d characters long", ErrValidation, minWebhookSecretLength)
+	}
+
+	endpoint := repository.WebhookEndpoint{
+		URL:    endpointURL.String(),
+		Secret: secret,
+		Events: slices.Compact(slices.Sorted(slices.Values(events))),
+	}
+
+	endpoint.ID, err = s.webhookRepo.CreateEndpoint(ctx, endpoint)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("create endpoint failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (s *WebhookService) GetEndpoint(ctx context.Context, id int64) (*repository.WebhookEndpoint, error) {
+	const op = "service.webhook.GetEndpoint"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("get endpoint failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (s *WebhookService) ListEndpoints(ctx context.Context) ([]repository.WebhookEndpoint, error) {
+	const op = "service.webhook.ListEndpoints"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	endpoints, err := s.webhookRepo.ListEndpoints(ctx)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("list endpoints failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id int64) error {
+	const op = "service.webhook.DeleteEndpoint"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.webhookRepo.DeleteEndpoint(ctx, id); err != nil {
+		span.RecordError(err)
+		log.Error("delete endpoint failed", slog.String("err", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the latest delivery attempts to an endpoint.
+func (s *WebhookService) ListDeliveries(ctx context.Context, endpointID int64) ([]repository.WebhookDeliveryLogRecord, error) {
+	const op = "service.webhook.ListDeliveries"
+	log := s.log.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if _, err := s.webhookRepo.GetEndpoint(ctx, endpointID); err != nil {
+		span.RecordError(err)
+		log.Error("get endpoint failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	records, err := s.webhookRepo.ListDeliveryLog(ctx, endpointID, defaultDeliveryLogLimit)
+	if err != nil {
+		span.RecordError(err)
+		log.Error("list delivery log failed", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"EffectiveMobile/internal/repository"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type WebhookServiceSuite struct {
+	suite.Suite
+
+	ctrl           *gomock.Controller
+	webhookRepo    *MockWebhookRepository
+	webhookService *WebhookService
+	logger         *slog.Logger
+	ctx            context.Context
+}
+
+func TestWebhookService(t *testing.T) {
+	suite.Run(t, &WebhookServiceSuite{})
+}
+
+func (s *WebhookServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.webhookRepo = NewMockWebhookRepository(s.ctrl)
+
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	s.ctx = context.Background()
+
+	s.webhookService = NewWebhookService(s.webhookRepo, s.logger)
+}
+
+func (s *WebhookServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *WebhookServiceSuite) TestCreateEndpoint_NormalizesEvents() {
+	s.webhookRepo.EXPECT().
+		CreateEndpoint(s.ctx, repository.WebhookEndpoint{
+			URL:    "https://example.com/hooks",
+			Secret: "0123456789abcdef",
+			Events: []string{repository.EventSubscriptionCreated, repository.EventSubscriptionDeleted},
+		}).
+		Return(int64(7), nil)
+
+	endpoint, err := s.webhookService.CreateEndpoint(s.ctx, " https://example.com/hooks ", "0123456789abcdef", []string{
+		repository.EventSubscriptionDeleted,
+		repository.EventSubscriptionCreated,
+		repository.EventSubscriptionDeleted,
+	})
+
+	s.Require().NoError(err)
+	s.Equal(int64(7), endpoint.ID)
+}
+
+func (s *WebhookServiceSuite) TestCreateEndpoint_GeneratesSecret() {
+	var stored repository.WebhookEndpoint
+	s.webhookRepo.EXPECT().
+		CreateEndpoint(s.ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, endpoint repository.WebhookEndpoint) (int64, error) {
+			stored = endpoint
+			return 1, nil
+		})
+
+	endpoint, err := s.webhookService.CreateEndpoint(s.ctx, "http://example.com/hooks", "", nil)
+
+	s.Require().NoError(err)
+	s.Len(endpoint.Secret, 64)
+	s.Equal(stored.Secret, endpoint.Secret)
+}
+
+func (s *WebhookServiceSuite) TestCreateEndpoint_Validation() {
+	cases := []struct {
+		name   string
+		url    string
+		secret string
+		events []string
+	}{
+		{name: "relative url", url: "/hooks"},
+		{name: "unsupported scheme", url: "ftp://example.com/hooks"},
+		{name: "unknown event", url: "https://example.com/hooks", events: []string{"subscription.renamed"}},
+		{name: "short secret", url: "https://example.com/hooks", secret: "short"},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			_, err := s.webhookService.CreateEndpoint(s.ctx, tc.url, tc.secret, tc.events)
+
+			s.ErrorIs(err, ErrValidation)
+		})
+	}
+}
+
+func (s *WebhookServiceSuite) TestListDeliveries_EndpointNotFound() {
+	s.webhookRepo.EXPECT().
+		GetEndpoint(s.ctx, int64(3)).
+		Return(repository.WebhookEndpoint{}, repository.ErrWebhookEndpointNotFound)
+
+	_, err := s.webhookService.ListDeliveries(s.ctx, 3)
+
+	s.ErrorIs(err, repository.ErrWebhookEndpointNotFound)
+}
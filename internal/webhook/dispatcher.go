@@ -0,0 +1,223 @@
+package webhook
+
+//go:generate mockgen -destination=dispatcher_mock.go -source=dispatcher.go -package=webhook
+
+import (
+	"EffectiveMobile/internal/outbox"
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+	maxErrorBody    = 512
+)
+
+type DeliveryRepository interface {
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]repository.WebhookDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, a repository.WebhookDeliveryAttempt) error
+}
+
+// EndingSoonNotifier is implemented by service.SubscriptionService.
+type EndingSoonNotifier interface {
+	NotifyEndingSoon(ctx context.Context, within time.Duration) (int, error)
+}
+
+type Config struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	RequestTimeout time.Duration
+	// EndingSoonWithin is how long before its last month starts a subscription is
+	// announced as ending soon; EndingSoonInterval is how often that is checked.
+	EndingSoonWithin   time.Duration
+	EndingSoonInterval time.Duration
+}
+
+// Dispatcher delivers queued webhook events. Every delivery is a signed POST of the
+// event JSON; a failed one is retried with exponential backoff until MaxAttempts.
+type Dispatcher struct {
+	repo     DeliveryRepository
+	notifier EndingSoonNotifier
+	client   *http.Client
+	cfg      Config
+	log      *slog.Logger
+}
+
+func NewDispatcher(repo DeliveryRepository, notifier EndingSoonNotifier, cfg Config, log *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:     repo,
+		notifier: notifier,
+		client:   &http.Client{Timeout: cfg.RequestTimeout},
+		cfg:      cfg,
+		log:      log.With(slog.String("op", "webhook.Dispatcher")),
+	}
+}
+
+// Run polls for due deliveries until ctx is done. A round that already started is
+// finished first, so Run returns at most RequestTimeout after ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var lastEndingSoonCheck time.Time
+	for {
+		roundCtx := context.WithoutCancel(ctx)
+
+		if d.notifier != nil && time.Since(lastEndingSoonCheck) >= d.cfg.EndingSoonInterval {
+			lastEndingSoonCheck = time.Now()
+			if _, err := d.notifier.NotifyEndingSoon(roundCtx, d.cfg.EndingSoonWithin); err != nil {
+				d.log.Error("notify ending subscriptions failed", slog.String("err", err.Error()))
+			}
+		}
+
+		if _, err := d.dispatchDue(roundCtx); err != nil {
+			d.log.Error("dispatch webhooks failed", slog.String("err", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue sends one batch of due deliveries concurrently and returns its size.
+func (d *Dispatcher) dispatchDue(ctx context.Context) (int, error) {
+	// The lease outlives a delivery, so only deliveries of a stopped process are
+	// claimed again.
+	lease := d.cfg.RequestTimeout + time.Minute
+
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, d.cfg.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			attempt := d.deliver(ctx, delivery)
+			if err := d.repo.RecordDeliveryAttempt(ctx, attempt); err != nil {
+				d.log.Error("record delivery attempt failed",
+					slog.Int64("delivery_id", delivery.ID),
+					slog.String("err", err.Error()),
+				)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery repository.WebhookDelivery) repository.WebhookDeliveryAttempt {
+	ctx, span := tracing.Start(ctx, "webhook.Dispatcher.deliver",
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int64("endpoint_id", delivery.EndpointID),
+		slog.Int("attempt", delivery.Attempt),
+	)
+	defer span.End()
+
+	attempt := repository.WebhookDeliveryAttempt{Delivery: delivery}
+
+	started := time.Now()
+	statusCode, err := d.send(ctx, delivery)
+	attempt.Duration = time.Since(started)
+
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+	if err == nil {
+		attempt.Delivered = true
+		return attempt
+	}
+
+	span.RecordError(err)
+	message := err.Error()
+	attempt.Error = &message
+	if delivery.Attempt < d.cfg.MaxAttempts {
+		next := time.Now().Add(outbox.Backoff(d.cfg.InitialBackoff, d.cfg.MaxBackoff, delivery.Attempt))
+		attempt.NextAttemptAt = &next
+	}
+
+	d.log.Warn("webhook delivery failed",
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int64("endpoint_id", delivery.EndpointID),
+		slog.Int("attempt", delivery.Attempt),
+		slog.String("err", message),
+	)
+
+	return attempt
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery repository.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, delivery.EventID.String())
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			d.log.Warn("resp.Body.Close():", slog.String("error", err.Error()))
+		}
+	}()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Webhook-Signature value: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the endpoint secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature made by Sign; receivers should also reject old timestamps.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dispatcher.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=dispatcher_mock.go -source=dispatcher.go -package=webhook
+//
+
+// Package webhook is a generated GoMock package.
+package webhook
+
+import (
+	repository "EffectiveMobile/internal/repository"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeliveryRepository is a mock of DeliveryRepository interface.
+type MockDeliveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDeliveryRepositoryMockRecorder is the mock recorder for MockDeliveryRepository.
+type MockDeliveryRepositoryMockRecorder struct {
+	mock *MockDeliveryRepository
+}
+
+// NewMockDeliveryRepository creates a new mock instance.
+func NewMockDeliveryRepository(ctrl *gomock.Controller) *MockDeliveryRepository {
+	mock := &MockDeliveryRepository{ctrl: ctrl}
+	mock.recorder = &MockDeliveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryRepository) EXPECT() *MockDeliveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockDeliveryRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", ctx, limit, lease)
+	ret0, _ := ret[0].([]repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockDeliveryRepositoryMockRecorder) ClaimDueDeliveries(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockDeliveryRepository)(nil).ClaimDueDeliveries), ctx, limit, lease)
+}
+
+// RecordDeliveryAttempt mocks base method.
+func (m *MockDeliveryRepository) RecordDeliveryAttempt(ctx context.Context, a repository.WebhookDeliveryAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDeliveryAttempt", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDeliveryAttempt indicates an expected call of RecordDeliveryAttempt.
+func (mr *MockDeliveryRepositoryMockRecorder) RecordDeliveryAttempt(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeliveryAttempt", reflect.TypeOf((*MockDeliveryRepository)(nil).RecordDeliveryAttempt), ctx, a)
+}
+
+// MockEndingSoonNotifier is a mock of EndingSoonNotifier interface.
+type MockEndingSoonNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockEndingSoonNotifierMockRecorder
+	isgomock struct{}
+}
+
+// MockEndingSoonNotifierMockRecorder is the mock recorder for MockEndingSoonNotifier.
+type MockEndingSoonNotifierMockRecorder struct {
+	mock *MockEndingSoonNotifier
+}
+
+// NewMockEndingSoonNotifier creates a new mock instance.
+func NewMockEndingSoonNotifier(ctrl *gomock.Controller) *MockEndingSoonNotifier {
+	mock := &MockEndingSoonNotifier{ctrl: ctrl}
+	mock.recorder = &MockEndingSoonNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEndingSoonNotifier) EXPECT() *MockEndingSoonNotifierMockRecorder {
+	return m.recorder
+}
+
+// NotifyEndingSoon mocks base method.
+func (m *MockEndingSoonNotifier) NotifyEndingSoon(ctx context.Context, within time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyEndingSoon", ctx, within)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotifyEndingSoon indicates an expected call of NotifyEndingSoon.
+func (mr *MockEndingSoonNotifierMockRecorder) NotifyEndingSoon(ctx, within any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyEndingSoon", reflect.TypeOf((*MockEndingSoonNotifier)(nil).NotifyEndingSoon), ctx, within)
+}
@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/pkg/tracing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type DispatcherSuite struct {
+	suite.Suite
+
+	ctrl       *gomock.Controller
+	repo       *MockDeliveryRepository
+	dispatcher *Dispatcher
+	ctx        context.Context
+}
+
+func TestDispatcher(t *testing.T) {
+	suite.Run(t, &DispatcherSuite{})
+}
+
+func (s *DispatcherSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.repo = NewMockDeliveryRepository(s.ctrl)
+	s.ctx = context.Background()
+
+	s.dispatcher = NewDispatcher(s.repo, nil, Config{
+		PollInterval:   time.Second,
+		BatchSize:      10,
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Hour,
+		RequestTimeout: time.Second,
+	}, slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+}
+
+func (s *DispatcherSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+// receiver is a webhook endpoint that verifies signatures and answers with status.
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+	verified []bool
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+
+	rc.mu.Lock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	rc.verified = append(rc.verified, Verify(rc.secret, timestamp, body, r.Header.Get(HeaderSignature)))
+	rc.mu.Unlock()
+
+	w.WriteHeader(rc.status)
+}
+
+func (s *DispatcherSuite) delivery(url string, attempt int) repository.WebhookDelivery {
+	return repository.WebhookDelivery{
+		ID:         1,
+		EndpointID: 2,
+		EventID:    uuid.New(),
+		EventType:  repository.EventSubscriptionCreated,
+		Payload:    []byte(`{"id":"x","type":"subscription.created"}`),
+		Attempt:    attempt,
+		URL:        url,
+		Secret:     "0123456789abcdef",
+	}
+}
+
+func (s *DispatcherSuite) TestDispatchDue_Delivered() {
+	rc := &receiver{secret: "0123456789abcdef", status: http.StatusNoContent}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	delivery := s.delivery(server.URL, 1)
+	s.repo.EXPECT().ClaimDueDeliveries(s.ctx, 10, gomock.Any()).Return([]repository.WebhookDelivery{delivery}, nil)
+
+	var recorded repository.WebhookDeliveryAttempt
+	s.repo.EXPECT().
+		RecordDeliveryAttempt(s.ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, a repository.WebhookDeliveryAttempt) error {
+			recorded = a
+			return nil
+		})
+
+	count, err := s.dispatcher.dispatchDue(s.ctx)
+
+	s.Require().NoError(err)
+	s.Equal(1, count)
+	s.Require().Len(rc.requests, 1)
+	s.True(rc.verified[0])
+	s.Equal(delivery.Payload, rc.bodies[0])
+	s.Equal(delivery.EventID.String(), rc.requests[0].Header.Get(HeaderEventID))
+	s.Equal(repository.EventSubscriptionCreated, rc.requests[0].Header.Get(HeaderEventType))
+
+	s.True(recorded.Delivered)
+	s.Equal(http.StatusNoContent, *recorded.StatusCode)
+	s.Nil(recorded.Error)
+	s.Nil(recorded.NextAttemptAt)
+}
+
+func (s *DispatcherSuite) TestDispatchDue_FailedAttemptIsRescheduled() {
+	rc := &receiver{secret: "0123456789abcdef", status: http.StatusInternalServerError}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	s.repo.EXPECT().ClaimDueDeliveries(s.ctx, 10, gomock.Any()).Return([]repository.WebhookDelivery{s.delivery(server.URL, 2)}, nil)
+
+	var recorded repository.WebhookDeliveryAttempt
+	s.repo.EXPECT().
+		RecordDeliveryAttempt(s.ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, a repository.WebhookDeliveryAttempt) error {
+			recorded = a
+			return nil
+		})
+
+	before := time.Now()
+	_, err := s.dispatcher.dispatchDue(s.ctx)
+
+	s.Require().NoError(err)
+	s.False(recorded.Delivered)
+	s.Equal(http.StatusInternalServerError, *recorded.StatusCode)
+	s.Require().NotNil(recorded.Error)
+	s.Require().NotNil(recorded.NextAttemptAt)
+	s.WithinDuration(before.Add(20*time.Second), *recorded.NextAttemptAt, 2*time.Second)
+}
+
+func (s *DispatcherSuite) TestDispatchDue_LastAttemptFails() {
+	s.repo.EXPECT().ClaimDueDeliveries(s.ctx, 10, gomock.Any()).Return([]repository.WebhookDelivery{s.delivery("http://127.0.0.1:1", 3)}, nil)
+
+	var recorded repository.WebhookDeliveryAttempt
+	s.repo.EXPECT().
+		RecordDeliveryAttempt(s.ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, a repository.WebhookDeliveryAttempt) error {
+			recorded = a
+			return nil
+		})
+
+	_, err := s.dispatcher.dispatchDue(s.ctx)
+
+	s.Require().NoError(err)
+	s.False(recorded.Delivered)
+	s.Nil(recorded.StatusCode)
+	s.NotNil(recorded.Error)
+	s.Nil(recorded.NextAttemptAt)
+}
+
+func (s *DispatcherSuite) TestDispatchDue_PropagatesTraceparent() {
+	exporter := tracing.NewInMemoryExporter()
+	tracing.SetTracer(tracing.NewTracer(exporter, nil))
+	defer tracing.SetTracer(nil)
+
+	rc := &receiver{secret: "0123456789abcdef", status: http.StatusOK}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	s.repo.EXPECT().ClaimDueDeliveries(s.ctx, 10, gomock.Any()).Return([]repository.WebhookDelivery{s.delivery(server.URL, 1)}, nil)
+	s.repo.EXPECT().RecordDeliveryAttempt(s.ctx, gomock.Any()).Return(nil)
+
+	_, err := s.dispatcher.dispatchDue(s.ctx)
+
+	s.Require().NoError(err)
+	spans := exporter.Spans()
+	s.Require().Len(spans, 1)
+	s.Equal("webhook.Dispatcher.deliver", spans[0].Name)
+	s.Require().Len(rc.requests, 1)
+	sc, ok := tracing.ParseTraceparent(rc.requests[0].Header.Get(tracing.TraceparentHeader))
+	s.True(ok)
+	s.Equal(spans[0].SpanID, sc.SpanID)
+}
+
+func (s *DispatcherSuite) TestVerify() {
+	body := []byte(`{"type":"subscription.deleted"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	s.True(Verify("secret", 1700000000, body, signature))
+	s.False(Verify("other", 1700000000, body, signature))
+	s.False(Verify("secret", 1700000001, body, signature))
+}
@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"EffectiveMobile/internal/repository"
+	"context"
+)
+
+type Enqueuer interface {
+	EnqueueEvent(ctx context.Context, event repository.Event) error
+}
+
+// Publisher is the outbox publisher that queues events for the webhook endpoints
+// subscribed to them; Dispatcher delivers them from there.
+type Publisher struct {
+	repo Enqueuer
+}
+
+func NewPublisher(repo Enqueuer) *Publisher {
+	return &Publisher{repo: repo}
+}
+
+func (p *Publisher) Name() string {
+	return "webhook"
+}
+
+func (p *Publisher) Publish(ctx context.Context, event repository.Event) error {
+	return p.repo.EnqueueEvent(ctx, event)
+}
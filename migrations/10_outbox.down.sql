@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_webhook_outbox_endpoint_event;
+DROP TABLE IF EXISTS outbox_event;
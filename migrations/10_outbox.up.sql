@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS outbox_event (
+    id              BIGSERIAL   PRIMARY KEY,
+    event_id        UUID        NOT NULL UNIQUE,
+    event_type      TEXT        NOT NULL,
+    subscription_id BIGINT      NOT NULL,
+    payload         JSONB       NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error      TEXT        NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at    TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_event_due
+    ON outbox_event(next_attempt_at, id) WHERE status = 'pending';
+
+-- Events are published at least once, a repeated event must not be delivered twice to
+-- the same webhook. The delivery log of a duplicate is kept under the first copy
+-- before the duplicate is deleted.
+UPDATE webhook_delivery_log l
+    SET outbox_id = d.keep_id
+    FROM (
+        SELECT id, MIN(id) OVER (PARTITION BY endpoint_id, event_id) AS keep_id
+        FROM webhook_outbox
+    ) d
+    WHERE l.outbox_id = d.id AND d.id <> d.keep_id;
+
+DELETE FROM webhook_outbox a
+    USING webhook_outbox b
+    WHERE a.endpoint_id = b.endpoint_id AND a.event_id = b.event_id AND a.id > b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_outbox_endpoint_event
+    ON webhook_outbox(endpoint_id, event_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS service_alias;
+
+DROP INDEX IF EXISTS idx_service_name_lower;
+
+ALTER TABLE service
+    ADD CONSTRAINT service_name_key UNIQUE (name);
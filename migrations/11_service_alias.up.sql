@@ -0,0 +1,63 @@
+-- Service names are compared ignoring case and extra whitespace. Existing services
+-- that only differ that way are merged into the oldest one first. When a user has
+-- active subscriptions to several of them starting in the same month, only one stays
+-- active (the one of the oldest service, then the oldest subscription); the others are
+-- soft-deleted so that they do not break idx_sub_user_service_start_active.
+CREATE TEMPORARY TABLE service_duplicate AS
+SELECT id, keep_id
+FROM (
+    SELECT id, MIN(id) OVER (PARTITION BY lower(regexp_replace(btrim(name), '\s+', ' ', 'g'))) AS keep_id
+    FROM service
+) s
+WHERE id <> keep_id;
+
+UPDATE subscription s
+SET deleted_at = now(),
+    version    = s.version + 1
+FROM (
+    SELECT s.id,
+           ROW_NUMBER() OVER (
+               PARTITION BY s.user_id, COALESCE(d.keep_id, s.service_id), s.start_date
+               ORDER BY s.service_id, s.id
+           ) AS rn
+    FROM subscription s
+    LEFT JOIN service_duplicate d ON d.id = s.service_id
+    WHERE s.deleted_at IS NULL
+) c
+WHERE s.id = c.id AND c.rn > 1;
+
+UPDATE subscription s
+SET service_id = d.keep_id
+FROM service_duplicate d
+WHERE s.service_id = d.id;
+
+DELETE FROM service sv
+USING service_duplicate d
+WHERE sv.id = d.id;
+
+DROP TABLE service_duplicate;
+
+ALTER TABLE service
+    DROP CONSTRAINT IF EXISTS service_name_key;
+
+UPDATE service
+SET name = regexp_replace(btrim(name), '\s+', ' ', 'g')
+WHERE name <> regexp_replace(btrim(name), '\s+', ' ', 'g');
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_name_lower
+    ON service(lower(name));
+
+-- An alias is another name of a service. Names of services and aliases do not overlap,
+-- which the application checks on every change.
+CREATE TABLE IF NOT EXISTS service_alias (
+    id         SERIAL      PRIMARY KEY,
+    service_id INT         NOT NULL REFERENCES service(id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_alias_name_lower
+    ON service_alias(lower(name));
+
+CREATE INDEX IF NOT EXISTS idx_service_alias_service
+    ON service_alias(service_id);
@@ -0,0 +1,10 @@
+DELETE FROM subscription
+    WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_sub_user_service_start_active;
+
+ALTER TABLE subscription
+    ADD CONSTRAINT subscription_user_id_service_id_start_date_key UNIQUE (user_id, service_id, start_date);
+
+ALTER TABLE subscription
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,9 @@
+ALTER TABLE subscription
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+ALTER TABLE subscription
+    DROP CONSTRAINT IF EXISTS subscription_user_id_service_id_start_date_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sub_user_service_start_active
+    ON subscription(user_id, service_id, start_date)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS subscription_history (
+    id              BIGSERIAL   PRIMARY KEY,
+    subscription_id BIGINT      NOT NULL,
+    action          TEXT        NOT NULL CHECK (action IN ('created', 'updated', 'deleted', 'restored')),
+    before          JSONB       NULL,
+    after           JSONB       NULL,
+    request_id      TEXT        NULL,
+    changed_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sub_history_subscription
+    ON subscription_history(subscription_id, id);
+
+CREATE OR REPLACE FUNCTION subscription_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_subscription_history_append_only
+    BEFORE UPDATE OR DELETE ON subscription_history
+    FOR EACH ROW EXECUTE FUNCTION subscription_history_append_only();
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS subscription_price (
+    subscription_id BIGINT  NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    effective_from  DATE    NOT NULL,
+    price_rub       INTEGER NOT NULL CHECK (price_rub >= 0),
+    CHECK (effective_from = date_trunc('month', effective_from)::date),
+
+    PRIMARY KEY (subscription_id, effective_from)
+);
+
+INSERT INTO subscription_price (subscription_id, effective_from, price_rub)
+SELECT id, start_date, price_rub FROM subscription
+ON CONFLICT DO NOTHING;
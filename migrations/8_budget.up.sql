@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS budget (
+    user_id           UUID        PRIMARY KEY,
+    monthly_limit_rub INTEGER     NOT NULL CHECK (monthly_limit_rub >= 0),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
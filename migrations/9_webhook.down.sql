@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subscription_ending_notice;
+DROP TABLE IF EXISTS webhook_delivery_log;
+DROP TABLE IF EXISTS webhook_outbox;
+DROP TABLE IF EXISTS webhook_endpoint;
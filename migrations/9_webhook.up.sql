@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS webhook_endpoint (
+    id         BIGSERIAL   PRIMARY KEY,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT[]      NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id              BIGSERIAL   PRIMARY KEY,
+    endpoint_id     BIGINT      NOT NULL REFERENCES webhook_endpoint(id) ON DELETE CASCADE,
+    event_id        UUID        NOT NULL,
+    event_type      TEXT        NOT NULL,
+    payload         JSONB       NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error      TEXT        NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at    TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_due
+    ON webhook_outbox(next_attempt_at, id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_log (
+    id          BIGSERIAL   PRIMARY KEY,
+    outbox_id   BIGINT      NOT NULL REFERENCES webhook_outbox(id) ON DELETE CASCADE,
+    endpoint_id BIGINT      NOT NULL REFERENCES webhook_endpoint(id) ON DELETE CASCADE,
+    event_id    UUID        NOT NULL,
+    event_type  TEXT        NOT NULL,
+    attempt     INTEGER     NOT NULL,
+    status_code INTEGER     NULL,
+    error       TEXT        NULL,
+    duration_ms INTEGER     NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_log_endpoint
+    ON webhook_delivery_log(endpoint_id, id);
+
+CREATE TABLE IF NOT EXISTS subscription_ending_notice (
+    subscription_id BIGINT      NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    end_date        DATE        NOT NULL,
+    notified_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (subscription_id, end_date)
+);
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations, so that the binaries do not need the
+// directory on disk.
+package migrations
+
+import "embed"
+
+// FS holds the golang-migrate files: <version>_<name>.up.sql and .down.sql.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+)
+
+// NewDBStatsCollector exposes the connection pool statistics returned by stats,
+// usually (*sql.DB).Stats.
+func NewDBStatsCollector(stats func() sql.DBStats) Collector {
+	return CollectorFunc(func(context.Context) ([]Family, error) {
+		s := stats()
+
+		return []Family{
+			single("db_pool_max_open_connections", "Maximum number of open connections to the database.", Gauge, float64(s.MaxOpenConnections)),
+			single("db_pool_open_connections", "Number of established connections, both in use and idle.", Gauge, float64(s.OpenConnections)),
+			single("db_pool_in_use_connections", "Number of connections currently in use.", Gauge, float64(s.InUse)),
+			single("db_pool_idle_connections", "Number of idle connections.", Gauge, float64(s.Idle)),
+			single("db_pool_wait_count_total", "Total number of connections waited for.", Counter, float64(s.WaitCount)),
+			single("db_pool_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", Counter, s.WaitDuration.Seconds()),
+			single("db_pool_max_idle_closed_total", "Total number of connections closed due to the idle connection limit.", Counter, float64(s.MaxIdleClosed)),
+			single("db_pool_max_idle_time_closed_total", "Total number of connections closed due to the idle time limit.", Counter, float64(s.MaxIdleTimeClosed)),
+			single("db_pool_max_lifetime_closed_total", "Total number of connections closed due to the connection lifetime limit.", Counter, float64(s.MaxLifetimeClosed)),
+		}, nil
+	})
+}
@@ -0,0 +1,189 @@
+// Package metrics exposes metrics in the Prometheus text exposition format without
+// depending on the Prometheus client.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value of a family. Name is the full sample name, so histograms
+// can use the _bucket, _sum and _count suffixes.
+type Sample struct {
+	Name   string
+	Labels []Label
+	Value  float64
+}
+
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Collector returns its families on every scrape.
+type Collector interface {
+	Collect(ctx context.Context) ([]Family, error)
+}
+
+// CollectorFunc adapts a function to Collector.
+type CollectorFunc func(ctx context.Context) ([]Family, error)
+
+func (f CollectorFunc) Collect(ctx context.Context) ([]Family, error) {
+	return f(ctx)
+}
+
+type Logger interface {
+	Error(msg string, args ...any)
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+	logger     Logger
+}
+
+func NewRegistry(logger Logger) *Registry {
+	return &Registry{
+		logger: logger,
+	}
+}
+
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collectors = append(r.collectors, c)
+}
+
+// Gather collects every registered collector and returns the families ordered by
+// name. A failed collector is skipped, so the others are still exposed; its error is
+// returned alongside them.
+func (r *Registry) Gather(ctx context.Context) ([]Family, error) {
+	r.mu.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+
+	var families []Family
+	var errs []error
+	for _, c := range collectors {
+		collected, err := c.Collect(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		families = append(families, collected...)
+	}
+
+	slices.SortStableFunc(families, func(a, b Family) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return families, errors.Join(errs...)
+}
+
+// Handler serves the gathered families. Collector errors are logged rather than
+// failing the scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		families, err := r.Gather(req.Context())
+		if err != nil {
+			r.logger.Error("failed to collect metrics", slog.String("err", err.Error()))
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		w.WriteHeader(http.StatusOK)
+		if err := WriteText(w, families); err != nil {
+			r.logger.Error("failed to write metrics", slog.String("err", err.Error()))
+		}
+	})
+}
+
+// WriteText writes families in the text exposition format.
+func WriteText(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+
+	for _, f := range families {
+		if f.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, helpEscaper.Replace(f.Help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+
+		for _, s := range f.Samples {
+			bw.WriteString(s.Name)
+			if len(s.Labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, `%s="%s"`, l.Name, labelEscaper.Replace(l.Value))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+// NewGauge returns a family with a single unlabelled gauge.
+func NewGauge(name, help string, value float64) Family {
+	return single(name, help, Gauge, value)
+}
+
+func single(name, help string, typ Type, value float64) Family {
+	return Family{
+		Name:    name,
+		Help:    help,
+		Type:    typ,
+		Samples: []Sample{{Name: name, Value: value}},
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
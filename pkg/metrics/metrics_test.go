@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsSuite struct {
+	suite.Suite
+
+	registry *Registry
+	ctx      context.Context
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, &MetricsSuite{})
+}
+
+func (s *MetricsSuite) SetupTest() {
+	s.registry = NewRegistry(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+	s.ctx = context.Background()
+}
+
+func (s *MetricsSuite) scrape() (*http.Response, string) {
+	rec := httptest.NewRecorder()
+	s.registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Result(), rec.Body.String()
+}
+
+func (s *MetricsSuite) TestCounterVec() {
+	requests := NewCounterVec("requests_total", "Total requests.", "method", "status")
+	s.registry.Register(requests)
+
+	requests.Inc("GET", "200")
+	requests.Inc("GET", "200")
+	requests.Add(3, "POST", "201")
+
+	resp, body := s.scrape()
+
+	s.Equal(ContentType, resp.Header.Get("Content-Type"))
+	s.Equal(`# HELP requests_total Total requests.
+# TYPE requests_total counter
+requests_total{method="GET",status="200"} 2
+requests_total{method="POST",status="201"} 3
+`, body)
+}
+
+func (s *MetricsSuite) TestHistogramVec() {
+	latency := NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.1}, "route")
+	s.registry.Register(latency)
+
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.1, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(3, "/a")
+
+	_, body := s.scrape()
+
+	s.Equal(`# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 2
+latency_seconds_bucket{route="/a",le="1"} 3
+latency_seconds_bucket{route="/a",le="+Inf"} 4
+latency_seconds_sum{route="/a"} 3.65
+latency_seconds_count{route="/a"} 4
+`, body)
+}
+
+func (s *MetricsSuite) TestWrongLabelCountPanics() {
+	requests := NewCounterVec("requests_total", "Total requests.", "method")
+
+	s.Panics(func() { requests.Inc("GET", "200") })
+}
+
+func (s *MetricsSuite) TestEscaping() {
+	s.registry.Register(CollectorFunc(func(context.Context) ([]Family, error) {
+		return []Family{{
+			Name: "escaped",
+			Help: "Back\\slash and\nnewline.",
+			Type: Gauge,
+			Samples: []Sample{{
+				Name:   "escaped",
+				Labels: []Label{{Name: "value", Value: "a\"b\\c\nd"}},
+				Value:  1,
+			}},
+		}}, nil
+	}))
+
+	_, body := s.scrape()
+
+	s.Equal(`# HELP escaped Back\\slash and\nnewline.
+# TYPE escaped gauge
+escaped{value="a\"b\\c\nd"} 1
+`, body)
+}
+
+func (s *MetricsSuite) TestFailedCollectorIsSkipped() {
+	s.registry.Register(CollectorFunc(func(context.Context) ([]Family, error) {
+		return nil, errors.New("database is down")
+	}))
+	s.registry.Register(CollectorFunc(func(context.Context) ([]Family, error) {
+		return []Family{NewGauge("up", "", 1)}, nil
+	}))
+
+	families, err := s.registry.Gather(s.ctx)
+	s.Error(err)
+	s.Require().Len(families, 1)
+
+	resp, body := s.scrape()
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal("# TYPE up gauge\nup 1\n", body)
+}
+
+func (s *MetricsSuite) TestFamiliesAreOrderedByName() {
+	s.registry.Register(CollectorFunc(func(context.Context) ([]Family, error) {
+		return []Family{NewGauge("b", "", 2), NewGauge("a", "", 1)}, nil
+	}))
+
+	families, err := s.registry.Gather(s.ctx)
+
+	s.NoError(err)
+	s.Require().Len(families, 2)
+	s.Equal("a", families[0].Name)
+	s.Equal("b", families[1].Name)
+}
+
+func (s *MetricsSuite) TestDBStatsCollector() {
+	s.registry.Register(NewDBStatsCollector(func() sql.DBStats {
+		return sql.DBStats{
+			MaxOpenConnections: 10,
+			OpenConnections:    4,
+			InUse:              3,
+			Idle:               1,
+			WaitCount:          7,
+			WaitDuration:       1500 * time.Millisecond,
+		}
+	}))
+
+	_, body := s.scrape()
+
+	for _, line := range []string{
+		"db_pool_max_open_connections 10",
+		"db_pool_open_connections 4",
+		"db_pool_in_use_connections 3",
+		"db_pool_idle_connections 1",
+		"db_pool_wait_count_total 7",
+		"db_pool_wait_duration_seconds_total 1.5",
+		"# TYPE db_pool_wait_count_total counter",
+	} {
+		s.True(strings.Contains(body, line+"\n"), line)
+	}
+}
@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are latency buckets in seconds, the same as the Prometheus client's.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// vec keeps one series per combination of label values.
+type vec[T any] struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series[T]
+}
+
+type series[T any] struct {
+	values []string
+	value  T
+}
+
+func newVec[T any](name, help string, labels []string) vec[T] {
+	return vec[T]{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: make(map[string]*series[T]),
+	}
+}
+
+// with calls fn with the series of the label values under the lock, creating it with
+// init on first use.
+func (v *vec[T]) with(values []string, init func() T, fn func(*T)) {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = &series[T]{values: slices.Clone(values), value: init()}
+		v.series[key] = s
+	}
+	fn(&s.value)
+}
+
+// snapshot returns copies of the series ordered by label values.
+func (v *vec[T]) snapshot(clone func(T) T) []series[T] {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]series[T], 0, len(keys))
+	for _, key := range keys {
+		s := v.series[key]
+		result = append(result, series[T]{values: s.values, value: clone(s.value)})
+	}
+	return result
+}
+
+func (v *vec[T]) labelsOf(values []string, extra ...Label) []Label {
+	labels := make([]Label, 0, len(values)+len(extra))
+	for i, value := range values {
+		labels = append(labels, Label{Name: v.labels[i], Value: value})
+	}
+	return append(labels, extra...)
+}
+
+type CounterVec struct {
+	vec[float64]
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{vec: newVec[float64](name, help, labels)}
+}
+
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta, which must not be negative, to the series of the label values.
+func (c *CounterVec) Add(delta float64, values ...string) {
+	c.with(values, func() float64 { return 0 }, func(v *float64) { *v += delta })
+}
+
+func (c *CounterVec) Collect(context.Context) ([]Family, error) {
+	family := Family{Name: c.name, Help: c.help, Type: Counter}
+	for _, s := range c.snapshot(func(v float64) float64 { return v }) {
+		family.Samples = append(family.Samples, Sample{Name: c.name, Labels: c.labelsOf(s.values), Value: s.value})
+	}
+	return []Family{family}, nil
+}
+
+type histogram struct {
+	// counts are per bucket, not cumulative; the last one counts values above every
+	// bucket.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type HistogramVec struct {
+	vec[histogram]
+	buckets []float64
+}
+
+// NewHistogramVec returns a histogram with the given upper bounds; the +Inf bucket is
+// added on its own.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &HistogramVec{
+		vec:     newVec[histogram](name, help, labels),
+		buckets: buckets,
+	}
+}
+
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	i, _ := slices.BinarySearch(h.buckets, value)
+
+	h.with(values, func() histogram {
+		return histogram{counts: make([]uint64, len(h.buckets)+1)}
+	}, func(hist *histogram) {
+		hist.counts[i]++
+		hist.sum += value
+		hist.count++
+	})
+}
+
+func (h *HistogramVec) Collect(context.Context) ([]Family, error) {
+	family := Family{Name: h.name, Help: h.help, Type: Histogram}
+
+	snapshot := h.snapshot(func(hist histogram) histogram {
+		hist.counts = slices.Clone(hist.counts)
+		return hist
+	})
+	for _, s := range snapshot {
+		var cumulative uint64
+		for i, count := range s.value.counts {
+			cumulative += count
+			upper := math.Inf(1)
+			if i < len(h.buckets) {
+				upper = h.buckets[i]
+			}
+			family.Samples = append(family.Samples, Sample{
+				Name:   h.name + "_bucket",
+				Labels: h.labelsOf(s.values, Label{Name: "le", Value: formatFloat(upper)}),
+				Value:  float64(cumulative),
+			})
+		}
+		family.Samples = append(family.Samples,
+			Sample{Name: h.name + "_sum", Labels: h.labelsOf(s.values), Value: s.value.sum},
+			Sample{Name: h.name + "_count", Labels: h.labelsOf(s.values), Value: float64(s.value.count)},
+		)
+	}
+	return []Family{family}, nil
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// migrationsLockID is the key of the advisory lock taken by Migrate ("EfMobile" in
+// ASCII). It is the same for every replica of the service.
+const migrationsLockID int64 = 0x4566_4d6f_6269_6c65
+
+// ErrNoMigrations is returned by MigrationVersion for a database no migration was
+// applied to.
+var ErrNoMigrations = errors.New("no migrations applied")
+
+// Migrate applies the pending migrations of files, a directory of golang-migrate SQL
+// files, to the database in table. It holds a session advisory lock meanwhile, so
+// replicas starting at once wait for the first one, bounded only by ctx, and then find
+// nothing left to apply.
+func (p *Provider) Migrate(ctx context.Context, files fs.FS, table string) (err error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("can't get db conn: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockID); err != nil {
+		return fmt.Errorf("can't acquire migrations lock: %w", err)
+	}
+	defer func() {
+		if _, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationsLockID); unlockErr != nil {
+			// A session that may still hold the lock must not go back to the pool.
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+			err = errors.Join(err, fmt.Errorf("can't release migrations lock: %w", unlockErr))
+		}
+	}()
+
+	return p.migrateUp(files, table)
+}
+
+func (p *Provider) migrateUp(files fs.FS, table string) error {
+	src, err := iofs.New(files, ".")
+	if err != nil {
+		return fmt.Errorf("can't read migrations: %w", err)
+	}
+
+	// The migrate driver closes the db it is given, so it gets its own.
+	db, err := sql.Open("pgx", p.cs)
+	if err != nil {
+		return fmt.Errorf("can't open db conn: %w", err)
+	}
+
+	dbDriver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{MigrationsTable: table})
+	if err != nil {
+		_ = db.Close()
+		return fmt.Errorf("can't init migrate driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "pgx5", dbDriver)
+	if err != nil {
+		_ = dbDriver.Close()
+		return fmt.Errorf("can't init migrate: %w", err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("can't apply migrations: %w", err)
+	}
+
+	version, _, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("can't read migrations version: %w", err)
+	}
+	p.logger.Info("migrations applied", "version", version)
+
+	return nil
+}
+
+// MigrationVersion returns the version golang-migrate recorded in table and whether
+// the migration to it failed halfway.
+func (p *Provider) MigrationVersion(ctx context.Context, table string) (uint, bool, error) {
+	query := fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1", pgx.Identifier{table}.Sanitize())
+
+	var version int64
+	var dirty bool
+	if err := p.Conn(ctx).QueryRowContext(ctx, query).Scan(&version, &dirty); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, sql.ErrNoRows) || errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable {
+			return 0, false, ErrNoMigrations
+		}
+		return 0, false, fmt.Errorf("can't read migrations version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
+
+// LatestMigration returns the version of the last migration in files.
+func LatestMigration(files fs.FS) (uint, error) {
+	src, err := iofs.New(files, ".")
+	if err != nil {
+		return 0, fmt.Errorf("can't read migrations: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	latest, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("can't read migrations: %w", err)
+	}
+	for {
+		next, err := src.Next(latest)
+		if errors.Is(err, os.ErrNotExist) {
+			return latest, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("can't read migrations: %w", err)
+		}
+		latest = next
+	}
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"EffectiveMobile/migrations"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type LatestMigrationSuite struct {
+	suite.Suite
+}
+
+func TestLatestMigration(t *testing.T) {
+	suite.Run(t, &LatestMigrationSuite{})
+}
+
+func (s *LatestMigrationSuite) TestEmbeddedMigrations() {
+	latest, err := LatestMigration(migrations.FS)
+
+	s.NoError(err)
+	s.Positive(latest)
+}
+
+func (s *LatestMigrationSuite) TestVersionsAreOrderedNumerically() {
+	files := fstest.MapFS{
+		"1_init.up.sql":     {Data: []byte("SELECT 1")},
+		"1_init.down.sql":   {Data: []byte("SELECT 1")},
+		"2_budget.up.sql":   {Data: []byte("SELECT 1")},
+		"10_webhook.up.sql": {Data: []byte("SELECT 1")},
+	}
+
+	latest, err := LatestMigration(files)
+
+	s.NoError(err)
+	s.Equal(uint(10), latest)
+}
+
+func (s *LatestMigrationSuite) TestNoMigrations() {
+	_, err := LatestMigration(fstest.MapFS{})
+
+	s.ErrorContains(err, "can't read migrations")
+}
+
+// MigrateSuite applies the embedded migrations to a throwaway database.
+type MigrateSuite struct {
+	suite.Suite
+
+	admin  *sql.DB
+	dbName string
+	logger *slog.Logger
+}
+
+func TestMigrate(t *testing.T) {
+	if os.Getenv("INTEGRATION_TESTS") != "true" {
+		t.Skip()
+		return
+	}
+
+	suite.Run(t, &MigrateSuite{})
+}
+
+func (s *MigrateSuite) provider(database string) *Provider {
+	provider := New("postgres", "postgres", SQLDataBase{
+		Server:          "localhost",
+		Database:        database,
+		MaxIdleCons:     2,
+		MaxOpenCons:     4,
+		ConnMaxLifetime: 60,
+		Port:            "5433",
+	}, s.logger)
+	s.Require().NoError(provider.Open())
+	return provider
+}
+
+func (s *MigrateSuite) SetupTest() {
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	admin := s.provider("postgres")
+	s.admin = admin.GetConn()
+
+	s.dbName = "migrate_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err := s.admin.Exec("CREATE DATABASE " + s.dbName)
+	s.Require().NoError(err)
+}
+
+func (s *MigrateSuite) TearDownTest() {
+	_, err := s.admin.Exec("DROP DATABASE IF EXISTS " + s.dbName + " WITH (FORCE)")
+	s.Require().NoError(err)
+	s.Require().NoError(s.admin.Close())
+}
+
+func (s *MigrateSuite) TestConcurrentReplicas() {
+	const replicas = 4
+	providers := make([]*Provider, replicas)
+	for i := range providers {
+		providers[i] = s.provider(s.dbName)
+	}
+	defer func() {
+		for _, provider := range providers {
+			s.NoError(provider.Close())
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	errs := make([]error, replicas)
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = provider.Migrate(ctx, migrations.FS, "schema_migrations")
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		s.NoError(err)
+	}
+
+	var dirty bool
+	var version int
+	s.Require().NoError(providers[0].GetConn().QueryRow(`SELECT version, dirty FROM schema_migrations`).Scan(&version, &dirty))
+	s.False(dirty)
+	s.Positive(version)
+
+	// The lock is released, so a later start runs again without waiting.
+	s.NoError(providers[0].Migrate(ctx, migrations.FS, "schema_migrations"))
+}
+
+func (s *MigrateSuite) TestLockWaitIsBoundedByContext() {
+	provider := s.provider(s.dbName)
+	defer func() { s.NoError(provider.Close()) }()
+
+	holder, err := provider.GetConn().Conn(context.Background())
+	s.Require().NoError(err)
+	defer holder.Close()
+	_, err = holder.ExecContext(context.Background(), `SELECT pg_advisory_lock($1)`, migrationsLockID)
+	s.Require().NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	err = provider.Migrate(ctx, migrations.FS, "schema_migrations")
+
+	s.ErrorContains(err, "can't acquire migrations lock")
+}
+
+func (s *MigrateSuite) TestMigrationVersion() {
+	provider := s.provider(s.dbName)
+	defer func() { s.NoError(provider.Close()) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, _, err := provider.MigrationVersion(ctx, "schema_migrations")
+	s.ErrorIs(err, ErrNoMigrations)
+
+	s.Require().NoError(provider.Migrate(ctx, migrations.FS, "schema_migrations"))
+	latest, err := LatestMigration(migrations.FS)
+	s.Require().NoError(err)
+
+	version, dirty, err := provider.MigrationVersion(ctx, "schema_migrations")
+	s.NoError(err)
+	s.False(dirty)
+	s.Equal(latest, version)
+}
@@ -1,11 +1,13 @@
 ﻿package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib" //nolint: revive,nolintlint
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
 type Logger interface {
@@ -43,12 +45,13 @@ func New(user, pass string, sqlDataBase SQLDataBase, logger Logger) *Provider {
 }
 
 func (p *Provider) Open() error {
-	var err error
-
-	p.db, err = sql.Open("pgx", p.cs)
+	config, err := pgx.ParseConfig(p.cs)
 	if err != nil {
 		return fmt.Errorf("can't open db conn: %w", err)
 	}
+	config.Tracer = queryTracer{}
+
+	p.db = stdlib.OpenDB(*config)
 
 	p.db.SetMaxIdleConns(p.idlConns)
 
@@ -66,6 +69,11 @@ func (p *Provider) Open() error {
 	return nil
 }
 
+// Ping checks that the database is reachable, bounded by ctx.
+func (p *Provider) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
 func (p *Provider) GetConn() *sql.DB {
 	return p.db
 }
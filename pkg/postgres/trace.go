@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"EffectiveMobile/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type querySpanKey struct{}
+
+// queryTracer records a span with the statement and the number of rows for every query
+// run on behalf of a traced operation. Queries without a parent span, like the polls
+// of the background workers, are not traced.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if tracing.SpanFromContext(ctx) == nil {
+		return ctx
+	}
+
+	ctx, span := tracing.Start(ctx, queryName(data.SQL),
+		slog.String("db.system", "postgresql"),
+		slog.String("db.statement", data.SQL),
+	)
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, ok := ctx.Value(querySpanKey{}).(*tracing.Span)
+	if !ok {
+		return
+	}
+
+	// The tag counts the returned rows for SELECT and the affected ones otherwise.
+	span.SetAttributes(slog.Int64("db.rows", data.CommandTag.RowsAffected()))
+	span.RecordError(data.Err)
+	span.End()
+}
+
+// queryName names the span after the SQL command, e.g. SELECT.
+func queryName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
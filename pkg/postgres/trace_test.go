@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"EffectiveMobile/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueryTracerSuite struct {
+	suite.Suite
+
+	exporter *tracing.InMemoryExporter
+	ctx      context.Context
+}
+
+func TestQueryTracer(t *testing.T) {
+	suite.Run(t, &QueryTracerSuite{})
+}
+
+func (s *QueryTracerSuite) SetupTest() {
+	s.exporter = tracing.NewInMemoryExporter()
+	tracing.SetTracer(tracing.NewTracer(s.exporter, nil))
+	s.ctx = context.Background()
+}
+
+func (s *QueryTracerSuite) TearDownTest() {
+	tracing.SetTracer(nil)
+}
+
+func (s *QueryTracerSuite) TestQueryWithParentSpan() {
+	ctx, parent := tracing.Start(s.ctx, "service.stats.GetTotalCost")
+	query := "SELECT id FROM subscription WHERE user_id = $1"
+
+	queryCtx := queryTracer{}.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: query})
+	queryTracer{}.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 3")})
+	parent.End()
+
+	spans := s.exporter.Spans()
+	s.Require().Len(spans, 2)
+	s.Equal("SELECT", spans[0].Name)
+	s.Equal(parent.SpanContext().SpanID, spans[0].ParentSpanID)
+	s.Equal(query, spans[0].Attributes["db.statement"])
+	s.Equal(int64(3), spans[0].Attributes["db.rows"])
+	s.Empty(spans[0].Error)
+}
+
+func (s *QueryTracerSuite) TestFailedQuery() {
+	ctx, parent := tracing.Start(s.ctx, "parent")
+	defer parent.End()
+
+	queryCtx := queryTracer{}.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "\n\tupdate subscription SET price_rub = 1"})
+	queryTracer{}.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: errors.New("deadlock detected")})
+
+	spans := s.exporter.Spans()
+	s.Require().Len(spans, 1)
+	s.Equal("UPDATE", spans[0].Name)
+	s.Equal("deadlock detected", spans[0].Error)
+}
+
+func (s *QueryTracerSuite) TestQueryWithoutParentSpanIsNotTraced() {
+	queryCtx := queryTracer{}.TraceQueryStart(s.ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	queryTracer{}.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	s.Equal(s.ctx, queryCtx)
+	s.Empty(s.exporter.Spans())
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+// TraceparentHeader is the W3C Trace Context header carrying the parent span.
+const TraceparentHeader = "traceparent"
+
+const (
+	traceparentVersion = "00"
+	traceparentLen     = 55
+	flagSampled        = 0x01
+)
+
+// Traceparent formats sc as a version 00 traceparent value.
+func (sc SpanContext) Traceparent() string {
+	var flags byte
+	if sc.Sampled {
+		flags |= flagSampled
+	}
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses a traceparent value. Values of later versions are accepted
+// as long as they start with the fields of version 00, as the specification asks.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	if len(value) < traceparentLen || value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return SpanContext{}, false
+	}
+
+	version, ok := decodeHex(value[0:2])
+	if !ok || version[0] == 0xff {
+		return SpanContext{}, false
+	}
+	if value[0:2] == traceparentVersion && len(value) != traceparentLen {
+		return SpanContext{}, false
+	}
+	if len(value) > traceparentLen && value[traceparentLen] != '-' {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	traceID, ok := decodeHex(value[3:35])
+	if !ok {
+		return SpanContext{}, false
+	}
+	copy(sc.TraceID[:], traceID)
+
+	spanID, ok := decodeHex(value[36:52])
+	if !ok {
+		return SpanContext{}, false
+	}
+	copy(sc.SpanID[:], spanID)
+
+	flags, ok := decodeHex(value[53:55])
+	if !ok {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&flagSampled != 0
+
+	return sc, sc.IsValid()
+}
+
+// decodeHex decodes lowercase hex only, the form traceparent requires.
+func decodeHex(s string) ([]byte, bool) {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return nil, false
+		}
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+// Extract returns ctx with the remote parent from the traceparent header of h. An
+// absent or invalid header leaves ctx as is, so a new trace is started.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	sc, ok := ParseTraceparent(h.Get(TraceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Inject sets the traceparent header of h to the span in ctx, if any.
+func Inject(ctx context.Context, h http.Header) {
+	sc, ok := parentSpanContext(ctx)
+	if !ok {
+		return
+	}
+	h.Set(TraceparentHeader, sc.Traceparent())
+}
@@ -0,0 +1,253 @@
+// Package tracing records spans in the style of OpenTelemetry without depending on its
+// SDK: spans form traces through the context, are propagated with the W3C traceparent
+// header and are handed to a pluggable Exporter when they end.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id TraceID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// SpanData is an ended span as exporters receive it.
+type SpanData struct {
+	TraceID      TraceID        `json:"trace_id"`
+	SpanID       SpanID         `json:"span_id"`
+	ParentSpanID SpanID         `json:"parent_span_id,omitzero"`
+	Name         string         `json:"name"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Duration     time.Duration  `json:"duration_ns"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// Span is an operation in progress. Only sampled spans of a tracer with an exporter
+// record anything, the others just carry their SpanContext. All methods are safe on a
+// nil Span.
+type Span struct {
+	mu        sync.Mutex
+	tracer    *Tracer
+	sc        SpanContext
+	data      SpanData
+	recording bool
+	ended     bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetName replaces the name the span was started with, for names only known at the
+// end, like the route of a request.
+func (s *Span) SetName(name string) {
+	s.update(func() { s.data.Name = name })
+}
+
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	s.update(func() { setAttributes(&s.data, attrs) })
+}
+
+// RecordError marks the span as failed. The last recorded error is kept.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.SetError(err.Error())
+}
+
+func (s *Span) SetError(message string) {
+	s.update(func() { s.data.Error = message })
+}
+
+// End finishes the span and exports it. Calls after the first one do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended || !s.recording {
+		s.ended = true
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	s.data.Duration = s.data.End.Sub(s.data.Start)
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.export(data)
+}
+
+func (s *Span) update(fn func()) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recording && !s.ended {
+		fn()
+	}
+}
+
+func setAttributes(data *SpanData, attrs []slog.Attr) {
+	if len(attrs) == 0 {
+		return
+	}
+	if data.Attributes == nil {
+		data.Attributes = make(map[string]any, len(attrs))
+	}
+	for _, attr := range attrs {
+		data.Attributes[attr.Key] = attr.Value.Resolve().Any()
+	}
+}
+
+// Exporter receives every ended span that was sampled.
+type Exporter interface {
+	ExportSpan(span SpanData) error
+}
+
+type Logger interface {
+	Error(msg string, args ...any)
+}
+
+type Tracer struct {
+	exporter Exporter
+	logger   Logger
+}
+
+// NewTracer returns a tracer exporting spans to exporter. A nil exporter still
+// propagates trace ids but records nothing.
+func NewTracer(exporter Exporter, logger Logger) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		logger:   logger,
+	}
+}
+
+// Start starts a span that is a child of the span in ctx or, failing that, of the
+// remote span extracted into ctx. Without either it starts a new trace.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *Span) {
+	parent, ok := parentSpanContext(ctx)
+
+	sc := SpanContext{Sampled: true}
+	var parentID SpanID
+	if ok {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+		parentID = parent.SpanID
+	} else {
+		_, _ = rand.Read(sc.TraceID[:])
+	}
+	_, _ = rand.Read(sc.SpanID[:])
+
+	span := &Span{
+		tracer:    t,
+		sc:        sc,
+		recording: t.exporter != nil && sc.Sampled,
+	}
+	if span.recording {
+		span.data = SpanData{
+			TraceID:      sc.TraceID,
+			SpanID:       sc.SpanID,
+			ParentSpanID: parentID,
+			Name:         name,
+			Start:        time.Now(),
+		}
+		setAttributes(&span.data, attrs)
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *Tracer) export(data SpanData) {
+	if err := t.exporter.ExportSpan(data); err != nil && t.logger != nil {
+		t.logger.Error("failed to export span", slog.String("span", data.Name), slog.String("err", err.Error()))
+	}
+}
+
+var globalTracer atomic.Pointer[Tracer]
+
+// SetTracer sets the tracer used by Start.
+func SetTracer(t *Tracer) {
+	globalTracer.Store(t)
+}
+
+// Start starts a span with the tracer set by SetTracer. Until one is set, tracing is
+// off: ctx is returned as is with a nil Span.
+func Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *Span) {
+	t := globalTracer.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	return t.Start(ctx, name, attrs...)
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// SpanFromContext returns the span started with ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext makes sc, received from another process, the parent of
+// the spans started with the returned context.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func parentSpanContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.sc, true
+	}
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+	if !ok || !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TracingSuite struct {
+	suite.Suite
+
+	exporter *InMemoryExporter
+	tracer   *Tracer
+	ctx      context.Context
+}
+
+func TestTracing(t *testing.T) {
+	suite.Run(t, &TracingSuite{})
+}
+
+func (s *TracingSuite) SetupTest() {
+	s.exporter = NewInMemoryExporter()
+	s.tracer = NewTracer(s.exporter, slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+	s.ctx = context.Background()
+}
+
+func (s *TracingSuite) TearDownTest() {
+	SetTracer(nil)
+}
+
+func (s *TracingSuite) TestChildSpansShareTheTrace() {
+	ctx, parent := s.tracer.Start(s.ctx, "parent", slog.String("user", "u1"))
+	_, child := s.tracer.Start(ctx, "child")
+	child.SetAttributes(slog.Int("rows", 3))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	parent.End()
+
+	spans := s.exporter.Spans()
+	s.Require().Len(spans, 2)
+	s.Equal("child", spans[0].Name)
+	s.Equal("parent", spans[1].Name)
+	s.Equal(spans[1].TraceID, spans[0].TraceID)
+	s.Equal(spans[1].SpanID, spans[0].ParentSpanID)
+	s.Equal(SpanID{}, spans[1].ParentSpanID)
+	s.Equal(map[string]any{"rows": int64(3)}, spans[0].Attributes)
+	s.Equal(map[string]any{"user": "u1"}, spans[1].Attributes)
+	s.Equal("boom", spans[0].Error)
+	s.Equal(spans[0].End.Sub(spans[0].Start), spans[0].Duration)
+}
+
+func (s *TracingSuite) TestEndTwiceExportsOnce() {
+	_, span := s.tracer.Start(s.ctx, "span")
+	span.End()
+	span.SetName("renamed")
+	span.End()
+
+	spans := s.exporter.Spans()
+	s.Require().Len(spans, 1)
+	s.Equal("span", spans[0].Name)
+}
+
+func (s *TracingSuite) TestRemoteParent() {
+	header := http.Header{}
+	header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	_, span := s.tracer.Start(Extract(s.ctx, header), "server")
+	span.End()
+
+	spans := s.exporter.Spans()
+	s.Require().Len(spans, 1)
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", spans[0].TraceID.String())
+	s.Equal("00f067aa0ba902b7", spans[0].ParentSpanID.String())
+}
+
+func (s *TracingSuite) TestUnsampledRemoteParentIsNotExported() {
+	header := http.Header{}
+	header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+	ctx, span := s.tracer.Start(Extract(s.ctx, header), "server")
+	span.End()
+
+	s.Empty(s.exporter.Spans())
+
+	out := http.Header{}
+	Inject(ctx, out)
+	s.Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanContext().SpanID.String()+"-00", out.Get(TraceparentHeader))
+}
+
+func (s *TracingSuite) TestInject() {
+	ctx, span := s.tracer.Start(s.ctx, "client")
+
+	header := http.Header{}
+	Inject(ctx, header)
+
+	sc, ok := ParseTraceparent(header.Get(TraceparentHeader))
+	s.True(ok)
+	s.Equal(span.SpanContext(), sc)
+
+	empty := http.Header{}
+	Inject(s.ctx, empty)
+	s.Empty(empty.Get(TraceparentHeader))
+}
+
+func (s *TracingSuite) TestParseTraceparent() {
+	cases := []struct {
+		value string
+		valid bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		_, ok := ParseTraceparent(c.value)
+		s.Equal(c.valid, ok, c.value)
+	}
+}
+
+func (s *TracingSuite) TestStartWithoutTracerIsOff() {
+	ctx, span := Start(s.ctx, "span")
+
+	s.Nil(span)
+	s.Equal(s.ctx, ctx)
+	span.SetAttributes(slog.String("key", "value"))
+	span.RecordError(errors.New("boom"))
+	span.End()
+}
+
+func (s *TracingSuite) TestStartUsesTheGlobalTracer() {
+	SetTracer(s.tracer)
+
+	ctx, span := Start(s.ctx, "span")
+	span.End()
+
+	s.Same(span, SpanFromContext(ctx))
+	s.Len(s.exporter.Spans(), 1)
+}
+
+func (s *TracingSuite) TestWriterExporter() {
+	var buf bytes.Buffer
+	tracer := NewTracer(NewWriterExporter(&buf), nil)
+
+	_, span := tracer.Start(s.ctx, "span", slog.String("db.statement", "SELECT 1"))
+	span.End()
+
+	var line map[string]any
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &line))
+	s.Equal("span", line["name"])
+	s.Equal(span.SpanContext().TraceID.String(), line["trace_id"])
+	s.Equal(span.SpanContext().SpanID.String(), line["span_id"])
+	s.NotContains(line, "parent_span_id")
+	s.Equal(map[string]any{"db.statement": "SELECT 1"}, line["attributes"])
+}
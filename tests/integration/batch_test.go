@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type batchResponse struct {
+	Created int `json:"created"`
+	Failed  int `json:"failed"`
+	Results []struct {
+		Index  int    `json:"index"`
+		Status string `json:"status"`
+		ID     int64  `json:"id"`
+	} `json:"results"`
+}
+
+func (s *SubscriptionSuite) postBatch(mode string, body string) batchResponse {
+	respBody, resp, err := postAPIResponse(mainHost, "/api/v1/subscriptions/batch?mode="+mode, []byte(body), nil, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode, string(respBody))
+
+	var result batchResponse
+	s.Require().NoError(jsoniter.Unmarshal(respBody, &result))
+	return result
+}
+
+func (s *SubscriptionSuite) TestCreateSubscriptionsBatch() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	s.createSubscription("Netflix", 500, userID, "01-2024", "")
+
+	body := fmt.Sprintf(`[
+		{"service_name": "Spotify", "price": 200, "user_id": "%[1]s", "start_date": "01-2024"},
+		{"service_name": "Netflix", "price": 500, "user_id": "%[1]s", "start_date": "01-2024"},
+		{"service_name": "Yandex Plus", "price": 300, "user_id": "%[1]s", "start_date": "03-2024"}
+	]`, userID)
+
+	result := s.postBatch("transaction", body)
+	s.Equal(0, result.Created)
+	s.Require().Len(result.Results, 3)
+	s.Equal("not_created", result.Results[0].Status)
+	s.Equal("duplicate", result.Results[1].Status)
+	s.Equal("not_created", result.Results[2].Status)
+
+	var count int
+	s.Require().NoError(s.DB.QueryRow("SELECT count(*) FROM subscription").Scan(&count))
+	s.Equal(1, count)
+	s.Require().NoError(s.DB.QueryRow("SELECT count(*) FROM service").Scan(&count))
+	s.Equal(1, count, "services of a rolled back batch must not be kept")
+
+	result = s.postBatch("per_item", body)
+	s.Equal(2, result.Created)
+	s.Equal(1, result.Failed)
+	s.Equal("created", result.Results[0].Status)
+	s.Equal("duplicate", result.Results[1].Status)
+	s.Equal("created", result.Results[2].Status)
+	s.NotZero(result.Results[2].ID)
+
+	s.Require().NoError(s.DB.QueryRow("SELECT count(*) FROM subscription").Scan(&count))
+	s.Equal(3, count)
+	s.Require().NoError(s.DB.QueryRow("SELECT count(*) FROM service").Scan(&count))
+	s.Equal(3, count)
+}
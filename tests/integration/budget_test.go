@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestBudgetStatus() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	path := fmt.Sprintf("/api/v1/users/%s/budget", userID)
+
+	_, resp, err := getAPIResponse(mainHost, path+"-status", nil, nil)
+	s.NoError(err)
+	s.Equal(404, resp.StatusCode)
+
+	_, resp, err = postAPIResponse(mainHost, path, []byte(`{"monthly_limit":700}`), nil, nil)
+	s.NoError(err)
+	s.Equal(201, resp.StatusCode)
+
+	_, resp, err = postAPIResponse(mainHost, path, []byte(`{"monthly_limit":700}`), nil, nil)
+	s.NoError(err)
+	s.Equal(409, resp.StatusCode)
+
+	_ = s.createSubscription("Netflix", 500, userID, "01-2024", "")
+	_ = s.createSubscription("Spotify", 300, userID, "02-2024", "03-2024")
+
+	respBody, resp, err := getAPIResponse(mainHost, path+"-status?start_date=01-2024&end_date=04-2024", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var status struct {
+		MonthlyLimit     int  `json:"monthly_limit"`
+		TotalCost        int  `json:"total_cost"`
+		MonthsCount      int  `json:"months_count"`
+		OverBudget       bool `json:"over_budget"`
+		OverBudgetMonths []struct {
+			Month     string `json:"month"`
+			TotalCost int    `json:"total_cost"`
+			Overspend int    `json:"overspend"`
+		} `json:"over_budget_months"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &status))
+
+	s.Equal(700, status.MonthlyLimit)
+	s.Equal(4*500+2*300, status.TotalCost)
+	s.Equal(4, status.MonthsCount)
+	s.True(status.OverBudget)
+	s.Require().Len(status.OverBudgetMonths, 2)
+	s.Equal("02-2024", status.OverBudgetMonths[0].Month)
+	s.Equal(100, status.OverBudgetMonths[0].Overspend)
+
+	_, resp, err = doRequest(http.MethodPut, mainHost, path, []byte(`{"monthly_limit":800}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	respBody, resp, err = getAPIResponse(mainHost, path+"-status?start_date=01-2024&end_date=04-2024", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+	s.NoError(jsoniter.Unmarshal(respBody, &status))
+	s.False(status.OverBudget)
+	s.Empty(status.OverBudgetMonths)
+
+	resp, err = deleteAPIResponse(mainHost, path, nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	_, resp, err = getAPIResponse(mainHost, path, nil, nil)
+	s.NoError(err)
+	s.Equal(404, resp.StatusCode)
+}
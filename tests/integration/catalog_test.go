@@ -0,0 +1,234 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestRenameService() {
+	s.clearDatabase()
+
+	_ = s.createSubscription("Netlfix", 500, uuid.New(), "01-2024", "")
+
+	var serviceID int
+	err := s.DB.QueryRow(`SELECT id FROM service WHERE name = $1`, "Netlfix").Scan(&serviceID)
+	s.Require().NoError(err)
+
+	_, resp, err := doRequest(http.MethodPut, mainHost, fmt.Sprintf("/api/v1/services/%d", serviceID), []byte(`{"name":"Netflix"}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	respBody, resp, err := getAPIResponse(mainHost, "/api/v1/services", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var response struct {
+		Services []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"services"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &response))
+	s.Require().Len(response.Services, 1)
+	s.Equal("Netflix", response.Services[0].Name)
+}
+
+func (s *SubscriptionSuite) TestDeleteServiceInUse() {
+	s.clearDatabase()
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "")
+
+	var serviceID int
+	err := s.DB.QueryRow(`SELECT service_id FROM subscription WHERE id = $1`, subscriptionID).Scan(&serviceID)
+	s.Require().NoError(err)
+
+	resp, err := deleteAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d", serviceID), nil)
+	s.NoError(err)
+	s.Equal(409, resp.StatusCode)
+
+	resp, err = deleteAPIResponse(mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID), nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	// A soft-deleted subscription still keeps its service.
+	resp, err = deleteAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d", serviceID), nil)
+	s.NoError(err)
+	s.Equal(409, resp.StatusCode)
+
+	_, err = s.DB.Exec(`DELETE FROM subscription WHERE id = $1`, subscriptionID)
+	s.Require().NoError(err)
+
+	resp, err = deleteAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d", serviceID), nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+}
+
+func (s *SubscriptionSuite) TestServiceNamesIgnoreCaseAndWhitespace() {
+	s.clearDatabase()
+
+	s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "")
+	s.createSubscription(" netflix ", 500, uuid.New(), "01-2024", "")
+	s.createSubscription("NETFLIX", 500, uuid.New(), "01-2024", "")
+
+	var names []string
+	rows, err := s.DB.Query(`SELECT name FROM service`)
+	s.Require().NoError(err)
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		s.Require().NoError(rows.Scan(&name))
+		names = append(names, name)
+	}
+	s.Require().NoError(rows.Err())
+	s.Equal([]string{"Netflix"}, names)
+
+	_, resp, err := postAPIResponse(mainHost, "/api/v1/services", []byte(`{"name":"  NetFlix"}`), nil, nil)
+	s.NoError(err)
+	s.Equal(409, resp.StatusCode)
+}
+
+func (s *SubscriptionSuite) TestServiceAliasesAndMerge() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	s.createSubscription("YouTube Premium", 300, userID, "01-2024", "")
+	s.createSubscription("YT Premium", 200, userID, "02-2024", "")
+
+	var targetID, sourceID int
+	s.Require().NoError(s.DB.QueryRow(`SELECT id FROM service WHERE name = 'YouTube Premium'`).Scan(&targetID))
+	s.Require().NoError(s.DB.QueryRow(`SELECT id FROM service WHERE name = 'YT Premium'`).Scan(&sourceID))
+
+	respBody, resp, err := postAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d/merge", sourceID), []byte(fmt.Sprintf(`{"target_id":%d}`, targetID)), nil, nil)
+	s.NoError(err)
+	s.Require().Equal(200, resp.StatusCode, string(respBody))
+
+	var merged struct {
+		Moved int64 `json:"moved"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &merged))
+	s.Equal(int64(1), merged.Moved)
+
+	// The merged name is kept as an alias and resolves to the target in filters,
+	// stats and new subscriptions.
+	names, _ := s.listServiceNames(fmt.Sprintf("/api/v1/subscriptions?user_id=%s&service_name=yt%%20premium", userID))
+	s.Equal([]string{"YouTube Premium", "YouTube Premium"}, names)
+
+	respBody, resp, err = getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/total?user_id=%s&service_name=YT%%20Premium&start_date=01-2024&end_date=03-2024", userID), nil, nil)
+	s.NoError(err)
+	s.Require().Equal(200, resp.StatusCode, string(respBody))
+
+	var stats struct {
+		TotalCost int `json:"total_cost"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+	s.Equal(1300, stats.TotalCost)
+
+	s.createSubscription("yt premium", 300, uuid.New(), "01-2024", "")
+	var services int
+	s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM service`).Scan(&services))
+	s.Equal(1, services)
+
+	_, resp, err = postAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d/aliases", targetID), []byte(`{"name":"YouTube Music"}`), nil, nil)
+	s.NoError(err)
+	s.Equal(201, resp.StatusCode)
+
+	respBody, resp, err = getAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d/aliases", targetID), nil, nil)
+	s.NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+
+	var aliases struct {
+		Aliases []string `json:"aliases"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &aliases))
+	s.Equal([]string{"YT Premium", "YouTube Music"}, aliases.Aliases)
+}
+
+func (s *SubscriptionSuite) TestMergeServicesRecordsChanges() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	s.createSubscription("Netflix", 500, userID, "01-2024", "")
+	subscriptionID := s.createSubscription("Netflix Basic", 300, userID, "02-2024", "")
+
+	var targetID, sourceID int
+	s.Require().NoError(s.DB.QueryRow(`SELECT id FROM service WHERE name = 'Netflix'`).Scan(&targetID))
+	s.Require().NoError(s.DB.QueryRow(`SELECT id FROM service WHERE name = 'Netflix Basic'`).Scan(&sourceID))
+
+	_, resp, err := postAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d/merge", sourceID), []byte(fmt.Sprintf(`{"target_id":%d}`, targetID)), map[string]string{"X-Request-Id": "merge-netflix"}, nil)
+	s.NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+	respBody, resp, err := getAPIResponse(mainHost, path, nil, nil)
+	s.NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+	s.Equal(`"2"`, resp.Header.Get("ETag"))
+
+	var subscription struct {
+		ServiceName string `json:"service_name"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &subscription))
+	s.Equal("Netflix", subscription.ServiceName)
+
+	respBody, _, err = getAPIResponse(mainHost, path+"/history", nil, nil)
+	s.NoError(err)
+
+	var history struct {
+		History []struct {
+			Action    string `json:"action"`
+			RequestID string `json:"request_id"`
+			Before    *struct {
+				ServiceName string `json:"service_name"`
+			} `json:"before"`
+			After *struct {
+				ServiceName string `json:"service_name"`
+				Version     int    `json:"version"`
+			} `json:"after"`
+		} `json:"history"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &history))
+	s.Require().Len(history.History, 2)
+	s.Equal("updated", history.History[1].Action)
+	s.Equal("merge-netflix", history.History[1].RequestID)
+	s.Equal("Netflix Basic", history.History[1].Before.ServiceName)
+	s.Equal("Netflix", history.History[1].After.ServiceName)
+	s.Equal(2, history.History[1].After.Version)
+
+	var eventType string
+	var payload []byte
+	s.Require().NoError(s.DB.QueryRow(`SELECT event_type, payload FROM outbox_event WHERE subscription_id = $1 ORDER BY id DESC LIMIT 1`, subscriptionID).Scan(&eventType, &payload))
+	s.Equal("subscription.updated", eventType)
+
+	var event struct {
+		ServiceName string `json:"service_name"`
+		Previous    struct {
+			ServiceName string `json:"service_name"`
+		} `json:"previous"`
+	}
+	s.NoError(jsoniter.Unmarshal(payload, &event))
+	s.Equal("Netflix", event.ServiceName)
+	s.Equal("Netflix Basic", event.Previous.ServiceName)
+}
+
+func (s *SubscriptionSuite) TestMergeServicesConflict() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	s.createSubscription("Netflix", 500, userID, "01-2024", "")
+	s.createSubscription("Netflix Basic", 300, userID, "01-2024", "")
+
+	var targetID, sourceID int
+	s.Require().NoError(s.DB.QueryRow(`SELECT id FROM service WHERE name = 'Netflix'`).Scan(&targetID))
+	s.Require().NoError(s.DB.QueryRow(`SELECT id FROM service WHERE name = 'Netflix Basic'`).Scan(&sourceID))
+
+	_, resp, err := postAPIResponse(mainHost, fmt.Sprintf("/api/v1/services/%d/merge", sourceID), []byte(fmt.Sprintf(`{"target_id":%d}`, targetID)), nil, nil)
+	s.NoError(err)
+	s.Equal(409, resp.StatusCode)
+
+	var services int
+	s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM service`).Scan(&services))
+	s.Equal(2, services)
+}
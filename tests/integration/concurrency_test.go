@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+func (s *SubscriptionSuite) TestUpdateSubscriptionStaleETag() {
+	s.clearDatabase()
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+
+	_, resp, err := getAPIResponse(mainHost, path, nil, nil)
+	s.Require().NoError(err)
+	s.Equal(200, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	s.Require().NotEmpty(etag)
+
+	_, resp, err = doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":600}`), map[string]string{"If-Match": etag}, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+	newETag := resp.Header.Get("ETag")
+	s.Equal(`"2"`, newETag)
+
+	_, resp, err = doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":700}`), map[string]string{"If-Match": etag}, nil)
+	s.NoError(err)
+	s.Equal(412, resp.StatusCode)
+
+	_, resp, err = doRequest(http.MethodDelete, mainHost, path, nil, map[string]string{"If-Match": etag}, nil)
+	s.NoError(err)
+	s.Equal(412, resp.StatusCode)
+
+	var price int
+	s.Require().NoError(s.DB.QueryRow(`SELECT price_rub FROM subscription WHERE id = $1`, subscriptionID).Scan(&price))
+	s.Equal(600, price)
+
+	// The ETag of the update is enough for the next conditional write.
+	_, resp, err = doRequest(http.MethodDelete, mainHost, path, nil, map[string]string{"If-Match": newETag}, nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	_, resp, err = doRequest(http.MethodDelete, mainHost, path, nil, map[string]string{"If-Match": newETag}, nil)
+	s.NoError(err)
+	s.Equal(404, resp.StatusCode)
+}
+
+func (s *SubscriptionSuite) TestConcurrentCreatesWithNewService() {
+	s.clearDatabase()
+
+	const requests = 20
+	statuses := make([]int, requests)
+	errs := make([]error, requests)
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			body := fmt.Sprintf(`{"service_name":"Wink","price":%d,"user_id":"%s","start_date":"01-2024"}`, 100+i, uuid.New())
+			_, resp, err := postAPIResponse(mainHost, "/api/v1/subscriptions", []byte(body), nil, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			statuses[i] = resp.StatusCode
+		}()
+	}
+	wg.Wait()
+
+	for i := range requests {
+		s.NoError(errs[i])
+		s.Equal(201, statuses[i])
+	}
+
+	var services int
+	s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM service WHERE name = 'Wink'`).Scan(&services))
+	s.Equal(1, services)
+
+	var subscriptions int
+	s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM subscription`).Scan(&subscriptions))
+	s.Equal(requests, subscriptions)
+}
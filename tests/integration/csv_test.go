@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestExportImportCSV() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	s.createSubscription("Netflix", 500, userID, "01-2024", "12-2024")
+	s.createSubscription("Spotify", 200, userID, "03-2024", "")
+	s.createSubscription("Netflix", 700, uuid.New(), "01-2024", "")
+
+	respBody, resp, err := getAPIResponse(mainHost, "/api/v1/subscriptions/export?format=csv&user_id="+userID.String(), nil, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode)
+	s.Contains(resp.Header.Get("Content-Type"), "text/csv")
+
+	lines := strings.Split(strings.TrimSpace(string(respBody)), "\n")
+	s.Require().Len(lines, 3)
+	s.Equal("id,service_name,price,user_id,start_date,end_date,deleted_at", lines[0])
+	s.Contains(lines[1], ",Netflix,500,"+userID.String()+",01-2024,12-2024,")
+
+	// Exported rows are duplicates, so importing them back only creates the new line.
+	body := string(respBody) + ",Kinopoisk,300," + userID.String() + ",02-2024,,\n" +
+		",Kinopoisk,300," + userID.String() + ",02-2024,01-2024,\n"
+
+	respBody, resp, err = postAPIResponse(mainHost, "/api/v1/subscriptions/import?mode=per_item", []byte(body), nil, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode, string(respBody))
+
+	var result struct {
+		Created int `json:"created"`
+		Results []struct {
+			Line   int    `json:"line"`
+			Status string `json:"status"`
+		} `json:"results"`
+	}
+	s.Require().NoError(jsoniter.Unmarshal(respBody, &result))
+	s.Equal(1, result.Created)
+	s.Require().Len(result.Results, 4)
+	s.Equal("duplicate", result.Results[0].Status)
+	s.Equal("duplicate", result.Results[1].Status)
+	s.Equal(4, result.Results[2].Line)
+	s.Equal("created", result.Results[2].Status)
+	s.Equal(5, result.Results[3].Line)
+	s.Equal("invalid", result.Results[3].Status)
+}
@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) listServiceNames(path string) ([]string, string) {
+	respBody, resp, err := getAPIResponse(mainHost, path, nil, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode, string(respBody))
+
+	var page struct {
+		Subscriptions []struct {
+			ServiceName string `json:"service_name"`
+		} `json:"subscriptions"`
+		NextCursor string `json:"next_cursor"`
+	}
+	s.Require().NoError(jsoniter.Unmarshal(respBody, &page))
+
+	names := make([]string, 0, len(page.Subscriptions))
+	for _, sub := range page.Subscriptions {
+		names = append(names, sub.ServiceName)
+	}
+	return names, page.NextCursor
+}
+
+func (s *SubscriptionSuite) TestListSubscriptionsFilters() {
+	s.clearDatabase()
+
+	userID, otherUserID := uuid.New(), uuid.New()
+	s.createSubscription("Netflix", 500, userID, "01-2024", "06-2024")
+	s.createSubscription("Spotify", 200, userID, "03-2024", "")
+	s.createSubscription("Kinopoisk", 300, userID, "05-2024", "12-2024")
+	s.createSubscription("Ivi", 300, otherUserID, "02-2024", "")
+	s.createSubscription("Okko", 100, uuid.New(), "01-2024", "")
+
+	users := fmt.Sprintf("user_id=%s&user_id=%s", userID, otherUserID)
+
+	for query, expected := range map[string][]string{
+		users + "&sort=price":                                    {"Spotify", "Ivi", "Kinopoisk", "Netflix"},
+		users + "&sort=-price":                                   {"Netflix", "Ivi", "Kinopoisk", "Spotify"},
+		users + "&sort=end_date":                                 {"Netflix", "Kinopoisk", "Spotify", "Ivi"},
+		users + "&sort=service_name":                             {"Ivi", "Kinopoisk", "Netflix", "Spotify"},
+		users + "&price_min=250&price_max=400":                   {"Kinopoisk", "Ivi"},
+		users + "&active_at=04-2024":                             {"Netflix", "Spotify", "Ivi"},
+		users + "&start_date_from=02-2024&start_date_to=03-2024": {"Spotify", "Ivi"},
+		users + "&end_date_from=07-2024":                         {"Kinopoisk"},
+		users + "&has_end_date=false":                            {"Spotify", "Ivi"},
+		"service_name=Okko&service_name=Ivi":                     {"Ivi", "Okko"},
+	} {
+		names, _ := s.listServiceNames("/api/v1/subscriptions?" + query)
+		s.Equal(expected, names, query)
+	}
+
+	// Paging by a non-unique key keeps the order and visits every row once.
+	path := "/api/v1/subscriptions?limit=2&sort=-price&" + users
+	var names []string
+	cursor := ""
+	for range 3 {
+		page, next := s.listServiceNames(path + cursor)
+		names = append(names, page...)
+		if next == "" {
+			break
+		}
+		cursor = "&cursor=" + next
+	}
+	s.Equal([]string{"Netflix", "Ivi", "Kinopoisk", "Spotify"}, names)
+
+	_, resp, err := getAPIResponse(mainHost, "/api/v1/subscriptions?sort=price&cursor="+cursor[len("&cursor="):], nil, nil)
+	s.NoError(err)
+	s.Equal(400, resp.StatusCode)
+}
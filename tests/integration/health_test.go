@@ -0,0 +1,34 @@
+package integration
+
+import (
+	"encoding/json"
+)
+
+func (s *SubscriptionSuite) TestLivez() {
+	_, resp, err := getAPIResponse(mainHost, "/livez", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+}
+
+func (s *SubscriptionSuite) TestReadyz() {
+	respBody, resp, err := getAPIResponse(mainHost, "/readyz", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+	s.Equal("application/json", resp.Header.Get("Content-Type"))
+
+	var report struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status  string         `json:"status"`
+			Details map[string]any `json:"details"`
+		} `json:"checks"`
+	}
+	s.Require().NoError(json.Unmarshal(respBody, &report))
+
+	s.Equal("ok", report.Status)
+	for _, name := range []string{"database", "migrations", "pool"} {
+		s.Contains(report.Checks, name)
+		s.Equal("ok", report.Checks[name].Status, name)
+	}
+	s.Equal(false, report.Checks["migrations"].Details["dirty"])
+}
@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestSubscriptionHistory() {
+	s.clearDatabase()
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+
+	_, resp, err := doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":600}`), map[string]string{"X-Request-Id": "price-change"}, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	resp, err = deleteAPIResponse(mainHost, path, nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	respBody, resp, err := getAPIResponse(mainHost, path+"/history", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var response struct {
+		History []struct {
+			Action    string `json:"action"`
+			RequestID string `json:"request_id"`
+			Before    *struct {
+				Price int `json:"price"`
+			} `json:"before"`
+			After *struct {
+				Price     int     `json:"price"`
+				DeletedAt *string `json:"deleted_at"`
+			} `json:"after"`
+		} `json:"history"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &response))
+	s.Require().Len(response.History, 3)
+
+	s.Equal("created", response.History[0].Action)
+	s.Nil(response.History[0].Before)
+	s.NotEmpty(response.History[0].RequestID)
+
+	s.Equal("updated", response.History[1].Action)
+	s.Equal("price-change", response.History[1].RequestID)
+	s.Equal(500, response.History[1].Before.Price)
+	s.Equal(600, response.History[1].After.Price)
+
+	s.Equal("deleted", response.History[2].Action)
+	s.NotNil(response.History[2].After.DeletedAt)
+
+	_, err = s.DB.Exec(`UPDATE subscription_history SET action = 'created' WHERE subscription_id = $1`, subscriptionID)
+	s.Error(err, "history must be append-only")
+}
@@ -0,0 +1,29 @@
+package integration
+
+import (
+	"github.com/google/uuid"
+)
+
+func (s *SubscriptionSuite) TestMetrics() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	_ = s.createSubscription("Netflix", 500, userID, "01-2024", "")
+	_ = s.createSubscription("Netflix", 200, uuid.New(), "01-2024", "")
+	_ = s.createSubscription("Okko", 300, userID, "01-2024", "02-2024")
+
+	respBody, resp, err := getAPIResponse(mainHost, "/metrics", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+	s.Contains(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4")
+
+	body := string(respBody)
+	s.Contains(body, "# TYPE http_requests_total counter\n")
+	s.Contains(body, `http_requests_total{method="POST",route="/api/v1/subscriptions",status="201"} `)
+	s.Contains(body, `http_request_duration_seconds_bucket{method="POST",route="/api/v1/subscriptions",status="201",le="+Inf"} `)
+	s.Contains(body, "db_pool_open_connections ")
+	s.Contains(body, "subscriptions_active 2\n")
+	s.Contains(body, `subscriptions_active_by_service{service="Netflix"} 2`+"\n")
+	s.NotContains(body, `subscriptions_active_by_service{service="Okko"}`)
+	s.Contains(body, "subscriptions_monthly_recurring_revenue_rub 700\n")
+}
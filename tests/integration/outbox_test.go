@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestOutboxEventsWrittenWithChanges() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	subscriptionID := s.createSubscription("Netflix", 500, userID, "01-2024", "")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+
+	_, resp, err := doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":600}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	// A rejected change writes no event.
+	_, resp, err = doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":700}`), map[string]string{"If-Match": `"1"`}, nil)
+	s.NoError(err)
+	s.Equal(412, resp.StatusCode)
+
+	resp, err = deleteAPIResponse(mainHost, path, nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	_, resp, err = postAPIResponse(mainHost, path+"/restore", nil, nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	rows, err := s.DB.Query(`SELECT event_type, payload FROM outbox_event WHERE subscription_id = $1 ORDER BY id`, subscriptionID)
+	s.Require().NoError(err)
+	defer rows.Close()
+
+	type eventData struct {
+		Price    int    `json:"price"`
+		UserID   string `json:"user_id"`
+		Previous *struct {
+			Price int `json:"price"`
+		} `json:"previous"`
+	}
+
+	var eventTypes []string
+	var data []eventData
+	for rows.Next() {
+		var eventType string
+		var payload []byte
+		s.Require().NoError(rows.Scan(&eventType, &payload))
+
+		var item eventData
+		s.Require().NoError(jsoniter.Unmarshal(payload, &item))
+		eventTypes = append(eventTypes, eventType)
+		data = append(data, item)
+	}
+	s.Require().NoError(rows.Err())
+
+	s.Equal([]string{"subscription.created", "subscription.updated", "subscription.deleted", "subscription.restored"}, eventTypes)
+	s.Equal(userID.String(), data[0].UserID)
+	s.Equal(600, data[1].Price)
+	s.Require().NotNil(data[1].Previous)
+	s.Equal(500, data[1].Previous.Price)
+
+	// The worker publishes the events and marks them delivered.
+	s.Eventually(func() bool {
+		var pending int
+		s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM outbox_event WHERE status <> 'delivered'`).Scan(&pending))
+		return pending == 0
+	}, 10*time.Second, 200*time.Millisecond)
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type listPage struct {
+	Subscriptions []struct {
+		ID int64 `json:"id"`
+	} `json:"subscriptions"`
+	Total      *int   `json:"total"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func (s *SubscriptionSuite) listPage(path string) listPage {
+	respBody, resp, err := getAPIResponse(mainHost, path, nil, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(200, resp.StatusCode, string(respBody))
+
+	var page listPage
+	s.Require().NoError(jsoniter.Unmarshal(respBody, &page))
+	return page
+}
+
+func (s *SubscriptionSuite) TestListSubscriptionsCursor() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	for month := 1; month <= 5; month++ {
+		s.createSubscription("Netflix", 500, userID, fmt.Sprintf("%02d-2024", month), "")
+	}
+
+	path := fmt.Sprintf("/api/v1/subscriptions?user_id=%s&limit=2&include_total=false", userID)
+
+	page := s.listPage(path)
+	s.Nil(page.Total)
+	s.Len(page.Subscriptions, 2)
+	s.Require().NotEmpty(page.NextCursor)
+
+	seen := []int64{page.Subscriptions[0].ID, page.Subscriptions[1].ID}
+
+	// A row inserted while paging does not shift the following pages.
+	s.createSubscription("Spotify", 100, userID, "01-2024", "")
+
+	page = s.listPage(path + "&cursor=" + page.NextCursor)
+	s.Len(page.Subscriptions, 2)
+	s.Require().NotEmpty(page.NextCursor)
+	seen = append(seen, page.Subscriptions[0].ID, page.Subscriptions[1].ID)
+
+	page = s.listPage(path + "&cursor=" + page.NextCursor)
+	s.Len(page.Subscriptions, 2)
+	s.Empty(page.NextCursor)
+	seen = append(seen, page.Subscriptions[0].ID, page.Subscriptions[1].ID)
+
+	for i := 1; i < len(seen); i++ {
+		s.Less(seen[i-1], seen[i])
+	}
+
+	page = s.listPage(fmt.Sprintf("/api/v1/subscriptions?user_id=%s&limit=10", userID))
+	s.Require().NotNil(page.Total)
+	s.Equal(6, *page.Total)
+	s.Empty(page.NextCursor)
+}
@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (s *SubscriptionSuite) subscriptionEndDate(subscriptionID int64) (price int, hasEndDate bool) {
+	s.Require().NoError(s.DB.QueryRow(
+		`SELECT price_rub, end_date IS NOT NULL FROM subscription WHERE id = $1`, subscriptionID,
+	).Scan(&price, &hasEndDate))
+	return price, hasEndDate
+}
+
+func (s *SubscriptionSuite) TestPatchSubscriptionMergePatch() {
+	s.clearDatabase()
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "12-2024")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+	headers := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	_, resp, err := doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":600}`), headers, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	price, hasEndDate := s.subscriptionEndDate(subscriptionID)
+	s.Equal(600, price)
+	s.True(hasEndDate)
+
+	_, resp, err = doRequest(http.MethodPatch, mainHost, path, []byte(`{"end_date":null}`), headers, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	price, hasEndDate = s.subscriptionEndDate(subscriptionID)
+	s.Equal(600, price)
+	s.False(hasEndDate)
+
+	for _, body := range []string{`{"price":null}`, `{"end_date":""}`, `{"user_id":"` + uuid.NewString() + `"}`} {
+		_, resp, err = doRequest(http.MethodPatch, mainHost, path, []byte(body), headers, nil)
+		s.NoError(err)
+		s.Equal(400, resp.StatusCode, body)
+	}
+}
+
+func (s *SubscriptionSuite) TestPatchSubscriptionStartAfterEnd() {
+	s.clearDatabase()
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2025", "06-2025")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+
+	for _, body := range []string{`{"start_date":"12-2025"}`, `{"end_date":"12-2024"}`, `{"price":700,"price_effective_from":"07-2025"}`} {
+		_, resp, err := doRequest(http.MethodPatch, mainHost, path, []byte(body), nil, nil)
+		s.NoError(err)
+		s.Equal(400, resp.StatusCode, body)
+	}
+
+	var startMonth, endMonth int
+	s.Require().NoError(s.DB.QueryRow(
+		`SELECT EXTRACT(MONTH FROM start_date), EXTRACT(MONTH FROM end_date) FROM subscription WHERE id = $1`, subscriptionID,
+	).Scan(&startMonth, &endMonth))
+	s.Equal(1, startMonth)
+	s.Equal(6, endMonth)
+
+	_, resp, err := doRequest(http.MethodPatch, mainHost, path, []byte(`{"start_date":"12-2025","end_date":null}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+}
+
+func (s *SubscriptionSuite) TestPutSubscriptionReplacesAllFields() {
+	s.clearDatabase()
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "12-2024")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+
+	_, resp, err := doRequest(http.MethodPut, mainHost, path, []byte(`{"price":600}`), nil, nil)
+	s.NoError(err)
+	s.Equal(400, resp.StatusCode)
+
+	_, resp, err = doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":700,"price_effective_from":"06-2024"}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	// The same price keeps the price history, a missing end_date clears it.
+	body := []byte(`{"service_name":"Netflix","price":700,"start_date":"01-2024"}`)
+	_, resp, err = doRequest(http.MethodPut, mainHost, path, body, nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	price, hasEndDate := s.subscriptionEndDate(subscriptionID)
+	s.Equal(700, price)
+	s.False(hasEndDate)
+
+	var periods int
+	s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM subscription_price WHERE subscription_id = $1`, subscriptionID).Scan(&periods))
+	s.Equal(2, periods)
+}
@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestSoftDeleteAndRestore() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	subscriptionID := s.createSubscription("Netflix", 500, userID, "01-2024", "12-2024")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+	statsPath := fmt.Sprintf("/api/v1/stats/total?user_id=%s&start_date=01-2024&end_date=12-2024", userID)
+
+	resp, err := deleteAPIResponse(mainHost, path, nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	_, resp, err = getAPIResponse(mainHost, path, nil, nil)
+	s.NoError(err)
+	s.Equal(404, resp.StatusCode)
+
+	respBody, resp, err := getAPIResponse(mainHost, path+"?include_deleted=true", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var subscription struct {
+		DeletedAt *string `json:"deleted_at"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &subscription))
+	s.NotNil(subscription.DeletedAt)
+
+	var stats struct {
+		TotalCost int `json:"total_cost"`
+	}
+	respBody, _, err = getAPIResponse(mainHost, statsPath, nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+	s.Equal(0, stats.TotalCost)
+
+	var list struct {
+		Total int `json:"total"`
+	}
+	respBody, _, err = getAPIResponse(mainHost, "/api/v1/subscriptions?include_deleted=true&user_id="+userID.String(), nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &list))
+	s.Equal(1, list.Total)
+
+	_, resp, err = doRequest(http.MethodPost, mainHost, path+"/restore", nil, nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	_, resp, err = doRequest(http.MethodPost, mainHost, path+"/restore", nil, nil, nil)
+	s.NoError(err)
+	s.Equal(409, resp.StatusCode)
+
+	respBody, _, err = getAPIResponse(mainHost, statsPath, nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+	s.Equal(6000, stats.TotalCost)
+}
+
+func (s *SubscriptionSuite) TestStatsIncludeDeleted() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	subscriptionID := s.createSubscription("Netflix", 500, userID, "01-2024", "")
+	_ = s.createSubscription("Okko", 300, userID, "01-2024", "02-2024")
+
+	resp, err := deleteAPIResponse(mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID), nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+	_, err = s.DB.Exec(`UPDATE subscription SET deleted_at = '2024-06-15T10:00:00Z' WHERE id = $1`, subscriptionID)
+	s.Require().NoError(err)
+
+	query := fmt.Sprintf("user_id=%s&start_date=01-2024&end_date=12-2024", userID)
+
+	var total struct {
+		TotalCost          int `json:"total_cost"`
+		SubscriptionsCount int `json:"subscriptions_count"`
+	}
+	respBody, _, err := getAPIResponse(mainHost, "/api/v1/stats/total?"+query, nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &total))
+	s.Equal(600, total.TotalCost)
+
+	// The deleted subscription is charged from 01-2024 until it was deleted in 06-2024.
+	respBody, resp, err = getAPIResponse(mainHost, "/api/v1/stats/total?include_deleted=true&"+query, nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+	s.NoError(jsoniter.Unmarshal(respBody, &total))
+	s.Equal(3600, total.TotalCost)
+	s.Equal(2, total.SubscriptionsCount)
+
+	respBody, _, err = getAPIResponse(mainHost, "/api/v1/stats/total?include_deleted=true&group_by=service&"+query, nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &total))
+	s.Equal(3600, total.TotalCost)
+
+	var monthly struct {
+		TotalCost int `json:"total_cost"`
+		Months    []struct {
+			TotalCost int `json:"total_cost"`
+		} `json:"months"`
+	}
+	respBody, _, err = getAPIResponse(mainHost, "/api/v1/stats/monthly?include_deleted=true&"+query, nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &monthly))
+	s.Equal(3600, monthly.TotalCost)
+	s.Require().Len(monthly.Months, 12)
+	s.Equal(800, monthly.Months[0].TotalCost)
+	s.Equal(500, monthly.Months[5].TotalCost)
+	s.Equal(0, monthly.Months[6].TotalCost)
+
+	_, resp, err = getAPIResponse(mainHost, "/api/v1/stats/total?include_deleted=maybe&"+query, nil, nil)
+	s.NoError(err)
+	s.Equal(400, resp.StatusCode)
+}
+
+func (s *SubscriptionSuite) TestRecreateDeletedSubscription() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	subscriptionID := s.createSubscription("Netflix", 500, userID, "01-2024", "")
+
+	resp, err := deleteAPIResponse(mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID), nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	_ = s.createSubscription("Netflix", 500, userID, "01-2024", "")
+
+	// The deleted copy cannot come back while an active duplicate exists.
+	_, resp, err = doRequest(http.MethodPost, mainHost, fmt.Sprintf("/api/v1/subscriptions/%d/restore", subscriptionID), nil, nil, nil)
+	s.NoError(err)
+	s.Equal(409, resp.StatusCode)
+}
@@ -0,0 +1,223 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"EffectiveMobile/internal/repository"
+	"EffectiveMobile/internal/service"
+	"EffectiveMobile/pkg/postgres"
+)
+
+// StatsParitySuite checks that the SQL aggregate behind /stats/total gives exactly
+// the same numbers as the in-memory month arithmetic of StatsService.
+type StatsParitySuite struct {
+	suite.Suite
+
+	logger       *slog.Logger
+	provider     *postgres.Provider
+	DB           *sql.DB
+	statsRepo    *repository.StatsRepository
+	statsService *service.StatsService
+}
+
+func TestStatsParity(t *testing.T) {
+	if !isIntegrationTestsRun() {
+		t.Skip()
+		return
+	}
+
+	suite.Run(t, &StatsParitySuite{})
+}
+
+func (s *StatsParitySuite) SetupSuite() {
+	s.logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	s.provider = postgres.New("postgres", "postgres", postgres.SQLDataBase{
+		Server:          "localhost",
+		Database:        "subscriptions",
+		MaxIdleCons:     10,
+		MaxOpenCons:     10,
+		ConnMaxLifetime: 2,
+		Port:            "5433",
+	}, s.logger)
+	s.Require().NoError(s.provider.Open())
+
+	s.DB = s.provider.GetConn()
+	s.statsRepo = repository.NewStatsRepository(s.provider, s.logger)
+	s.statsService = service.NewStatsService(s.statsRepo, s.logger)
+}
+
+func (s *StatsParitySuite) TearDownSuite() {
+	s.Require().NoError(s.provider.Close())
+}
+
+func (s *StatsParitySuite) TestRandomizedParity() {
+	const (
+		seed          = 20250101
+		services      = 4
+		users         = 6
+		subscriptions = 300
+		periods       = 100
+	)
+
+	rng := rand.New(rand.NewPCG(seed, seed))
+	ctx := context.Background()
+
+	_, err := s.DB.Exec(`TRUNCATE TABLE subscription CASCADE`)
+	s.Require().NoError(err)
+	_, err = s.DB.Exec(`TRUNCATE TABLE service CASCADE`)
+	s.Require().NoError(err)
+
+	serviceNames := make([]string, services)
+	serviceIDs := make([]int, services)
+	for i := range serviceNames {
+		serviceNames[i] = fmt.Sprintf("Parity %d", i)
+		s.Require().NoError(s.DB.QueryRow(`INSERT INTO service (name) VALUES ($1) RETURNING id`, serviceNames[i]).Scan(&serviceIDs[i]))
+	}
+
+	userIDs := make([]uuid.UUID, users)
+	for i := range userIDs {
+		userIDs[i] = uuid.New()
+	}
+
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	randomMonth := func() time.Time {
+		return currentMonth.AddDate(0, rng.IntN(96)-60, 0)
+	}
+
+	for range subscriptions {
+		start := randomMonth()
+
+		var end *time.Time
+		if rng.IntN(10) >= 3 {
+			e := start.AddDate(0, rng.IntN(40), 0)
+			end = &e
+		}
+
+		var id int64
+		err := s.DB.QueryRow(
+			`INSERT INTO subscription (user_id, service_id, price_rub, start_date, end_date)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT DO NOTHING
+			 RETURNING id`,
+			userIDs[rng.IntN(users)], serviceIDs[rng.IntN(services)], rng.IntN(2000), start, end,
+		).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		s.Require().NoError(err)
+
+		// Some subscriptions keep no price periods at all to cover the price_rub fallback.
+		effectiveFrom := start.AddDate(0, rng.IntN(6)-3, 0)
+		for range rng.IntN(4) {
+			_, err := s.DB.Exec(
+				`INSERT INTO subscription_price (subscription_id, effective_from, price_rub) VALUES ($1, $2, $3)`,
+				id, effectiveFrom, rng.IntN(2000),
+			)
+			s.Require().NoError(err)
+			effectiveFrom = effectiveFrom.AddDate(0, 1+rng.IntN(12), 0)
+		}
+	}
+
+	for i := range periods {
+		var periodStart, periodEnd *time.Time
+		if rng.IntN(4) > 0 {
+			ps := randomMonth()
+			periodStart = &ps
+		}
+		if rng.IntN(4) > 0 {
+			pe := randomMonth()
+			if periodStart != nil && pe.Before(*periodStart) {
+				pe = *periodStart
+			}
+			periodEnd = &pe
+		}
+
+		var userID *uuid.UUID
+		if rng.IntN(3) == 0 {
+			userID = &userIDs[rng.IntN(users)]
+		}
+		var serviceName *string
+		if rng.IntN(3) == 0 {
+			serviceName = &serviceNames[rng.IntN(services)]
+		}
+
+		msg := fmt.Sprintf("period #%d: start=%v end=%v user=%v service=%v", i, periodStart, periodEnd, userID, serviceName)
+
+		total, err := s.statsService.GetTotalCost(ctx, userID, serviceName, periodStart, periodEnd, false)
+		s.Require().NoError(err, msg)
+
+		monthly, err := s.statsService.GetMonthlyCost(ctx, userID, serviceName, periodStart, periodEnd, false)
+		s.Require().NoError(err, msg)
+
+		rows, err := s.statsRepo.ListSubscriptionCosts(ctx, repository.GetTotalCostParams{
+			UserID:      userID,
+			ServiceName: serviceName,
+			StartDate:   periodStart,
+			EndDate:     periodEnd,
+		})
+		s.Require().NoError(err, msg)
+
+		s.Equal(referenceTotalCost(rows, periodStart, periodEnd, currentMonth), total.TotalCost, msg)
+		s.Equal(monthly.TotalCost, total.TotalCost, msg)
+		s.Equal(len(rows), total.SubscriptionsCount, msg)
+
+		grouped, err := s.statsService.GetGroupedCost(ctx, userID, serviceName, periodStart, periodEnd,
+			[]repository.GroupByField{repository.GroupByService, repository.GroupByUser}, false)
+		s.Require().NoError(err, msg)
+		s.Equal(total.TotalCost, grouped.TotalCost, msg)
+		s.Equal(total.SubscriptionsCount, grouped.SubscriptionsCount, msg)
+	}
+}
+
+// referenceTotalCost walks every month of every subscription one by one. It is slow
+// on purpose and independent of both implementations under test.
+func referenceTotalCost(rows []repository.SubscriptionCost, periodStart, periodEnd *time.Time, currentMonth time.Time) int {
+	total := 0
+
+	for _, sub := range rows {
+		last := currentMonth
+		switch {
+		case sub.EndDate != nil && (periodEnd == nil || sub.EndDate.Before(*periodEnd)):
+			last = *sub.EndDate
+		case periodEnd != nil:
+			last = *periodEnd
+		}
+
+		for m := sub.StartDate; !m.After(last); m = m.AddDate(0, 1, 0) {
+			if periodStart != nil && m.Before(*periodStart) {
+				continue
+			}
+			total += referencePrice(sub, m)
+		}
+	}
+
+	return total
+}
+
+func referencePrice(sub repository.SubscriptionCost, month time.Time) int {
+	if len(sub.Prices) == 0 {
+		return sub.PriceRub
+	}
+
+	price := sub.Prices[0].PriceRub
+	for _, period := range sub.Prices {
+		if !period.EffectiveFrom.After(month) {
+			price = period.PriceRub
+		}
+	}
+
+	return price
+}
@@ -0,0 +1,227 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestGetMonthlyStats() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	_ = s.createSubscription("Netflix", 500, userID, "01-2024", "")
+	_ = s.createSubscription("Spotify", 300, userID, "02-2024", "04-2024")
+
+	respBody, resp, err := getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/monthly?user_id=%s&start_date=01-2024&end_date=12-2024", userID.String()), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var monthly struct {
+		TotalCost int `json:"total_cost"`
+		Months    []struct {
+			Month              string `json:"month"`
+			TotalCost          int    `json:"total_cost"`
+			SubscriptionsCount int    `json:"subscriptions_count"`
+		} `json:"months"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &monthly))
+
+	s.Require().Len(monthly.Months, 12)
+	s.Equal("01-2024", monthly.Months[0].Month)
+	s.Equal(500, monthly.Months[0].TotalCost)
+	s.Equal(800, monthly.Months[1].TotalCost)
+	s.Equal(2, monthly.Months[1].SubscriptionsCount)
+	s.Equal(500, monthly.Months[4].TotalCost)
+
+	respBody, resp, err = getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/total?user_id=%s&start_date=01-2024&end_date=12-2024", userID.String()), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var total struct {
+		TotalCost int `json:"total_cost"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &total))
+
+	s.Equal(6900, total.TotalCost)
+	s.Equal(total.TotalCost, monthly.TotalCost)
+}
+
+func (s *SubscriptionSuite) TestGetTotalStatsGroupByService() {
+	s.clearDatabase()
+
+	firstUser, secondUser := uuid.New(), uuid.New()
+	_ = s.createSubscription("Netflix", 500, firstUser, "01-2024", "")
+	_ = s.createSubscription("Netflix", 400, secondUser, "06-2024", "08-2024")
+	_ = s.createSubscription("Spotify", 300, firstUser, "02-2024", "")
+
+	respBody, resp, err := getAPIResponse(mainHost, "/api/v1/stats/total?start_date=01-2024&end_date=12-2024&group_by=service", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var stats struct {
+		TotalCost          int `json:"total_cost"`
+		SubscriptionsCount int `json:"subscriptions_count"`
+		Groups             []struct {
+			ServiceName        string  `json:"service_name"`
+			UserID             *string `json:"user_id"`
+			TotalCost          int     `json:"total_cost"`
+			SubscriptionsCount int     `json:"subscriptions_count"`
+		} `json:"groups"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+
+	s.Equal(10500, stats.TotalCost)
+	s.Equal(3, stats.SubscriptionsCount)
+	s.Require().Len(stats.Groups, 2)
+	s.Equal("Netflix", stats.Groups[0].ServiceName)
+	s.Nil(stats.Groups[0].UserID)
+	s.Equal(7200, stats.Groups[0].TotalCost)
+	s.Equal(2, stats.Groups[0].SubscriptionsCount)
+	s.Equal("Spotify", stats.Groups[1].ServiceName)
+	s.Equal(3300, stats.Groups[1].TotalCost)
+}
+
+func (s *SubscriptionSuite) TestPriceChangeKeepsPastMonths() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	subscriptionID := s.createSubscription("Netflix", 500, userID, "01-2025", "06-2025")
+
+	_, resp, err := doRequest(http.MethodPatch, mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID),
+		[]byte(`{"price":800,"price_effective_from":"04-2025"}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	respBody, resp, err := getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/total?user_id=%s&start_date=01-2025&end_date=12-2025", userID), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var stats struct {
+		TotalCost int `json:"total_cost"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+	s.Equal(3*500+3*800, stats.TotalCost)
+
+	respBody, resp, err = getAPIResponse(mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var subscription struct {
+		Price  int `json:"price"`
+		Prices []struct {
+			EffectiveFrom string `json:"effective_from"`
+			Price         int    `json:"price"`
+		} `json:"prices"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &subscription))
+	s.Equal(800, subscription.Price)
+	s.Require().Len(subscription.Prices, 2)
+	s.Equal("01-2025", subscription.Prices[0].EffectiveFrom)
+	s.Equal("04-2025", subscription.Prices[1].EffectiveFrom)
+
+	// Without price_effective_from the price changes from the current month, here
+	// moved back to the last month of the subscription.
+	_, resp, err = doRequest(http.MethodPatch, mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID),
+		[]byte(`{"price":400}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	respBody, _, err = getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/total?user_id=%s&start_date=01-2025&end_date=12-2025", userID), nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+	s.Equal(3*500+2*800+400, stats.TotalCost)
+
+	_, resp, err = doRequest(http.MethodPatch, mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID),
+		[]byte(`{"price":300,"reset_price_history":true}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	respBody, _, err = getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/total?user_id=%s&start_date=01-2025&end_date=12-2025", userID), nil, nil)
+	s.NoError(err)
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+	s.Equal(6*300, stats.TotalCost)
+}
+
+func (s *SubscriptionSuite) TestPriceChangeAppliesFromCurrentMonth() {
+	s.clearDatabase()
+
+	current := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := current.AddDate(0, -2, 0)
+	userID := uuid.New()
+	subscriptionID := s.createSubscription("Netflix", 500, userID, start.Format("01-2006"), "")
+
+	_, resp, err := doRequest(http.MethodPatch, mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID),
+		[]byte(`{"price":800}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	respBody, _, err := getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/total?user_id=%s&start_date=%s&end_date=%s",
+		userID, start.Format("01-2006"), current.Format("01-2006")), nil, nil)
+	s.NoError(err)
+
+	var stats struct {
+		TotalCost int `json:"total_cost"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &stats))
+	s.Equal(2*500+800, stats.TotalCost)
+
+	respBody, _, err = getAPIResponse(mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID), nil, nil)
+	s.NoError(err)
+
+	var subscription struct {
+		Price  int `json:"price"`
+		Prices []struct {
+			EffectiveFrom string `json:"effective_from"`
+		} `json:"prices"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &subscription))
+	s.Equal(800, subscription.Price)
+	s.Require().Len(subscription.Prices, 2)
+	s.Equal(current.Format("01-2006"), subscription.Prices[1].EffectiveFrom)
+}
+
+func (s *SubscriptionSuite) TestGetForecastStats() {
+	s.clearDatabase()
+
+	now := time.Now()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	_ = s.createSubscription("Netflix", 500, userID, current.AddDate(-1, 0, 0).Format("01-2006"), "")
+	_ = s.createSubscription("Spotify", 300, userID, current.Format("01-2006"), current.AddDate(0, 1, 0).Format("01-2006"))
+
+	respBody, resp, err := getAPIResponse(mainHost, fmt.Sprintf("/api/v1/stats/forecast?months=3&user_id=%s", userID.String()), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	var forecast struct {
+		TotalCost int      `json:"total_cost"`
+		GroupBy   []string `json:"group_by"`
+		Months    []struct {
+			Month     string `json:"month"`
+			TotalCost int    `json:"total_cost"`
+			Groups    []struct {
+				ServiceName string `json:"service_name"`
+				TotalCost   int    `json:"total_cost"`
+			} `json:"groups"`
+		} `json:"months"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &forecast))
+
+	s.Equal([]string{"service"}, forecast.GroupBy)
+	s.Equal(3*500+2*300, forecast.TotalCost)
+	s.Require().Len(forecast.Months, 3)
+	s.Equal(current.Format("01-2006"), forecast.Months[0].Month)
+	s.Equal(800, forecast.Months[1].TotalCost)
+	s.Require().Len(forecast.Months[1].Groups, 2)
+	s.Equal("Netflix", forecast.Months[1].Groups[0].ServiceName)
+	s.Require().Len(forecast.Months[2].Groups, 1)
+	s.Equal(500, forecast.Months[2].Groups[0].TotalCost)
+
+	_, resp, err = getAPIResponse(mainHost, "/api/v1/stats/forecast?months=0", nil, nil)
+	s.NoError(err)
+	s.Equal(400, resp.StatusCode)
+}
@@ -82,8 +82,16 @@ func (s *SubscriptionSuite) initDatabase() {
 func (s *SubscriptionSuite) clearDatabase() {
 	_, err := s.DB.Exec(`TRUNCATE TABLE subscription CASCADE`)
 	s.NoError(err)
+	_, err = s.DB.Exec(`TRUNCATE TABLE subscription_history`)
+	s.NoError(err)
 	_, err = s.DB.Exec(`TRUNCATE TABLE service CASCADE`)
 	s.NoError(err)
+	_, err = s.DB.Exec(`TRUNCATE TABLE budget`)
+	s.NoError(err)
+	_, err = s.DB.Exec(`TRUNCATE TABLE webhook_endpoint CASCADE`)
+	s.NoError(err)
+	_, err = s.DB.Exec(`TRUNCATE TABLE outbox_event`)
+	s.NoError(err)
 }
 
 func (s *SubscriptionSuite) TestCreateSubscription() {
@@ -156,7 +164,7 @@ func (s *SubscriptionSuite) TestUpdateSubscription() {
 	userID := uuid.New()
 	subscriptionID := s.createSubscription("Netflix", 500, userID, "01-2024", "")
 
-	updateData := []byte(`{"price":600,"end_date":"12-2024"}`)
+	updateData := []byte(`{"service_name":"Netflix","price":600,"start_date":"01-2024","end_date":"12-2024"}`)
 	respBody, resp, err := doRequest(http.MethodPut, mainHost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID), updateData, nil, nil)
 	s.NoError(err)
 	s.Equal(200, resp.StatusCode)
@@ -193,10 +201,10 @@ func (s *SubscriptionSuite) TestDeleteSubscription() {
 	s.NoError(err)
 	s.Equal(204, resp.StatusCode)
 
-	var count int
-	err = s.DB.QueryRow(`SELECT COUNT(*) FROM subscription WHERE id = $1`, subscriptionID).Scan(&count)
+	var deleted bool
+	err = s.DB.QueryRow(`SELECT deleted_at IS NOT NULL FROM subscription WHERE id = $1`, subscriptionID).Scan(&deleted)
 	s.NoError(err)
-	s.Equal(0, count)
+	s.True(deleted)
 }
 
 func (s *SubscriptionSuite) TestListSubscriptions() {
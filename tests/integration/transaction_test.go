@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (s *SubscriptionSuite) TestFailedUpdateLeavesNoNewService() {
+	s.clearDatabase()
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+
+	body := []byte(`{"service_name":"Kinopoisk"}`)
+	_, resp, err := doRequest(http.MethodPatch, mainHost, path, body, map[string]string{"If-Match": `"42"`}, nil)
+	s.NoError(err)
+	s.Equal(412, resp.StatusCode)
+
+	var count int
+	s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM service WHERE name = 'Kinopoisk'`).Scan(&count))
+	s.Equal(0, count)
+}
+
+func (s *SubscriptionSuite) TestFailedTransactionBatchLeavesNoNewService() {
+	s.clearDatabase()
+
+	userID := uuid.New()
+	s.createSubscription("Netflix", 500, userID, "01-2024", "")
+
+	body := fmt.Sprintf(`[
+		{"service_name": "Okko", "price": 300, "user_id": "%[1]s", "start_date": "01-2024"},
+		{"service_name": "Netflix", "price": 500, "user_id": "%[1]s", "start_date": "01-2024"}
+	]`, userID)
+
+	result := s.postBatch("transaction", body)
+	s.Equal(0, result.Created)
+
+	var count int
+	s.Require().NoError(s.DB.QueryRow(`SELECT count(*) FROM service WHERE name = 'Okko'`).Scan(&count))
+	s.Equal(0, count)
+}
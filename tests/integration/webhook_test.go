@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func (s *SubscriptionSuite) TestWebhookEndpoints() {
+	s.clearDatabase()
+
+	_, resp, err := postAPIResponse(mainHost, "/api/v1/webhooks", []byte(`{"url":"ftp://example.com"}`), nil, nil)
+	s.NoError(err)
+	s.Equal(400, resp.StatusCode)
+
+	respBody, resp, err := postAPIResponse(mainHost, "/api/v1/webhooks", []byte(`{"url":"http://127.0.0.1:1/hooks"}`), nil, nil)
+	s.NoError(err)
+	s.Equal(201, resp.StatusCode)
+
+	var created struct {
+		ID     int64    `json:"id"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+	s.NoError(jsoniter.Unmarshal(respBody, &created))
+	s.NotEmpty(created.Secret)
+	s.Empty(created.Events)
+
+	path := fmt.Sprintf("/api/v1/webhooks/%d", created.ID)
+
+	respBody, resp, err = getAPIResponse(mainHost, path, nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+	s.NotContains(string(respBody), created.Secret)
+
+	_, resp, err = getAPIResponse(mainHost, path+"/deliveries", nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	resp, err = deleteAPIResponse(mainHost, path, nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	_, resp, err = getAPIResponse(mainHost, path, nil, nil)
+	s.NoError(err)
+	s.Equal(404, resp.StatusCode)
+}
+
+func (s *SubscriptionSuite) TestWebhookEventsAreQueued() {
+	s.clearDatabase()
+
+	body := []byte(`{"url":"http://127.0.0.1:1/hooks","events":["subscription.created","subscription.deleted"]}`)
+	_, resp, err := postAPIResponse(mainHost, "/api/v1/webhooks", body, nil, nil)
+	s.NoError(err)
+	s.Equal(201, resp.StatusCode)
+
+	subscriptionID := s.createSubscription("Netflix", 500, uuid.New(), "01-2024", "")
+	path := fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID)
+
+	_, resp, err = doRequest(http.MethodPatch, mainHost, path, []byte(`{"price":600}`), nil, nil)
+	s.NoError(err)
+	s.Equal(200, resp.StatusCode)
+
+	resp, err = deleteAPIResponse(mainHost, path, nil)
+	s.NoError(err)
+	s.Equal(204, resp.StatusCode)
+
+	// Events reach the webhook queue through the outbox worker.
+	var eventTypes []string
+	s.Eventually(func() bool {
+		eventTypes = s.queuedWebhookEvents(subscriptionID)
+		return len(eventTypes) == 2
+	}, 10*time.Second, 200*time.Millisecond)
+
+	// The endpoint is not subscribed to subscription.updated.
+	s.Equal([]string{"subscription.created", "subscription.deleted"}, eventTypes)
+}
+
+func (s *SubscriptionSuite) queuedWebhookEvents(subscriptionID int64) []string {
+	rows, err := s.DB.Query(`
+		SELECT event_type, (payload->'data'->>'subscription_id')::bigint
+		FROM webhook_outbox
+		ORDER BY id`)
+	s.Require().NoError(err)
+	defer rows.Close()
+
+	var eventTypes []string
+	for rows.Next() {
+		var eventType string
+		var id int64
+		s.Require().NoError(rows.Scan(&eventType, &id))
+		s.Equal(subscriptionID, id)
+		eventTypes = append(eventTypes, eventType)
+	}
+	s.Require().NoError(rows.Err())
+
+	return eventTypes
+}
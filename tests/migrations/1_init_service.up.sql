@@ -1,3 +1,3 @@
 INSERT INTO service (name)
 VALUES ('Yandex Plus')
-    ON CONFLICT (name) DO NOTHING;
\ No newline at end of file
+    ON CONFLICT ((lower(name))) DO NOTHING;
\ No newline at end of file